@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Polyrom/houses_api/internal/export"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const exportUsage = "usage: app export [-format csv|xlsx|jsonl] [-house-id N] [-status S] [-o FILE] houses|flats"
+
+func runExport(ctx context.Context, pg *pgxpool.Pool, l logging.Logger, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "", "csv, xlsx or jsonl, guessed from the -o extension by default (csv for stdout)")
+	houseID := fs.Int("house-id", 0, "export flats of this house only")
+	status := fs.String("status", "", "export flats with this status only")
+	out := fs.String("o", "-", "output file, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return errors.New(exportUsage)
+	}
+	kind := export.Kind(fs.Arg(0))
+	if fs.NArg() != 1 || (kind != export.KindHouses && kind != export.KindFlats) {
+		return errors.New(exportUsage)
+	}
+	f := export.Format(*format)
+	if f == "" {
+		f = export.Format(strings.TrimPrefix(strings.ToLower(filepath.Ext(*out)), "."))
+		if f == "" {
+			f = export.FormatCSV
+		}
+	}
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	s := export.NewService(export.NewRepository(pg, l), l)
+	if err := s.Export(ctx, kind, f, export.Filter{HouseID: *houseID, Status: *status}, w); err != nil {
+		if *out != "-" {
+			os.Remove(*out)
+		}
+		return err
+	}
+	if file, ok := w.(*os.File); ok && file != os.Stdout {
+		return file.Close()
+	}
+	return nil
+}
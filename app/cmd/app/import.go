@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Polyrom/houses_api/internal/importer"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const importUsage = "usage: app import [-mode atomic|best_effort] [-dry-run] [-format csv|jsonl] [-json] houses|flats FILE"
+
+// extFormats guesses the format from the file extension.
+var extFormats = map[string]importer.Format{
+	".csv":    importer.FormatCSV,
+	".jsonl":  importer.FormatJSONL,
+	".ndjson": importer.FormatJSONL,
+}
+
+func runImport(ctx context.Context, pg *pgxpool.Pool, l logging.Logger, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	mode := fs.String("mode", string(importer.ModeAtomic), "atomic or best_effort")
+	dryRun := fs.Bool("dry-run", false, "only validate the file")
+	format := fs.String("format", "", "csv or jsonl, guessed from the file extension by default")
+	asJSON := fs.Bool("json", false, "print the full report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return errors.New(importUsage)
+	}
+	if fs.NArg() != 2 {
+		return errors.New(importUsage)
+	}
+	kind, path := importer.Kind(fs.Arg(0)), fs.Arg(1)
+	opts := importer.Options{Format: importer.Format(*format), Mode: importer.Mode(*mode), DryRun: *dryRun}
+	if opts.Format == "" {
+		opts.Format = extFormats[strings.ToLower(filepath.Ext(path))]
+		if opts.Format == "" {
+			return fmt.Errorf("cannot guess format of %s, use -format", path)
+		}
+	}
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	s := importer.NewService(importer.NewRepository(pg, l), l)
+	rep, err := s.Import(ctx, kind, in, opts)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(rep); err != nil {
+			return err
+		}
+	} else if err = printReport(rep); err != nil {
+		return err
+	}
+	if !rep.OK() {
+		return fmt.Errorf("import: %d of %d rows failed", rep.Failed, rep.Total)
+	}
+	return nil
+}
+
+func printReport(rep importer.Report) error {
+	fmt.Printf("%s (%s", rep.Kind, rep.Mode)
+	if rep.DryRun {
+		fmt.Print(", dry run")
+	}
+	fmt.Printf("): %d rows, %d valid, %d inserted, %d failed\n", rep.Total, rep.Valid, rep.Inserted, rep.Failed)
+	if rep.OK() {
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "LINE\tERRORS")
+	for _, row := range rep.Rows {
+		if len(row.Errors) > 0 {
+			fmt.Fprintf(tw, "%d\t%s\n", row.Line, strings.Join(row.Errors, "; "))
+		}
+	}
+	return tw.Flush()
+}
@@ -2,23 +2,66 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 
 	"github.com/Polyrom/houses_api/internal/config"
+	"github.com/Polyrom/houses_api/internal/migrator"
 	"github.com/Polyrom/houses_api/internal/server"
+	"github.com/Polyrom/houses_api/migrations"
 	"github.com/Polyrom/houses_api/pkg/client/postgres"
 	"github.com/Polyrom/houses_api/pkg/logging"
 	"github.com/gorilla/mux"
 )
 
 func main() {
+	configPath := flag.String("config", config.DefaultPath(), "path to config file (env CONFIG_PATH)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [migrate up|down|status|goto N | import [flags] houses|flats FILE | export [flags] houses|flats]\n", flag.CommandLine.Name())
+		flag.PrintDefaults()
+		fmt.Fprintln(flag.CommandLine.Output(), config.Usage())
+	}
+	flag.Parse()
 	logger := logging.New()
-	cfg := config.Get(logger)
+	cfg := config.Get(logger, *configPath)
 	pg, err := postgres.NewClient(context.Background(), cfg.Storage)
 	if err != nil {
 		logger.Fatalf("create postgres connection error: %v", err)
 	}
+	args := flag.Args()
+	if len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			err = runMigrate(context.Background(), pg, logger, args[1:])
+		case "import":
+			err = runImport(context.Background(), pg, logger, args[1:])
+		case "export":
+			err = runExport(context.Background(), pg, logger, args[1:])
+		default:
+			logger.Fatalf("unknown command %q (available: migrate, import, export)", args[0])
+		}
+		pg.Close()
+		if err != nil {
+			logger.Fatal(err)
+		}
+		return
+	}
+	if cfg.Storage.AutoMigrate {
+		m, err := migrator.New(pg, migrations.FS, logger)
+		if err != nil {
+			logger.Fatalf("load migrations error: %v", err)
+		}
+		err = m.Up(context.Background())
+		if err != nil && !errors.Is(err, migrator.ErrNoChange) {
+			logger.Fatalf("apply migrations error: %v", err)
+		}
+	}
 	router := mux.NewRouter()
 	server := server.New(cfg, logger, router, pg)
 	server.ConfigureRouter()
-	server.Run()
+	server.ConfigureGRPC()
+	if err := server.Run(); err != nil {
+		logger.Fatal(err)
+	}
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/Polyrom/houses_api/internal/migrator"
+	"github.com/Polyrom/houses_api/migrations"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const migrateUsage = "usage: app migrate up|down|status|goto N"
+
+func runMigrate(ctx context.Context, pg *pgxpool.Pool, l logging.Logger, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	m, err := migrator.New(pg, migrations.FS, l)
+	if err != nil {
+		return fmt.Errorf("load migrations: %w", err)
+	}
+	switch args[0] {
+	case "up":
+		err = m.Up(ctx)
+	case "down":
+		err = m.Down(ctx)
+	case "goto":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		err = m.Goto(ctx, version)
+	case "status":
+		return printStatus(ctx, m)
+	default:
+		return errors.New(migrateUsage)
+	}
+	if errors.Is(err, migrator.ErrNoChange) {
+		l.Info("migrate: no change")
+		return nil
+	}
+	return err
+}
+
+func printStatus(ctx context.Context, m *migrator.Migrator) error {
+	st, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("current version: %d (dirty: %t)\n", st.Version, st.Dirty)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED")
+	for _, ms := range st.Migrations {
+		fmt.Fprintf(tw, "%d\t%s\t%t\n", ms.Version, ms.Name, ms.Applied)
+	}
+	return tw.Flush()
+}
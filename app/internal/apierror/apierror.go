@@ -11,9 +11,20 @@ type body struct {
 	ErrCode int    `json:"err_code"`
 }
 
+// defaultRetryAfter is suggested to clients for errors worth retrying.
+const defaultRetryAfter = "5"
+
+// Write sends the error as a JSON body. Headers must be set before the status
+// is written, so a Retry-After already set by the caller is kept. Only 5xx
+// and 429 responses get a default one, other client errors will not succeed
+// on retry.
 func Write(w http.ResponseWriter, APIErr error, reqID string, errCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	retryable := errCode >= http.StatusInternalServerError || errCode == http.StatusTooManyRequests
+	if retryable && w.Header().Get("Retry-After") == "" {
+		w.Header().Set("Retry-After", defaultRetryAfter)
+	}
 	w.WriteHeader(errCode)
-	w.Header().Set("Retry-After", "5")
 	errBody := body{
 		Message: APIErr.Error(),
 		ReqID:   reqID,
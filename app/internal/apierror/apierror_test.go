@@ -0,0 +1,41 @@
+package apierror
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWrite_RetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   int
+		preset string
+		want   string
+	}{
+		{name: "bad request", code: http.StatusBadRequest},
+		{name: "unauthorized", code: http.StatusUnauthorized},
+		{name: "not found", code: http.StatusNotFound},
+		{name: "conflict", code: http.StatusConflict},
+		{name: "too many requests", code: http.StatusTooManyRequests, want: "5"},
+		{name: "rate limiter value kept", code: http.StatusTooManyRequests, preset: "2", want: "2"},
+		{name: "internal error", code: http.StatusInternalServerError, want: "5"},
+		{name: "unavailable", code: http.StatusServiceUnavailable, want: "5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			if tt.preset != "" {
+				rr.Header().Set("Retry-After", tt.preset)
+			}
+			Write(rr, errors.New("boom"), "req", tt.code)
+			if got := rr.Header().Get("Retry-After"); got != tt.want {
+				t.Errorf("Retry-After = %q, want %q", got, tt.want)
+			}
+			if rr.Code != tt.code {
+				t.Errorf("code = %d, want %d", rr.Code, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// Package blob keeps files such as photos outside the database.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// Storage stores blobs by slash-separated keys like "flats/1/photo.jpg".
+// Put replaces an existing blob and Delete of a missing blob succeeds.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,59 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	l, err := NewLocal(filepath.Join(t.TempDir(), "photos"))
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	if err = l.Put(ctx, "flats/1/a.jpg", strings.NewReader("first")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err = l.Put(ctx, "flats/1/a.jpg", strings.NewReader("second")); err != nil {
+		t.Fatalf("Put again: %v", err)
+	}
+	rc, err := l.Open(ctx, "flats/1/a.jpg")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	b, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(b) != "second" {
+		t.Errorf("read %q, want %q", b, "second")
+	}
+	entries, _ := os.ReadDir(filepath.Join(l.dir, "flats", "1"))
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d entries, want only the blob", len(entries))
+	}
+	if err = l.Delete(ctx, "flats/1/a.jpg"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err = l.Open(ctx, "flats/1/a.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open after Delete error = %v, want ErrNotFound", err)
+	}
+	if err = l.Delete(ctx, "flats/1/a.jpg"); err != nil {
+		t.Errorf("Delete of a missing blob: %v", err)
+	}
+}
+
+func TestLocal_InvalidKey(t *testing.T) {
+	l, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+	for _, key := range []string{"", "../a.jpg", "flats/../../a.jpg", "/etc/passwd"} {
+		if err = l.Put(context.Background(), key, strings.NewReader("x")); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
@@ -1,42 +1,410 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/Polyrom/houses_api/pkg/logging"
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
-// TODO: add env-default
+const (
+	defaultPath = "config.yaml"
+	pathEnv     = "CONFIG_PATH"
+	// fileEnvSuffix marks a variable that holds a path to a file with the
+	// actual value, e.g. DB_PASSWORD_FILE=/run/secrets/db_password.
+	fileEnvSuffix = "_FILE"
+)
+
 type Config struct {
-	Debug  *bool `yaml:"debug"`
-	Listen struct {
-		Host string `yaml:"host"`
-		Port string `yaml:"port"`
-	} `yaml:"listen"`
-	Storage StorageConfig `yaml:"storage"`
+	Debug     bool            `yaml:"debug" env:"DEBUG" env-default:"false" env-description:"enable debug mode"`
+	Listen    ListenConfig    `yaml:"listen"`
+	GRPC      GRPCConfig      `yaml:"grpc"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Shutdown  ShutdownConfig  `yaml:"shutdown"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	CORS      CORSConfig      `yaml:"cors"`
+	API       APIConfig       `yaml:"api"`
+	OpenAPI   OpenAPIConfig   `yaml:"openapi"`
+	Events    EventsConfig    `yaml:"events"`
+	Webhooks  WebhooksConfig  `yaml:"webhooks"`
+	Photos    PhotosConfig    `yaml:"photos"`
+	Stats     StatsConfig     `yaml:"stats"`
+	Searches  SearchesConfig  `yaml:"searches"`
+}
+
+type ListenConfig struct {
+	Host         string `yaml:"host" env:"LISTEN_HOST" env-default:"0.0.0.0" env-description:"HTTP server host"`
+	Port         string `yaml:"port" env:"LISTEN_PORT" env-default:"8080" env-description:"HTTP server port"`
+	MaxBodyBytes int64  `yaml:"max_body_bytes" env:"LISTEN_MAX_BODY_BYTES" env-default:"1048576" env-description:"maximum request body size in bytes"`
+}
+
+// GRPCConfig describes the gRPC server, which listens on Listen.Host.
+type GRPCConfig struct {
+	Port string `yaml:"port" env:"GRPC_PORT" env-default:"9090" env-description:"gRPC server port"`
 }
 
 type StorageConfig struct {
-	Username    string `yaml:"username"`
-	Password    string `yaml:"password"`
-	Host        string `yaml:"host"`
-	Port        string `yaml:"port"`
-	Database    string `yaml:"database"`
-	MaxAttempts int    `yaml:"max_attempts"`
+	Username    string `yaml:"username" env:"DB_USERNAME" env-description:"database user"`
+	Password    string `yaml:"password" env:"DB_PASSWORD" env-description:"database password"`
+	Host        string `yaml:"host" env:"DB_HOST" env-default:"localhost" env-description:"database host"`
+	Port        string `yaml:"port" env:"DB_PORT" env-default:"5432" env-description:"database port"`
+	Database    string `yaml:"database" env:"DB_NAME" env-description:"database name"`
+	MaxAttempts int    `yaml:"max_attempts" env:"DB_MAX_ATTEMPTS" env-default:"5" env-description:"connection attempts on start"`
+	AutoMigrate bool   `yaml:"auto_migrate" env:"DB_AUTO_MIGRATE" env-default:"false" env-description:"apply migrations on start"`
+}
+
+type ShutdownConfig struct {
+	Timeout        time.Duration `yaml:"timeout" env:"SHUTDOWN_TIMEOUT" env-default:"15s" env-description:"deadline for draining requests and stopping workers"`
+	ReadinessDelay time.Duration `yaml:"readiness_delay" env:"SHUTDOWN_READINESS_DELAY" env-default:"0s" env-description:"delay between failing readiness and draining"`
+}
+
+// RateLimit is a token bucket refilled at Rate tokens per second and holding
+// at most Burst tokens. A zero Rate disables limiting.
+type RateLimit struct {
+	Rate  float64 `yaml:"rate" env:"RATE" env-default:"20" env-description:"default requests per second per client, 0 disables"`
+	Burst int     `yaml:"burst" env:"BURST" env-default:"40" env-description:"default burst size per client"`
+}
+
+// RateLimitConfig holds the per-route limits and Auth, the per-IP limit
+// checked before the token of authenticated routes is resolved.
+type RateLimitConfig struct {
+	Default    RateLimit            `yaml:"default" env-prefix:"RATE_LIMIT_"`
+	Auth       RateLimit            `yaml:"auth" env-prefix:"RATE_LIMIT_AUTH_"`
+	Routes     map[string]RateLimit `yaml:"routes"`
+	TrustProxy bool                 `yaml:"trust_proxy" env:"RATE_LIMIT_TRUST_PROXY" env-default:"false" env-description:"take client IP from X-Forwarded-For"`
+}
+
+type CORSConfig struct {
+	AllowedOrigins   []string      `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" env-description:"allowed origins, * or https://*.example.com patterns"`
+	AllowedMethods   []string      `yaml:"allowed_methods" env:"CORS_ALLOWED_METHODS" env-default:"GET,POST,PUT,PATCH,DELETE" env-description:"methods allowed in preflight"`
+	AllowedHeaders   []string      `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS" env-default:"Authorization,Content-Type,Idempotency-Key,X-Request-ID" env-description:"request headers allowed in preflight"`
+	ExposedHeaders   []string      `yaml:"exposed_headers" env:"CORS_EXPOSED_HEADERS" env-default:"X-Request-ID,Retry-After,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,Deprecation,Sunset" env-description:"response headers readable by the browser"`
+	AllowCredentials bool          `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS" env-default:"false" env-description:"allow cookies and Authorization from browsers"`
+	MaxAge           time.Duration `yaml:"max_age" env:"CORS_MAX_AGE" env-default:"10m" env-description:"how long browsers may cache preflight results"`
+}
+
+// APIConfig describes the lifetime of the unversioned legacy routes that are
+// kept as deprecated aliases of /api/v1. Dates use the YYYY-MM-DD format.
+type APIConfig struct {
+	LegacyDeprecatedAt string `yaml:"legacy_deprecated_at" env:"API_LEGACY_DEPRECATED_AT" env-default:"2026-10-19" env-description:"date legacy routes were deprecated"`
+	LegacySunset       string `yaml:"legacy_sunset" env:"API_LEGACY_SUNSET" env-default:"2027-04-19" env-description:"date legacy routes will be removed"`
+}
+
+// OpenAPIConfig toggles validation against the embedded OpenAPI document.
+// Response validation buffers every body and is meant for development.
+type OpenAPIConfig struct {
+	Validate          bool `yaml:"validate" env:"OPENAPI_VALIDATE" env-default:"false" env-description:"validate requests against the OpenAPI spec"`
+	ValidateResponses bool `yaml:"validate_responses" env:"OPENAPI_VALIDATE_RESPONSES" env-default:"false" env-description:"validate responses against the OpenAPI spec as well"`
+}
+
+type EventsConfig struct {
+	Heartbeat  time.Duration `yaml:"heartbeat" env:"EVENTS_HEARTBEAT" env-default:"15s" env-description:"interval of keep-alive comments in event streams"`
+	Retention  time.Duration `yaml:"retention" env:"EVENTS_RETENTION" env-default:"168h" env-description:"how long events are kept for Last-Event-ID resume"`
+	BufferSize int           `yaml:"buffer_size" env:"EVENTS_BUFFER_SIZE" env-default:"64" env-description:"events buffered per stream before a slow client is dropped"`
+}
+
+// WebhooksConfig tunes webhook delivery. Attempt n is retried after
+// BaseBackoff*2^(n-1), at most MaxBackoff; after MaxAttempts the delivery is
+// dead-lettered.
+type WebhooksConfig struct {
+	Timeout      time.Duration `yaml:"timeout" env:"WEBHOOKS_TIMEOUT" env-default:"10s" env-description:"timeout of a single delivery attempt"`
+	MaxAttempts  int           `yaml:"max_attempts" env:"WEBHOOKS_MAX_ATTEMPTS" env-default:"10" env-description:"attempts before a delivery is dead-lettered"`
+	BaseBackoff  time.Duration `yaml:"base_backoff" env:"WEBHOOKS_BASE_BACKOFF" env-default:"10s" env-description:"delay before the first retry, doubled on every next one"`
+	MaxBackoff   time.Duration `yaml:"max_backoff" env:"WEBHOOKS_MAX_BACKOFF" env-default:"1h" env-description:"longest delay between retries"`
+	PollInterval time.Duration `yaml:"poll_interval" env:"WEBHOOKS_POLL_INTERVAL" env-default:"5s" env-description:"how often due deliveries are looked up"`
+	BatchSize    int           `yaml:"batch_size" env:"WEBHOOKS_BATCH_SIZE" env-default:"20" env-description:"deliveries sent concurrently by one worker"`
+	AllowPrivate bool          `yaml:"allow_private" env:"WEBHOOKS_ALLOW_PRIVATE" env-default:"false" env-description:"deliver to loopback and private addresses too, e.g. receivers in the same network"`
+}
+
+// PhotosConfig describes where uploaded photos are kept and how they are
+// served. Thumbnails fit in a ThumbnailSize square.
+type PhotosConfig struct {
+	Dir           string        `yaml:"dir" env:"PHOTOS_DIR" env-default:"data/photos" env-description:"directory photo files are stored in"`
+	MaxBytes      int64         `yaml:"max_bytes" env:"PHOTOS_MAX_BYTES" env-default:"10485760" env-description:"maximum size of an uploaded photo in bytes"`
+	ThumbnailSize int           `yaml:"thumbnail_size" env:"PHOTOS_THUMBNAIL_SIZE" env-default:"320" env-description:"longest side of thumbnails in pixels"`
+	CacheMaxAge   time.Duration `yaml:"cache_max_age" env:"PHOTOS_CACHE_MAX_AGE" env-default:"720h" env-description:"how long clients may cache photo files"`
+	MaxProcessing int           `yaml:"max_processing" env:"PHOTOS_MAX_PROCESSING" env-default:"2" env-description:"uploads decoded at the same time, each may take about 300MB"`
+}
+
+// StatsConfig tunes the cache of market statistics, a zero CacheTTL
+// turns it off.
+type StatsConfig struct {
+	CacheTTL time.Duration `yaml:"cache_ttl" env:"STATS_CACHE_TTL" env-default:"5m" env-description:"how long market statistics are cached"`
+}
+
+// SearchesConfig tunes saved searches. Every Interval the searches of up to
+// BatchSize users are matched against newly approved flats and the matches
+// are sent to Notifier, log or file; the file notifier appends digests to
+// File as JSON lines.
+type SearchesConfig struct {
+	Interval   time.Duration `yaml:"interval" env:"SEARCHES_INTERVAL" env-default:"15m" env-description:"how often saved searches are checked"`
+	MaxPerUser int           `yaml:"max_per_user" env:"SEARCHES_MAX_PER_USER" env-default:"20" env-description:"saved searches a user may have"`
+	MaxMatches int           `yaml:"max_matches" env:"SEARCHES_MAX_MATCHES" env-default:"50" env-description:"flats listed per search in a digest, the rest are counted"`
+	BatchSize  int           `yaml:"batch_size" env:"SEARCHES_BATCH_SIZE" env-default:"100" env-description:"users whose searches are checked at once"`
+	Notifier   string        `yaml:"notifier" env:"SEARCHES_NOTIFIER" env-default:"log" env-description:"where digests are sent: log or file"`
+	File       string        `yaml:"file" env:"SEARCHES_FILE" env-default:"data/search_digests.jsonl" env-description:"file the file notifier appends digests to"`
+}
+
+const dateLayout = "2006-01-02"
+
+// LegacyDates returns the parsed deprecation and sunset dates.
+func (c APIConfig) LegacyDates() (time.Time, time.Time, error) {
+	deprecatedAt, err := time.Parse(dateLayout, c.LegacyDeprecatedAt)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	sunset, err := time.Parse(dateLayout, c.LegacySunset)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return deprecatedAt, sunset, nil
+}
+
+// Validate reports every problem found in the configuration at once.
+func (c *Config) Validate() error {
+	var problems []string
+	if c.Listen.Port == "" {
+		problems = append(problems, "listen.port (LISTEN_PORT) is required")
+	} else if !isPort(c.Listen.Port) {
+		problems = append(problems, fmt.Sprintf("listen.port (LISTEN_PORT) %q is not a valid port", c.Listen.Port))
+	}
+	if !isPort(c.GRPC.Port) {
+		problems = append(problems, fmt.Sprintf("grpc.port (GRPC_PORT) %q is not a valid port", c.GRPC.Port))
+	} else if c.GRPC.Port == c.Listen.Port {
+		problems = append(problems, "grpc.port (GRPC_PORT) must differ from listen.port")
+	}
+	if c.Listen.MaxBodyBytes < 1 {
+		problems = append(problems, "listen.max_body_bytes (LISTEN_MAX_BODY_BYTES) must be positive")
+	}
+	if c.Storage.Username == "" {
+		problems = append(problems, "storage.username (DB_USERNAME) is required")
+	}
+	if c.Storage.Password == "" {
+		problems = append(problems, "storage.password (DB_PASSWORD or DB_PASSWORD_FILE) is required")
+	}
+	if c.Storage.Host == "" {
+		problems = append(problems, "storage.host (DB_HOST) is required")
+	}
+	if !isPort(c.Storage.Port) {
+		problems = append(problems, fmt.Sprintf("storage.port (DB_PORT) %q is not a valid port", c.Storage.Port))
+	}
+	if c.Storage.Database == "" {
+		problems = append(problems, "storage.database (DB_NAME) is required")
+	}
+	if c.Storage.MaxAttempts < 1 {
+		problems = append(problems, "storage.max_attempts (DB_MAX_ATTEMPTS) must be at least 1")
+	}
+	if c.Shutdown.Timeout <= 0 {
+		problems = append(problems, "shutdown.timeout (SHUTDOWN_TIMEOUT) must be positive")
+	}
+	if c.Shutdown.ReadinessDelay < 0 {
+		problems = append(problems, "shutdown.readiness_delay (SHUTDOWN_READINESS_DELAY) must not be negative")
+	}
+	if c.Events.Heartbeat <= 0 {
+		problems = append(problems, "events.heartbeat (EVENTS_HEARTBEAT) must be positive")
+	}
+	if c.Events.Retention <= 0 {
+		problems = append(problems, "events.retention (EVENTS_RETENTION) must be positive")
+	}
+	if c.Events.BufferSize < 1 {
+		problems = append(problems, "events.buffer_size (EVENTS_BUFFER_SIZE) must be at least 1")
+	}
+	if c.Webhooks.Timeout <= 0 {
+		problems = append(problems, "webhooks.timeout (WEBHOOKS_TIMEOUT) must be positive")
+	}
+	if c.Webhooks.MaxAttempts < 1 {
+		problems = append(problems, "webhooks.max_attempts (WEBHOOKS_MAX_ATTEMPTS) must be at least 1")
+	}
+	if c.Webhooks.BaseBackoff <= 0 || c.Webhooks.MaxBackoff < c.Webhooks.BaseBackoff {
+		problems = append(problems, "webhooks.base_backoff (WEBHOOKS_BASE_BACKOFF) must be positive and not above webhooks.max_backoff (WEBHOOKS_MAX_BACKOFF)")
+	}
+	if c.Webhooks.PollInterval <= 0 {
+		problems = append(problems, "webhooks.poll_interval (WEBHOOKS_POLL_INTERVAL) must be positive")
+	}
+	if c.Webhooks.BatchSize < 1 {
+		problems = append(problems, "webhooks.batch_size (WEBHOOKS_BATCH_SIZE) must be at least 1")
+	}
+	if c.Photos.Dir == "" {
+		problems = append(problems, "photos.dir (PHOTOS_DIR) is required")
+	}
+	if c.Photos.MaxBytes < 1 {
+		problems = append(problems, "photos.max_bytes (PHOTOS_MAX_BYTES) must be positive")
+	}
+	if c.Photos.ThumbnailSize < 16 {
+		problems = append(problems, "photos.thumbnail_size (PHOTOS_THUMBNAIL_SIZE) must be at least 16")
+	}
+	if c.Photos.CacheMaxAge < 0 {
+		problems = append(problems, "photos.cache_max_age (PHOTOS_CACHE_MAX_AGE) must not be negative")
+	}
+	if c.Photos.MaxProcessing < 1 {
+		problems = append(problems, "photos.max_processing (PHOTOS_MAX_PROCESSING) must be positive")
+	}
+	if c.Stats.CacheTTL < 0 {
+		problems = append(problems, "stats.cache_ttl (STATS_CACHE_TTL) must not be negative")
+	}
+	if c.Searches.Interval <= 0 {
+		problems = append(problems, "searches.interval (SEARCHES_INTERVAL) must be positive")
+	}
+	if c.Searches.MaxPerUser < 1 {
+		problems = append(problems, "searches.max_per_user (SEARCHES_MAX_PER_USER) must be at least 1")
+	}
+	if c.Searches.MaxMatches < 1 {
+		problems = append(problems, "searches.max_matches (SEARCHES_MAX_MATCHES) must be at least 1")
+	}
+	if c.Searches.BatchSize < 1 {
+		problems = append(problems, "searches.batch_size (SEARCHES_BATCH_SIZE) must be at least 1")
+	}
+	switch c.Searches.Notifier {
+	case "log":
+	case "file":
+		if c.Searches.File == "" {
+			problems = append(problems, "searches.file (SEARCHES_FILE) is required by the file notifier")
+		}
+	default:
+		problems = append(problems, "searches.notifier (SEARCHES_NOTIFIER) must be log or file")
+	}
+	for route, rl := range c.RateLimit.allLimits() {
+		if rl.Rate < 0 || (rl.Rate > 0 && rl.Burst < 1) {
+			problems = append(problems, fmt.Sprintf("rate_limit %s: rate must not be negative and burst must be at least 1", route))
+		}
+	}
+	for _, o := range c.CORS.AllowedOrigins {
+		if o == "*" && c.CORS.AllowCredentials {
+			problems = append(problems, "cors.allowed_origins (CORS_ALLOWED_ORIGINS) cannot be * when credentials are allowed")
+		}
+		if strings.Count(o, "*") > 1 {
+			problems = append(problems, fmt.Sprintf("cors.allowed_origins (CORS_ALLOWED_ORIGINS) %q has more than one wildcard", o))
+		}
+	}
+	if deprecatedAt, sunset, err := c.API.LegacyDates(); err != nil {
+		problems = append(problems, fmt.Sprintf("api legacy dates (API_LEGACY_DEPRECATED_AT, API_LEGACY_SUNSET) must be YYYY-MM-DD: %v", err))
+	} else if !sunset.After(deprecatedAt) {
+		problems = append(problems, "api.legacy_sunset (API_LEGACY_SUNSET) must be after api.legacy_deprecated_at")
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New("invalid configuration:\n  - " + strings.Join(problems, "\n  - "))
+}
+
+func (c RateLimitConfig) allLimits() map[string]RateLimit {
+	all := make(map[string]RateLimit, len(c.Routes)+1)
+	for route, rl := range c.Routes {
+		all["route "+route] = rl
+	}
+	all["default"] = c.Default
+	all["auth"] = c.Auth
+	return all
+}
+
+// For returns the limit configured for a route path template.
+func (c RateLimitConfig) For(route string) RateLimit {
+	if rl, ok := c.Routes[route]; ok {
+		return rl
+	}
+	return c.Default
+}
+
+// DefaultPath returns the config path from CONFIG_PATH or the default one.
+func DefaultPath() string {
+	if p := os.Getenv(pathEnv); p != "" {
+		return p
+	}
+	return defaultPath
+}
+
+// Usage returns the list of supported environment variables.
+func Usage() string {
+	help, _ := cleanenv.GetDescription(&Config{}, nil)
+	return help + "\nAny variable may be replaced by <NAME>" + fileEnvSuffix + " pointing to a file with the value."
+}
+
+// Load reads the config file at path (if it exists), applies environment
+// overrides, secret files and defaults, then validates the result.
+// A missing file is an error only when the path was given explicitly.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	_, statErr := os.Stat(path)
+	switch {
+	case statErr == nil:
+		if err := cleanenv.ReadConfig(path, cfg); err != nil {
+			return nil, err
+		}
+	case errors.Is(statErr, os.ErrNotExist) && path == defaultPath:
+		if err := cleanenv.ReadEnv(cfg); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("read config %s: %w", path, statErr)
+	}
+	if err := applySecretFiles(reflect.ValueOf(cfg)); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applySecretFiles sets string fields from NAME_FILE for every env-tagged
+// field whose NAME is not set, so values such as Docker secrets can be used.
+func applySecretFiles(v reflect.Value) error {
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f, fv := t.Field(i), v.Field(i)
+		if f.Type.Kind() == reflect.Struct {
+			if err := applySecretFiles(fv); err != nil {
+				return err
+			}
+			continue
+		}
+		env, ok := f.Tag.Lookup("env")
+		if !ok || env == "" || f.Type.Kind() != reflect.String {
+			continue
+		}
+		name := strings.Split(env, ",")[0]
+		filePath, ok := os.LookupEnv(name + fileEnvSuffix)
+		if !ok {
+			continue
+		}
+		if _, set := os.LookupEnv(name); set {
+			return fmt.Errorf("both %s and %s%s are set", name, name, fileEnvSuffix)
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("read %s%s: %w", name, fileEnvSuffix, err)
+		}
+		fv.SetString(strings.TrimRight(string(content), "\r\n"))
+	}
+	return nil
+}
+
+func isPort(s string) bool {
+	p, err := strconv.Atoi(s)
+	return err == nil && p > 0 && p < 65536
 }
 
 var instance *Config
 var once sync.Once
 
-func Get(l logging.Logger) *Config {
+func Get(l logging.Logger, path string) *Config {
 	once.Do(func() {
-		instance = &Config{}
-		if err := cleanenv.ReadConfig("config.yaml", instance); err != nil {
-			help, _ := cleanenv.GetDescription(instance, nil)
-			l.Error(help)
+		cfg, err := Load(path)
+		if err != nil {
+			l.Error(Usage())
 			l.Fatal(err)
 		}
+		instance = cfg
 	})
 	return instance
 }
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestLoad_EnvOverridesFile(t *testing.T) {
+	p := writeFile(t, "config.yaml", "storage:\n  username: fileuser\n  password: filepass\n  database: db\nshutdown:\n  timeout: 3s\n")
+	t.Setenv("DB_USERNAME", "envuser")
+	cfg, err := Load(p)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Storage.Username != "envuser" {
+		t.Errorf("Storage.Username = %q, want envuser", cfg.Storage.Username)
+	}
+	if cfg.Storage.Password != "filepass" {
+		t.Errorf("Storage.Password = %q, want filepass", cfg.Storage.Password)
+	}
+	if cfg.Shutdown.Timeout != 3*time.Second {
+		t.Errorf("Shutdown.Timeout = %s, want 3s", cfg.Shutdown.Timeout)
+	}
+	if cfg.Listen.Port != "8080" || cfg.Storage.Port != "5432" || cfg.Storage.MaxAttempts != 5 {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+}
+
+func TestLoad_SecretFile(t *testing.T) {
+	p := writeFile(t, "config.yaml", "storage:\n  username: u\n  database: db\n")
+	secret := writeFile(t, "db_password", "s3cret\n")
+	t.Setenv("DB_PASSWORD_FILE", secret)
+	cfg, err := Load(p)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Storage.Password != "s3cret" {
+		t.Errorf("Storage.Password = %q, want s3cret", cfg.Storage.Password)
+	}
+}
+
+func TestLoad_ValidationListsProblems(t *testing.T) {
+	p := writeFile(t, "config.yaml", "listen:\n  port: abc\nsearches:\n  notifier: mail\n")
+	_, err := Load(p)
+	if err == nil {
+		t.Fatal("Load() error = nil, want validation error")
+	}
+	for _, want := range []string{"LISTEN_PORT", "DB_USERNAME", "DB_PASSWORD", "DB_NAME", "SEARCHES_NOTIFIER"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+}
+
+func TestLoad_MissingExplicitFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "nope.yaml"))
+	if err == nil {
+		t.Error("Load() error = nil, want error for missing file")
+	}
+}
@@ -0,0 +1,5 @@
+package developer
+
+type DeveloperDTO struct {
+	Name string `json:"name" validate:"required,max=200"`
+}
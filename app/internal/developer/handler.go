@@ -0,0 +1,181 @@
+package developer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/handlers"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+const (
+	developersURL = "/developers"
+	developerURL  = "/developers/{id}"
+	housesURL     = "/developers/{id}/houses"
+)
+
+type handler struct {
+	aumw  middleware.Middleware
+	modmw middleware.Middleware
+	s     *Service
+	l     logging.Logger
+}
+
+func NewHandler(aumw middleware.Middleware, modmw middleware.Middleware, s *Service, l logging.Logger) handlers.Handler {
+	return &handler{aumw: aumw, modmw: modmw, s: s, l: l}
+}
+
+// Register mounts the developer routes, changes are for moderators only.
+func (h *handler) Register(r *mux.Router) {
+	r.Handle(developersURL, h.modmw.DoInMiddle(http.HandlerFunc(h.Create))).Methods(http.MethodPost)
+	r.Handle(developersURL, h.aumw.DoInMiddle(http.HandlerFunc(h.List))).Methods(http.MethodGet)
+	r.Handle(developerURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Get))).Methods(http.MethodGet)
+	r.Handle(developerURL, h.modmw.DoInMiddle(http.HandlerFunc(h.Update))).Methods(http.MethodPut)
+	r.Handle(developerURL, h.modmw.DoInMiddle(http.HandlerFunc(h.Delete))).Methods(http.MethodDelete)
+	r.Handle(housesURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Houses))).Methods(http.MethodGet)
+}
+
+func (h *handler) Create(w http.ResponseWriter, r *http.Request) {
+	dto, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.s.Create(r.Context(), dto)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.respond(w, r, d)
+}
+
+// List finds developers by the name query parameter, any spelling of the
+// name matches.
+func (h *handler) List(w http.ResponseWriter, r *http.Request) {
+	ds, err := h.s.List(r.Context(), r.URL.Query().Get("name"))
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.respond(w, r, ds)
+}
+
+func (h *handler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.developerID(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.s.Get(r.Context(), id)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.respond(w, r, d)
+}
+
+func (h *handler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.developerID(w, r)
+	if !ok {
+		return
+	}
+	dto, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.s.Update(r.Context(), id, dto)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.respond(w, r, d)
+}
+
+func (h *handler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.developerID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.s.Delete(r.Context(), id); err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Houses lists the houses of the developer with their flat counts.
+func (h *handler) Houses(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.developerID(w, r)
+	if !ok {
+		return
+	}
+	hs, err := h.s.Houses(r.Context(), id)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.respond(w, r, hs)
+}
+
+func (h *handler) decode(w http.ResponseWriter, r *http.Request) (DeveloperDTO, bool) {
+	reqID := middleware.RequestID(r.Context())
+	var dto DeveloperDTO
+	code, err := handlers.DecodeJSON(r, &dto)
+	if err != nil {
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, code)
+		return DeveloperDTO{}, false
+	}
+	dto.Name = strings.TrimSpace(dto.Name)
+	validate := validator.New()
+	err = validate.Struct(dto)
+	if err != nil {
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusBadRequest)
+		return DeveloperDTO{}, false
+	}
+	return dto, true
+}
+
+func (h *handler) developerID(w http.ResponseWriter, r *http.Request) (DeveloperID, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id < 1 {
+		reqID := middleware.RequestID(r.Context())
+		invalidIDErr := errors.New("invalid developer id")
+		h.l.Errorf("bad request req_id=%s: %v", reqID, invalidIDErr)
+		apierror.Write(w, invalidIDErr, reqID, http.StatusBadRequest)
+		return 0, false
+	}
+	return DeveloperID(id), true
+}
+
+func (h *handler) fail(w http.ResponseWriter, r *http.Request, err error) {
+	reqID := middleware.RequestID(r.Context())
+	switch {
+	case errors.Is(err, ErrNotFound):
+		h.l.Errorf("not found req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusNotFound)
+	case errors.Is(err, ErrExists), errors.Is(err, ErrHasHouses):
+		h.l.Errorf("conflict req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusConflict)
+	default:
+		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusInternalServerError)
+	}
+}
+
+func (h *handler) respond(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	h.encode(w, r, v)
+}
+
+func (h *handler) encode(w http.ResponseWriter, r *http.Request, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.l.Errorf("internal error req_id=%s: %v", middleware.RequestID(r.Context()), err)
+	}
+}
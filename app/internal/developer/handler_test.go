@@ -0,0 +1,159 @@
+package developer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Polyrom/houses_api/internal/house"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/internal/middleware/middlewaretest"
+	"github.com/gorilla/mux"
+)
+
+type MockLogger struct{}
+
+func (ml *MockLogger) Trace(args ...interface{})                   {}
+func (ml *MockLogger) Debug(args ...interface{})                   {}
+func (ml *MockLogger) Info(args ...interface{})                    {}
+func (ml *MockLogger) Warn(args ...interface{})                    {}
+func (ml *MockLogger) Warning(args ...interface{})                 {}
+func (ml *MockLogger) Error(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   {}
+func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
+func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
+func (ml *MockLogger) Infof(format string, args ...interface{})    {}
+func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
+func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
+func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
+func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
+
+// MockDeveloperRepo knows developer 1 with one house of two flats, one of
+// them approved.
+type MockDeveloperRepo struct {
+	Repository
+	created string
+}
+
+func (mr *MockDeveloperRepo) Create(ctx context.Context, name string) (Developer, error) {
+	if name == "ПИК" {
+		return Developer{}, ErrExists
+	}
+	mr.created = name
+	return Developer{ID: 2, Name: name}, nil
+}
+
+func (mr *MockDeveloperRepo) Get(ctx context.Context, id DeveloperID) (Developer, error) {
+	if id != 1 {
+		return Developer{}, ErrNotFound
+	}
+	return Developer{ID: 1, Name: "ПИК"}, nil
+}
+
+func (mr *MockDeveloperRepo) Delete(ctx context.Context, id DeveloperID) error {
+	if id == 1 {
+		return ErrHasHouses
+	}
+	return ErrNotFound
+}
+
+func (mr *MockDeveloperRepo) Houses(ctx context.Context, id DeveloperID, allFlats bool) ([]DeveloperHouse, error) {
+	flats := 1
+	if allFlats {
+		flats = 2
+	}
+	return []DeveloperHouse{{House: house.House{ID: 7, Address: "Lenina 1"}, Flats: flats}}, nil
+}
+
+func newRouter(repo Repository) *mux.Router {
+	router := mux.NewRouter()
+	NewHandler(middlewaretest.Auth{}, middlewaretest.Auth{}, NewService(repo, &MockLogger{}), &MockLogger{}).Register(router)
+	return router
+}
+
+func TestHandler_Houses(t *testing.T) {
+	router := newRouter(&MockDeveloperRepo{})
+	tests := []struct {
+		role  middleware.Role
+		flats int
+	}{
+		{role: middleware.Client, flats: 1},
+		{role: middleware.Moderator, flats: 2},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/developers/1/houses", nil)
+			req.Header.Set("X-Role", string(tt.role))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("code = %d, want 200: %s", rr.Code, rr.Body)
+			}
+			var got []DeveloperHouse
+			if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(got) != 1 || got[0].ID != 7 || got[0].Flats != tt.flats {
+				t.Errorf("houses = %+v, want house 7 with %d flats", got, tt.flats)
+			}
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/developers/3/houses", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("unknown developer code = %d, want 404", rr.Code)
+	}
+}
+
+func TestHandler_Create(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantName string
+	}{
+		{name: "created", body: `{"name":"  Самолет "}`, wantCode: http.StatusOK, wantName: "Самолет"},
+		{name: "blank name", body: `{"name":"   "}`, wantCode: http.StatusBadRequest},
+		{name: "name too long", body: `{"name":"` + strings.Repeat("a", 201) + `"}`, wantCode: http.StatusBadRequest},
+		{name: "same key", body: `{"name":"ПИК"}`, wantCode: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockDeveloperRepo{}
+			req := httptest.NewRequest(http.MethodPost, "/developers", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			newRouter(repo).ServeHTTP(rr, req)
+			if rr.Code != tt.wantCode {
+				t.Fatalf("code = %d, want %d: %s", rr.Code, tt.wantCode, rr.Body)
+			}
+			if repo.created != tt.wantName {
+				t.Errorf("created %q, want %q", repo.created, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestHandler_Delete(t *testing.T) {
+	router := newRouter(&MockDeveloperRepo{})
+	tests := []struct {
+		path     string
+		wantCode int
+	}{
+		{path: "/developers/1", wantCode: http.StatusConflict},
+		{path: "/developers/2", wantCode: http.StatusNotFound},
+		{path: "/developers/0", wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, tt.path, nil))
+		if rr.Code != tt.wantCode {
+			t.Errorf("DELETE %s code = %d, want %d", tt.path, rr.Code, tt.wantCode)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package developer
+
+import (
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/house"
+)
+
+type DeveloperID int
+
+type Developer struct {
+	ID        DeveloperID `json:"id"`
+	Name      string      `json:"name"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdateAt  time.Time   `json:"update_at"`
+}
+
+// DeveloperHouse is a house of the developer with the number of its flats
+// the user can see.
+type DeveloperHouse struct {
+	house.House
+	Flats int `json:"flats"`
+}
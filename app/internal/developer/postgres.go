@@ -0,0 +1,150 @@
+package developer
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Polyrom/houses_api/internal/house"
+	"github.com/Polyrom/houses_api/pkg/client/postgres"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+)
+
+const developerColumns = `id, name, created_at, update_at`
+
+type repository struct {
+	client postgres.Client
+	logger logging.Logger
+}
+
+func scanDeveloper(row pgx.Row) (Developer, error) {
+	var d Developer
+	err := row.Scan(&d.ID, &d.Name, &d.CreatedAt, &d.UpdateAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Developer{}, ErrNotFound
+	}
+	return d, err
+}
+
+// mapError turns constraint violations into the errors of the package.
+func (r *repository) mapError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case uniqueViolation:
+		return ErrExists
+	case foreignKeyViolation:
+		return ErrHasHouses
+	}
+	r.logger.Errorf("SQL Error: %s, Detail: %s, Where: %s", pgErr.Message, pgErr.Detail, pgErr.Where)
+	return err
+}
+
+func (r *repository) Create(ctx context.Context, name string) (Developer, error) {
+	q := `INSERT INTO developers
+					(name)
+				VALUES
+					(btrim($1))
+				RETURNING ` + developerColumns
+	d, err := scanDeveloper(r.client.QueryRow(ctx, q, name))
+	if err != nil {
+		return Developer{}, r.mapError(err)
+	}
+	return d, nil
+}
+
+func (r *repository) Update(ctx context.Context, id DeveloperID, name string) (Developer, error) {
+	q := `UPDATE developers
+				SET
+					name = btrim($2),
+					update_at = CURRENT_TIMESTAMP
+				WHERE id = $1
+				RETURNING ` + developerColumns
+	d, err := scanDeveloper(r.client.QueryRow(ctx, q, id, name))
+	if err != nil {
+		return Developer{}, r.mapError(err)
+	}
+	return d, nil
+}
+
+func (r *repository) Delete(ctx context.Context, id DeveloperID) error {
+	q := `DELETE FROM developers WHERE id = $1`
+	tag, err := r.client.Exec(ctx, q, id)
+	if err != nil {
+		return r.mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *repository) Get(ctx context.Context, id DeveloperID) (Developer, error) {
+	q := `SELECT ` + developerColumns + ` FROM developers WHERE id = $1`
+	return scanDeveloper(r.client.QueryRow(ctx, q, id))
+}
+
+func (r *repository) List(ctx context.Context, name string) ([]Developer, error) {
+	q := `SELECT 
+					` + developerColumns + `
+				FROM 
+					developers
+				WHERE $1 = '' 
+				OR position(developer_key($1) IN key) > 0
+				ORDER BY name, id`
+	rows, err := r.client.Query(ctx, q, strings.TrimSpace(name))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ds := make([]Developer, 0)
+	for rows.Next() {
+		d, err := scanDeveloper(rows)
+		if err != nil {
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+	return ds, rows.Err()
+}
+
+func (r *repository) Houses(ctx context.Context, id DeveloperID, allFlats bool) ([]DeveloperHouse, error) {
+	q := `SELECT 
+					` + house.Columns + `, COUNT(f.id) FILTER (WHERE $2 OR f.status = 'approved')
+				FROM 
+					` + house.FromHouses + `
+					LEFT JOIN flats AS f ON f.house_id = h.id
+				WHERE h.developer_id = $1
+				GROUP BY h.id, d.id
+				ORDER BY h.id`
+	rows, err := r.client.Query(ctx, q, id, allFlats)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	hs := make([]DeveloperHouse, 0)
+	for rows.Next() {
+		var h DeveloperHouse
+		if err = house.Scan(rows, &h.House, &h.Flats); err != nil {
+			return nil, err
+		}
+		hs = append(hs, h)
+	}
+	return hs, rows.Err()
+}
+
+func NewRepository(c postgres.Client, l logging.Logger) Repository {
+	return &repository{
+		client: c,
+		logger: l,
+	}
+}
@@ -0,0 +1,56 @@
+package developer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/pkg/logging"
+)
+
+var (
+	ErrNotFound  = errors.New("developer not found")
+	ErrExists    = errors.New("developer with the same name already exists")
+	ErrHasHouses = errors.New("developer still has houses")
+)
+
+type Service struct {
+	repo   Repository
+	logger logging.Logger
+}
+
+func (s *Service) Create(ctx context.Context, dto DeveloperDTO) (Developer, error) {
+	return s.repo.Create(ctx, dto.Name)
+}
+
+func (s *Service) Update(ctx context.Context, id DeveloperID, dto DeveloperDTO) (Developer, error) {
+	return s.repo.Update(ctx, id, dto.Name)
+}
+
+// Delete removes a developer without houses.
+func (s *Service) Delete(ctx context.Context, id DeveloperID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *Service) Get(ctx context.Context, id DeveloperID) (Developer, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// List finds developers by any spelling of their name.
+func (s *Service) List(ctx context.Context, name string) ([]Developer, error) {
+	return s.repo.List(ctx, name)
+}
+
+// Houses lists the houses of the developer. Moderators get all flats
+// counted, other users only the approved ones they can see.
+func (s *Service) Houses(ctx context.Context, id DeveloperID) ([]DeveloperHouse, error) {
+	if _, err := s.repo.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	userRole, _ := middleware.CurrentRole(ctx)
+	return s.repo.Houses(ctx, id, userRole == middleware.Moderator)
+}
+
+func NewService(r Repository, l logging.Logger) *Service {
+	return &Service{repo: r, logger: l}
+}
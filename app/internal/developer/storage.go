@@ -0,0 +1,16 @@
+package developer
+
+import "context"
+
+type Repository interface {
+	Create(ctx context.Context, name string) (Developer, error)
+	Update(ctx context.Context, id DeveloperID, name string) (Developer, error)
+	Delete(ctx context.Context, id DeveloperID) error
+	Get(ctx context.Context, id DeveloperID) (Developer, error)
+	// List returns the developers whose key contains the key of name, all
+	// of them if name is empty.
+	List(ctx context.Context, name string) ([]Developer, error)
+	// Houses counts all flats of the houses if allFlats is set and only
+	// approved ones otherwise.
+	Houses(ctx context.Context, id DeveloperID, allFlats bool) ([]DeveloperHouse, error)
+}
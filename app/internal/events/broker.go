@@ -0,0 +1,78 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/Polyrom/houses_api/internal/metrics"
+)
+
+const defaultBufferSize = 64
+
+// Broker fans events out to the streams connected to this instance.
+// A subscriber whose buffer is full is dropped instead of slowing down
+// publishers; its stream ends and the client resumes with Last-Event-ID.
+type Broker struct {
+	mu      sync.Mutex
+	subs    map[chan Event]struct{}
+	bufSize int
+	closed  bool
+}
+
+// Subscribe returns a channel of events published from now on and a function
+// that releases it. The channel is closed when the subscriber is dropped or
+// the broker is closed.
+func (b *Broker) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, b.bufSize)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subs[ch] = struct{}{}
+	metrics.StreamSubscribers.Add(1)
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(ch)
+	}
+}
+
+func (b *Broker) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			metrics.StreamSubscribersDropped.Add(1)
+			b.remove(ch)
+		}
+	}
+}
+
+// Close ends all streams so that HTTP shutdown does not wait for them.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for ch := range b.subs {
+		b.remove(ch)
+	}
+}
+
+func (b *Broker) remove(ch chan Event) {
+	if _, ok := b.subs[ch]; !ok {
+		return
+	}
+	delete(b.subs, ch)
+	close(ch)
+	metrics.StreamSubscribers.Add(-1)
+}
+
+func NewBroker(bufSize int) *Broker {
+	if bufSize <= 0 {
+		bufSize = defaultBufferSize
+	}
+	return &Broker{subs: make(map[chan Event]struct{}), bufSize: bufSize}
+}
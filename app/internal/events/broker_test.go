@@ -0,0 +1,50 @@
+package events
+
+import "testing"
+
+func TestBroker_FanOut(t *testing.T) {
+	b := NewBroker(2)
+	first, unsubscribeFirst := b.Subscribe()
+	second, unsubscribeSecond := b.Subscribe()
+	defer unsubscribeSecond()
+
+	b.Publish(Event{ID: 1})
+	for i, ch := range []<-chan Event{first, second} {
+		if e := <-ch; e.ID != 1 {
+			t.Errorf("subscriber %d got event %d, want 1", i, e.ID)
+		}
+	}
+	unsubscribeFirst()
+	if _, ok := <-first; ok {
+		t.Errorf("channel still open after unsubscribe")
+	}
+	unsubscribeFirst()
+}
+
+func TestBroker_DropsSlowSubscriber(t *testing.T) {
+	b := NewBroker(1)
+	slow, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+	b.Publish(Event{ID: 1})
+	b.Publish(Event{ID: 2})
+	if e := <-slow; e.ID != 1 {
+		t.Errorf("got event %d, want 1", e.ID)
+	}
+	if _, ok := <-slow; ok {
+		t.Errorf("slow subscriber was not dropped")
+	}
+}
+
+func TestBroker_Close(t *testing.T) {
+	b := NewBroker(1)
+	ch, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+	b.Close()
+	if _, ok := <-ch; ok {
+		t.Errorf("channel still open after Close")
+	}
+	late, _ := b.Subscribe()
+	if _, ok := <-late; ok {
+		t.Errorf("subscribe after Close returned an open channel")
+	}
+}
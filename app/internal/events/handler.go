@@ -0,0 +1,188 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/favorite"
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/handlers"
+	"github.com/Polyrom/houses_api/internal/house"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/internal/modstatus"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/gorilla/mux"
+)
+
+const (
+	streamURL         = "/events/stream"
+	lastEventIDHeader = "Last-Event-ID"
+	lastEventIDParam  = "last_event_id"
+	defaultHeartbeat  = 15 * time.Second
+	clientRetryMillis = 3000
+)
+
+type handler struct {
+	aumw      middleware.Middleware
+	s         *Service
+	hs        *house.Service
+	fs        *favorite.Service
+	heartbeat time.Duration
+	l         logging.Logger
+}
+
+func NewHandler(aumw middleware.Middleware, s *Service, hs *house.Service, fs *favorite.Service, heartbeat time.Duration, l logging.Logger) handlers.Handler {
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeat
+	}
+	return &handler{aumw: aumw, s: s, hs: hs, fs: fs, heartbeat: heartbeat, l: l}
+}
+
+func (h *handler) Register(r *mux.Router) {
+	r.Handle(streamURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Stream))).Methods(http.MethodGet)
+}
+
+// Stream sends events as Server-Sent Events. Moderators get every event,
+// clients only approved flats of the houses they are subscribed to and price
+// changes of their favorite flats. A stream resumed with Last-Event-ID first replays the missed events from the log.
+func (h *handler) Stream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	reqID := middleware.RequestID(ctx)
+	lastID, err := lastEventID(r)
+	if err != nil {
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusBadRequest)
+		return
+	}
+	visible, err := h.filter(ctx)
+	if err != nil {
+		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusInternalServerError)
+		return
+	}
+	rc := http.NewResponseController(w)
+	// Streams outlive the server write timeout.
+	if err = rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.l.Warnf("reset write deadline req_id=%s: %v", reqID, err)
+	}
+	// Subscribe before replaying so that nothing published meanwhile is lost;
+	// duplicates are skipped by ID, which the log assigns in commit order.
+	live, unsubscribe := h.s.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err = fmt.Fprintf(w, "retry: %d\n\n", clientRetryMillis); err != nil {
+		return
+	}
+	if err = rc.Flush(); err != nil {
+		h.l.Errorf("stream not supported req_id=%s: %v", reqID, err)
+		return
+	}
+
+	sent := lastID
+	send := func(e Event) error {
+		if e.ID <= sent {
+			return nil
+		}
+		sent = e.ID
+		if !visible(e) {
+			return nil
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, e.Data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+	if lastID > 0 {
+		if err = h.s.Replay(ctx, lastID, send); err != nil {
+			if ctx.Err() == nil {
+				h.l.Errorf("replay events req_id=%s: %v", reqID, err)
+			}
+			return
+		}
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-live:
+			if !ok {
+				h.l.Infof("event stream closed by server req_id=%s", reqID)
+				return
+			}
+			if err = send(e); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err = fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			if err = rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// filter returns which events the current user may see. Subscriptions and
+// favorites are read once, so changes apply to streams opened afterwards.
+func (h *handler) filter(ctx context.Context) (func(e Event) bool, error) {
+	if role, _ := middleware.CurrentRole(ctx); role == middleware.Moderator {
+		return func(Event) bool { return true }, nil
+	}
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		return nil, house.ErrNotAuthenticated
+	}
+	ids, err := h.hs.SubscribedHouseIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	followed := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		followed[int(id)] = true
+	}
+	flatIDs, err := h.fs.FavoriteFlatIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	favorites := make(map[int]bool, len(flatIDs))
+	for _, id := range flatIDs {
+		favorites[int(id)] = true
+	}
+	approved := modstatus.Approved.String()
+	return func(e Event) bool {
+		if e.Status != approved {
+			return false
+		}
+		return followed[e.HouseID] || e.Type == string(flat.EventPriceChanged) && favorites[e.FlatID]
+	}, nil
+}
+
+// lastEventID reads the ID to resume from. Browsers send the header on
+// reconnect; the query parameter covers the first connection.
+func lastEventID(r *http.Request) (int64, error) {
+	v := r.Header.Get(lastEventIDHeader)
+	if v == "" {
+		v = r.URL.Query().Get(lastEventIDParam)
+	}
+	if v == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || id < 0 {
+		return 0, errors.New("invalid Last-Event-ID")
+	}
+	return id, nil
+}
@@ -0,0 +1,218 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/favorite"
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/house"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/internal/middleware/middlewaretest"
+	"github.com/Polyrom/houses_api/internal/modstatus"
+	"github.com/gorilla/mux"
+)
+
+type MockLogger struct{}
+
+func (ml *MockLogger) Trace(args ...interface{})                   {}
+func (ml *MockLogger) Debug(args ...interface{})                   {}
+func (ml *MockLogger) Info(args ...interface{})                    {}
+func (ml *MockLogger) Warn(args ...interface{})                    {}
+func (ml *MockLogger) Warning(args ...interface{})                 {}
+func (ml *MockLogger) Error(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   {}
+func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
+func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
+func (ml *MockLogger) Infof(format string, args ...interface{})    {}
+func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
+func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
+func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
+func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
+
+type MockEventRepo struct {
+	mu  sync.Mutex
+	log []Event
+}
+
+func (mr *MockEventRepo) Append(ctx context.Context, evs []Event) ([]Event, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	stored := make([]Event, 0, len(evs))
+	for _, e := range evs {
+		e.ID = int64(len(mr.log) + 1)
+		mr.log = append(mr.log, e)
+		stored = append(stored, e)
+	}
+	return stored, nil
+}
+
+// waitLogged waits until the queued events reach the log.
+func (mr *MockEventRepo) waitLogged(t *testing.T, n int) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		mr.mu.Lock()
+		logged := len(mr.log)
+		mr.mu.Unlock()
+		if logged >= n {
+			return
+		}
+	}
+	t.Fatalf("events not logged in time")
+}
+
+func (mr *MockEventRepo) ListAfter(ctx context.Context, afterID int64, limit int) ([]Event, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	evs := make([]Event, 0)
+	for _, e := range mr.log {
+		if e.ID > afterID && len(evs) < limit {
+			evs = append(evs, e)
+		}
+	}
+	return evs, nil
+}
+
+func (mr *MockEventRepo) DeleteBefore(ctx context.Context, t time.Time) (int64, error) {
+	return 0, nil
+}
+
+type MockHouseRepo struct {
+	house.Repository
+}
+
+func (mr *MockHouseRepo) SubscribedHouseIDs(ctx context.Context, uid string) ([]house.HouseID, error) {
+	return []house.HouseID{1}, nil
+}
+
+type MockFavoriteRepo struct {
+	favorite.Repository
+}
+
+func (mr *MockFavoriteRepo) FlatIDs(ctx context.Context, uid string) ([]flat.FlatID, error) {
+	return []flat.FlatID{5}, nil
+}
+
+func flatEvent(typ flat.EventType, id, houseID int, status modstatus.ModerationStatus) flat.Event {
+	return flat.Event{Type: typ, Flat: flat.FlatDTO{ID: id, HouseID: houseID, Price: 100, Rooms: 1, Status: status.String()}}
+}
+
+func readEvents(t *testing.T, sc *bufio.Scanner, n int) []string {
+	t.Helper()
+	var got []string
+	for len(got) < n && sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "id: ") {
+			got = append(got, strings.TrimPrefix(line, "id: "))
+		}
+	}
+	if len(got) < n {
+		t.Fatalf("got %d events %v, want %d (err %v)", len(got), got, n, sc.Err())
+	}
+	return got
+}
+
+func TestStream(t *testing.T) {
+	repo := &MockEventRepo{}
+	s := NewService(repo, NewBroker(8), &MockLogger{})
+	runCtx, stop := context.WithCancel(context.Background())
+	defer stop()
+	go s.Run(runCtx)
+	hs := house.NewService(&MockHouseRepo{}, &MockLogger{})
+	fs := favorite.NewService(&MockFavoriteRepo{}, &MockLogger{})
+	router := mux.NewRouter()
+	NewHandler(middlewaretest.Auth{UserID: "user-1"}, s, hs, fs, 20*time.Millisecond, &MockLogger{}).Register(router)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	// Logged before anyone connects, replayed on resume.
+	s.OnFlatEvent(context.Background(), flatEvent(flat.EventCreated, 1, 1, modstatus.Created))
+	s.OnFlatEvent(context.Background(), flatEvent(flat.EventStatusChanged, 1, 1, modstatus.Approved))
+	s.OnFlatEvent(context.Background(), flatEvent(flat.EventCreated, 2, 2, modstatus.Created))
+	// A favorite flat in another house and one that is not.
+	s.OnFlatEvent(context.Background(), flatEvent(flat.EventPriceChanged, 5, 2, modstatus.Approved))
+	s.OnFlatEvent(context.Background(), flatEvent(flat.EventPriceChanged, 6, 2, modstatus.Approved))
+	repo.waitLogged(t, 5)
+
+	tests := []struct {
+		name   string
+		role   middleware.Role
+		lastID string
+		live   bool
+		want   []string
+	}{
+		{name: "moderator resumes and follows live events", role: middleware.Moderator, lastID: "1", live: true, want: []string{"2", "3", "4", "5", "6", "7", "8"}},
+		{name: "moderator resumes after last id", role: middleware.Moderator, lastID: "6", want: []string{"7", "8"}},
+		{name: "client sees approved flats of followed houses and favorites", role: middleware.Client, lastID: "1", want: []string{"2", "4", "8"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+streamURL, nil)
+			req.Header.Set("X-Role", string(tt.role))
+			req.Header.Set(lastEventIDHeader, tt.lastID)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("connect: %v", err)
+			}
+			defer resp.Body.Close()
+			if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+				t.Fatalf("Content-Type = %q", ct)
+			}
+			if tt.live {
+				// One in another house, one declined, one approved.
+				s.OnFlatEvent(context.Background(), flatEvent(flat.EventStatusChanged, 2, 2, modstatus.Approved))
+				s.OnFlatEvent(context.Background(), flatEvent(flat.EventStatusChanged, 3, 1, modstatus.Declined))
+				s.OnFlatEvent(context.Background(), flatEvent(flat.EventStatusChanged, 4, 1, modstatus.Approved))
+			}
+			got := readEvents(t, bufio.NewScanner(resp.Body), len(tt.want))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("event ids = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStream_Heartbeat(t *testing.T) {
+	s := NewService(&MockEventRepo{}, NewBroker(8), &MockLogger{})
+	router := mux.NewRouter()
+	NewHandler(middlewaretest.Auth{UserID: "user-1"}, s, nil, nil, 10*time.Millisecond, &MockLogger{}).Register(router)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+streamURL, nil)
+	req.Header.Set("X-Role", string(middleware.Moderator))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer resp.Body.Close()
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		if sc.Text() == ": heartbeat" {
+			return
+		}
+	}
+	t.Fatalf("no heartbeat: %v", sc.Err())
+}
+
+func TestStream_InvalidLastEventID(t *testing.T) {
+	s := NewService(&MockEventRepo{}, NewBroker(8), &MockLogger{})
+	router := mux.NewRouter()
+	NewHandler(middlewaretest.Auth{UserID: "user-1"}, s, nil, nil, time.Second, &MockLogger{}).Register(router)
+	req := httptest.NewRequest(http.MethodGet, streamURL+"?last_event_id=abc", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("code = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+}
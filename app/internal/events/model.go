@@ -0,0 +1,17 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event is an entry of the event log. Data is the JSON sent to subscribers.
+type Event struct {
+	ID        int64
+	Type      string
+	HouseID   int
+	FlatID    int
+	Status    string
+	Data      json.RawMessage
+	CreatedAt time.Time
+}
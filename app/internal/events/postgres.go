@@ -0,0 +1,96 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Polyrom/houses_api/pkg/client/postgres"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// appendLockID is the key of the advisory lock that serializes appends to
+// the event log across instances.
+const appendLockID int64 = 7_346_021_119
+
+type repository struct {
+	client postgres.TxClient
+	logger logging.Logger
+}
+
+// Append inserts evs under an advisory lock held until commit. IDs come
+// from a sequence and would otherwise become visible out of order, so a
+// reader that has seen ID n could later miss a smaller one committed after
+// it. The lock is only taken by the background writer of each instance,
+// once per batch.
+func (r *repository) Append(ctx context.Context, evs []Event) ([]Event, error) {
+	tx, err := r.client.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	if _, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, appendLockID); err != nil {
+		return nil, err
+	}
+	q := `INSERT INTO events
+					(type, house_id, flat_id, status, data)
+				VALUES
+					($1, $2, $3, $4, $5)
+				RETURNING
+					id, created_at`
+	stored := make([]Event, 0, len(evs))
+	for _, e := range evs {
+		err = tx.QueryRow(ctx, q, e.Type, e.HouseID, e.FlatID, e.Status, e.Data).Scan(&e.ID, &e.CreatedAt)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				r.logger.Errorf("SQL Error: %s, Detail: %s, Where: %s", pgErr.Message, pgErr.Detail, pgErr.Where)
+			}
+			return nil, err
+		}
+		stored = append(stored, e)
+	}
+	return stored, tx.Commit(ctx)
+}
+
+func (r *repository) ListAfter(ctx context.Context, afterID int64, limit int) ([]Event, error) {
+	q := `SELECT
+					id, type, house_id, flat_id, status, data, created_at
+				FROM
+					events
+				WHERE id > $1
+				ORDER BY id
+				LIMIT $2`
+	rows, err := r.client.Query(ctx, q, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	evs := make([]Event, 0)
+	for rows.Next() {
+		var e Event
+		err = rows.Scan(&e.ID, &e.Type, &e.HouseID, &e.FlatID, &e.Status, &e.Data, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		evs = append(evs, e)
+	}
+	return evs, rows.Err()
+}
+
+func (r *repository) DeleteBefore(ctx context.Context, t time.Time) (int64, error) {
+	q := `DELETE FROM events WHERE created_at < $1`
+	tag, err := r.client.Exec(ctx, q, t)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func NewRepository(c postgres.TxClient, l logging.Logger) Repository {
+	return &repository{
+		client: c,
+		logger: l,
+	}
+}
@@ -0,0 +1,141 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/metrics"
+	"github.com/Polyrom/houses_api/pkg/logging"
+)
+
+const (
+	// queueSize bounds the events waiting to be logged; more are dropped
+	// rather than holding up the requests that changed the flats.
+	queueSize        = 1024
+	appendBatchSize  = 100
+	replayBatchSize  = 500
+	retentionPeriod  = time.Hour
+	pruneCallTimeout = time.Minute
+)
+
+type Service struct {
+	queue  chan Event
+	repo   Repository
+	broker *Broker
+	logger logging.Logger
+}
+
+// OnFlatEvent queues the change to be logged and published. Failures are
+// logged only, the change itself is already committed.
+func (s *Service) OnFlatEvent(ctx context.Context, fe flat.Event) {
+	data, err := json.Marshal(fe.Data())
+	if err != nil {
+		s.logger.Errorf("encode event %s: %v", fe.Type, err)
+		return
+	}
+	e := Event{
+		Type:    string(fe.Type),
+		HouseID: fe.Flat.HouseID,
+		FlatID:  fe.Flat.ID,
+		Status:  fe.Flat.Status,
+		Data:    data,
+	}
+	s.Publish(e)
+}
+
+// Publish queues e for Run without waiting for the event log.
+func (s *Service) Publish(e Event) {
+	select {
+	case s.queue <- e:
+	default:
+		metrics.EventsDropped.Add(1)
+		s.logger.Errorf("event queue full, dropped event %s of flat %d", e.Type, e.FlatID)
+	}
+}
+
+// Run appends queued events to the event log and sends them to connected
+// streams until ctx is cancelled, then flushes the queue. Being the only
+// writer of this instance, it keeps local streams receiving events in ID
+// order, which they rely on to skip the ones already replayed.
+func (s *Service) Run(ctx context.Context) error {
+	// Events are logged even when shutting down, the changes are committed.
+	done := context.WithoutCancel(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			for len(s.queue) > 0 {
+				s.append(done, <-s.queue)
+			}
+			return ctx.Err()
+		case e := <-s.queue:
+			s.append(done, e)
+		}
+	}
+}
+
+// append logs first together with the events queued behind it.
+func (s *Service) append(ctx context.Context, first Event) {
+	evs := []Event{first}
+	for len(evs) < appendBatchSize && len(s.queue) > 0 {
+		evs = append(evs, <-s.queue)
+	}
+	stored, err := s.repo.Append(ctx, evs)
+	if err != nil {
+		s.logger.Errorf("append %d events: %v", len(evs), err)
+		return
+	}
+	for _, e := range stored {
+		s.broker.Publish(e)
+	}
+}
+
+func (s *Service) Subscribe() (<-chan Event, func()) {
+	return s.broker.Subscribe()
+}
+
+// Replay calls fn for every logged event after afterID in order.
+func (s *Service) Replay(ctx context.Context, afterID int64, fn func(e Event) error) error {
+	for {
+		evs, err := s.repo.ListAfter(ctx, afterID, replayBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, e := range evs {
+			if err = fn(e); err != nil {
+				return err
+			}
+			afterID = e.ID
+		}
+		if len(evs) < replayBatchSize {
+			return nil
+		}
+	}
+}
+
+// RunRetention deletes events older than retention every hour until ctx is
+// cancelled. Streams cannot resume from before that point.
+func (s *Service) RunRetention(ctx context.Context, retention time.Duration) error {
+	ticker := time.NewTicker(retentionPeriod)
+	defer ticker.Stop()
+	for {
+		pruneCtx, cancel := context.WithTimeout(ctx, pruneCallTimeout)
+		n, err := s.repo.DeleteBefore(pruneCtx, time.Now().Add(-retention))
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			s.logger.Errorf("prune events: %v", err)
+		} else if n > 0 {
+			s.logger.Infof("pruned %d events", n)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func NewService(r Repository, b *Broker, l logging.Logger) *Service {
+	return &Service{queue: make(chan Event, queueSize), repo: r, broker: b, logger: l}
+}
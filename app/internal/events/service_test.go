@@ -0,0 +1,35 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/modstatus"
+)
+
+func TestService_RunFlushesOnShutdown(t *testing.T) {
+	repo := &MockEventRepo{}
+	b := NewBroker(8)
+	live, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+	s := NewService(repo, b, &MockLogger{})
+	for id := 1; id <= 3; id++ {
+		s.OnFlatEvent(context.Background(), flatEvent(flat.EventCreated, id, 1, modstatus.Created))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(repo.log) != 3 {
+		t.Fatalf("logged %d events, want 3", len(repo.log))
+	}
+	for want := int64(1); want <= 3; want++ {
+		if e := <-live; e.ID != want || e.FlatID != int(want) {
+			t.Errorf("published event %d of flat %d, want %d", e.ID, e.FlatID, want)
+		}
+	}
+}
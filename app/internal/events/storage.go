@@ -0,0 +1,13 @@
+package events
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	// Append logs evs in one transaction and returns them with their IDs.
+	Append(ctx context.Context, evs []Event) ([]Event, error)
+	ListAfter(ctx context.Context, afterID int64, limit int) ([]Event, error)
+	DeleteBefore(ctx context.Context, t time.Time) (int64, error)
+}
@@ -0,0 +1,143 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/handlers"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/internal/modstatus"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/gorilla/mux"
+)
+
+const (
+	exportHousesURL = "/export/houses"
+	exportFlatsURL  = "/export/flats"
+
+	chunkWriteTimeout = time.Minute
+)
+
+var statuses = []string{
+	modstatus.Created.String(),
+	modstatus.Approved.String(),
+	modstatus.Declined.String(),
+	modstatus.OnModeration.String(),
+}
+
+type handler struct {
+	modmw middleware.Middleware
+	s     *Service
+	l     logging.Logger
+}
+
+func NewHandler(modmw middleware.Middleware, s *Service, l logging.Logger) handlers.Handler {
+	return &handler{modmw: modmw, s: s, l: l}
+}
+
+func (h *handler) Register(r *mux.Router) {
+	r.Handle(exportHousesURL, h.modmw.DoInMiddle(h.Export(KindHouses))).Methods(http.MethodGet)
+	r.Handle(exportFlatsURL, h.modmw.DoInMiddle(h.Export(KindFlats))).Methods(http.MethodGet)
+}
+
+// Export streams the table as an attachment. Errors before the first byte
+// get a JSON response; later ones abort the connection so that the client
+// does not take a truncated file for a complete one.
+func (h *handler) Export(kind Kind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqID := middleware.RequestID(r.Context())
+		format, f, err := parseQuery(r, kind)
+		if err != nil {
+			h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+			apierror.Write(w, err, reqID, http.StatusBadRequest)
+			return
+		}
+		filename := fmt.Sprintf("%s-%s.%s", kind, time.Now().UTC().Format("20060102"), format)
+		aw := &attachmentWriter{w: w, rc: http.NewResponseController(w), contentType: format.ContentType(), filename: filename}
+		err = h.s.Export(r.Context(), kind, format, f, aw)
+		if err == nil {
+			// An empty JSONL export writes nothing at all.
+			aw.start()
+			return
+		}
+		if !aw.started {
+			h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+			apierror.Write(w, err, reqID, http.StatusInternalServerError)
+			return
+		}
+		if r.Context().Err() == nil {
+			h.l.Errorf("export %s aborted req_id=%s: %v", kind, reqID, err)
+		}
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func parseQuery(r *http.Request, kind Kind) (Format, Filter, error) {
+	q := r.URL.Query()
+	format := Format(q.Get("format"))
+	switch format {
+	case "":
+		format = FormatCSV
+	case FormatCSV, FormatXLSX, FormatJSONL:
+	default:
+		return "", Filter{}, errors.New("format must be one of csv, xlsx, jsonl")
+	}
+	var f Filter
+	if kind != KindFlats {
+		return format, f, nil
+	}
+	if v := q.Get("house_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id < 1 {
+			return "", Filter{}, errors.New("invalid house id")
+		}
+		f.HouseID = id
+	}
+	if v := q.Get("status"); v != "" {
+		if !slices.Contains(statuses, v) {
+			return "", Filter{}, fmt.Errorf("invalid status %q", v)
+		}
+		f.Status = v
+	}
+	return format, f, nil
+}
+
+// attachmentWriter sends the download headers with the first byte.
+type attachmentWriter struct {
+	w           http.ResponseWriter
+	rc          *http.ResponseController
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (aw *attachmentWriter) start() {
+	if aw.started {
+		return
+	}
+	aw.started = true
+	aw.w.Header().Set("Content-Type", aw.contentType)
+	aw.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", aw.filename))
+	aw.w.WriteHeader(http.StatusOK)
+}
+
+// NextChunk gives the client chunkWriteTimeout to take the next chunk, so
+// large exports outlive the server write timeout but a stalled client
+// does not hold the cursor forever.
+func (aw *attachmentWriter) NextChunk() error {
+	err := aw.rc.SetWriteDeadline(time.Now().Add(chunkWriteTimeout))
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
+
+func (aw *attachmentWriter) Write(p []byte) (int, error) {
+	aw.start()
+	return aw.w.Write(p)
+}
@@ -0,0 +1,64 @@
+package export
+
+import (
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+)
+
+// Kind is what is exported.
+type Kind string
+
+const (
+	KindHouses Kind = "houses"
+	KindFlats  Kind = "flats"
+)
+
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatXLSX  Format = "xlsx"
+	FormatJSONL Format = "jsonl"
+)
+
+// ContentType is the media type of files in the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatJSONL:
+		return "application/x-ndjson"
+	}
+	return "application/octet-stream"
+}
+
+// Filter narrows flats down; zero values match everything. Houses are not
+// filtered.
+type Filter struct {
+	HouseID int
+	Status  string
+}
+
+// HouseRow is a house as exported, the developer is empty if unknown.
+type HouseRow struct {
+	ID          int
+	Address     string
+	Year        int
+	DeveloperID *int
+	Developer   string
+	Floors      *int
+	Material    *string
+	Latitude    *float64
+	Longitude   *float64
+	City        *string
+	District    *string
+	Parking     bool
+	Elevator    bool
+	CreatedAt   time.Time
+	UpdateAt    time.Time
+}
+
+type FlatRow = flat.FlatDTO
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Polyrom/houses_api/pkg/client/postgres"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	cursorName = "export_cursor"
+	fetchSize  = 1000
+)
+
+type repository struct {
+	client postgres.TxClient
+	logger logging.Logger
+}
+
+func (r *repository) ScanHouses(ctx context.Context, chunk func() error, fn func(h HouseRow) error) error {
+	q := `SELECT
+					h.id, h.address, h.year, h.developer_id, COALESCE(d.name, ''), h.floors, h.material, h.latitude, h.longitude,
+					h.city, h.district, h.parking, h.elevator, h.created_at, h.update_at
+				FROM
+					houses AS h LEFT JOIN developers AS d ON d.id = h.developer_id
+				ORDER BY h.id`
+	return r.scan(ctx, q, nil, chunk, func(rows pgx.Rows) error {
+		var h HouseRow
+		err := rows.Scan(&h.ID, &h.Address, &h.Year, &h.DeveloperID, &h.Developer, &h.Floors, &h.Material, &h.Latitude,
+			&h.Longitude, &h.City, &h.District, &h.Parking, &h.Elevator, &h.CreatedAt, &h.UpdateAt)
+		if err != nil {
+			return err
+		}
+		return fn(h)
+	})
+}
+
+func (r *repository) ScanFlats(ctx context.Context, f Filter, chunk func() error, fn func(f FlatRow) error) error {
+	q := `SELECT
+					id, house_id, number, price, rooms, floor, total_area, living_area, kitchen_area, balcony, layout, status
+				FROM
+					flats
+				WHERE ($1::int = 0 OR house_id = $1)
+				AND ($2::text = '' OR status = $2)
+				ORDER BY id`
+	return r.scan(ctx, q, []any{f.HouseID, f.Status}, chunk, func(rows pgx.Rows) error {
+		var fl FlatRow
+		err := rows.Scan(&fl.ID, &fl.HouseID, &fl.Number, &fl.Price, &fl.Rooms, &fl.Floor,
+			&fl.TotalArea, &fl.LivingArea, &fl.KitchenArea, &fl.Balcony, &fl.Layout, &fl.Status)
+		if err != nil {
+			return err
+		}
+		return fn(fl)
+	})
+}
+
+// scan declares a cursor for q in a read-only transaction and fetches it in
+// chunks, so only fetchSize rows are held in memory at a time.
+func (r *repository) scan(ctx context.Context, q string, args []any, chunk func() error, row func(rows pgx.Rows) error) error {
+	tx, err := r.client.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err = tx.Exec(ctx, `SET TRANSACTION READ ONLY`); err != nil {
+		return err
+	}
+	if err = postgres.Tag(ctx, tx); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `DECLARE `+cursorName+` NO SCROLL CURSOR FOR `+q, args...); err != nil {
+		return err
+	}
+	fetch := fmt.Sprintf(`FETCH FORWARD %d FROM %s`, fetchSize, cursorName)
+	for {
+		if err = chunk(); err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			return err
+		}
+		n := 0
+		for rows.Next() {
+			n++
+			if err = row(rows); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		if n < fetchSize {
+			return tx.Commit(ctx)
+		}
+	}
+}
+
+func NewRepository(c postgres.TxClient, l logging.Logger) Repository {
+	return &repository{
+		client: c,
+		logger: l,
+	}
+}
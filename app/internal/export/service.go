@@ -0,0 +1,63 @@
+package export
+
+import (
+	"context"
+	"io"
+
+	"github.com/Polyrom/houses_api/pkg/logging"
+)
+
+var (
+	houseColumns = []string{"id", "address", "year", "developer_id", "developer", "floors", "material", "latitude",
+		"longitude", "city", "district", "parking", "elevator", "created_at", "update_at"}
+	flatColumns = []string{"id", "house_id", "number", "price", "rooms", "floor",
+		"total_area", "living_area", "kitchen_area", "balcony", "layout", "status"}
+)
+
+type Service struct {
+	repo   Repository
+	logger logging.Logger
+}
+
+// chunkWriter is implemented by writers that need to know when the next
+// chunk of rows is about to be read, e.g. to extend a write deadline.
+type chunkWriter interface {
+	NextChunk() error
+}
+
+// Export writes all houses or the flats matching f to w in the given format.
+// Rows are streamed as they are read, so on error w may hold a partial file.
+func (s *Service) Export(ctx context.Context, kind Kind, format Format, f Filter, w io.Writer) error {
+	var rw rowWriter
+	var err error
+	chunk := func() error { return nil }
+	if cw, ok := w.(chunkWriter); ok {
+		chunk = cw.NextChunk
+	}
+	switch kind {
+	case KindHouses:
+		if rw, err = newRowWriter(format, w, string(kind), houseColumns); err != nil {
+			return err
+		}
+		err = s.repo.ScanHouses(ctx, chunk, func(h HouseRow) error {
+			return rw.Write([]any{h.ID, h.Address, h.Year, h.DeveloperID, h.Developer, h.Floors, h.Material, h.Latitude,
+				h.Longitude, h.City, h.District, h.Parking, h.Elevator, h.CreatedAt, h.UpdateAt})
+		})
+	default:
+		if rw, err = newRowWriter(format, w, string(KindFlats), flatColumns); err != nil {
+			return err
+		}
+		err = s.repo.ScanFlats(ctx, f, chunk, func(fl FlatRow) error {
+			return rw.Write([]any{fl.ID, fl.HouseID, fl.Number, fl.Price, fl.Rooms, fl.Floor,
+				fl.TotalArea, fl.LivingArea, fl.KitchenArea, fl.Balcony, fl.Layout, fl.Status})
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return rw.Close()
+}
+
+func NewService(r Repository, l logging.Logger) *Service {
+	return &Service{repo: r, logger: l}
+}
@@ -0,0 +1,300 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/middleware/middlewaretest"
+	"github.com/gorilla/mux"
+)
+
+type MockLogger struct{}
+
+func (ml *MockLogger) Trace(args ...interface{})                   {}
+func (ml *MockLogger) Debug(args ...interface{})                   {}
+func (ml *MockLogger) Info(args ...interface{})                    {}
+func (ml *MockLogger) Warn(args ...interface{})                    {}
+func (ml *MockLogger) Warning(args ...interface{})                 {}
+func (ml *MockLogger) Error(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   {}
+func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
+func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
+func (ml *MockLogger) Infof(format string, args ...interface{})    {}
+func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
+func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
+func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
+func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
+
+var created = time.Date(2024, 8, 1, 10, 0, 0, 0, time.UTC)
+
+type MockRepo struct {
+	flats []FlatRow
+	// failAfter makes the scan fail after that many rows, -1 never.
+	failAfter int
+}
+
+func (mr *MockRepo) ScanHouses(ctx context.Context, chunk func() error, fn func(h HouseRow) error) error {
+	if err := chunk(); err != nil {
+		return err
+	}
+	return fn(HouseRow{ID: 1, Address: `Lenina 1, "A" & <B>`, Year: 2001, Latitude: &lat, Longitude: &lon,
+		City: &city, Elevator: true, CreatedAt: created, UpdateAt: created})
+}
+
+func (mr *MockRepo) ScanFlats(ctx context.Context, f Filter, chunk func() error, fn func(f FlatRow) error) error {
+	for i, fl := range mr.flats {
+		if i == mr.failAfter {
+			return errors.New("connection lost")
+		}
+		if i%fetchSize == 0 {
+			if err := chunk(); err != nil {
+				return err
+			}
+		}
+		if (f.HouseID == 0 || fl.HouseID == f.HouseID) && (f.Status == "" || fl.Status == f.Status) {
+			if err := fn(fl); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	lat    = 55.75
+	lon    = 37.62
+	city   = "Moscow"
+	floor  = 2
+	area   = 54.5
+	layout = "studio"
+)
+
+func newMockRepo() *MockRepo {
+	return &MockRepo{
+		flats: []FlatRow{
+			{ID: 1, HouseID: 1, Number: 1, Price: 100, Rooms: 1, Floor: &floor, TotalArea: &area, Balcony: true, Layout: &layout, Status: "approved"},
+			{ID: 2, HouseID: 2, Number: 1, Price: 200, Rooms: 2, Status: "created"},
+			{ID: 3, HouseID: 1, Number: 2, Price: 300, Rooms: 3, Status: "on moderation"},
+		},
+		failAfter: -1,
+	}
+}
+
+func TestExport_CSV(t *testing.T) {
+	s := NewService(newMockRepo(), &MockLogger{})
+	var buf bytes.Buffer
+	if err := s.Export(context.Background(), KindFlats, FormatCSV, Filter{HouseID: 1}, &buf); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	want := "id,house_id,number,price,rooms,floor,total_area,living_area,kitchen_area,balcony,layout,status\n" +
+		"1,1,1,100,1,2,54.5,,,true,studio,approved\n" +
+		"3,1,2,300,3,,,,,false,,on moderation\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestExport_JSONL(t *testing.T) {
+	s := NewService(newMockRepo(), &MockLogger{})
+	var buf bytes.Buffer
+	if err := s.Export(context.Background(), KindHouses, FormatJSONL, Filter{}, &buf); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	want := `{"id":1,"address":"Lenina 1, \"A\" \u0026 \u003cB\u003e","year":2001,"developer_id":null,"developer":"","floors":null,"material":null,` +
+		`"latitude":55.75,"longitude":37.62,"city":"Moscow","district":null,"parking":false,"elevator":true,` +
+		`"created_at":"2024-08-01T10:00:00Z","update_at":"2024-08-01T10:00:00Z"}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+// chunkBuffer counts the chunks announced to it and fails the one after last.
+type chunkBuffer struct {
+	bytes.Buffer
+	chunks int
+	last   int
+}
+
+func (cb *chunkBuffer) NextChunk() error {
+	cb.chunks++
+	if cb.chunks > cb.last {
+		return errors.New("write deadline exceeded")
+	}
+	return nil
+}
+
+func TestExport_NextChunk(t *testing.T) {
+	repo := newMockRepo()
+	repo.flats = append(repo.flats, make([]FlatRow, 2*fetchSize)...)
+	s := NewService(repo, &MockLogger{})
+	cb := &chunkBuffer{last: 3}
+	if err := s.Export(context.Background(), KindFlats, FormatCSV, Filter{}, cb); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if cb.chunks != 3 {
+		t.Errorf("got %d chunks, want 3", cb.chunks)
+	}
+	cb = &chunkBuffer{last: 1}
+	if err := s.Export(context.Background(), KindFlats, FormatCSV, Filter{}, cb); err == nil {
+		t.Errorf("Export ignored a failed chunk")
+	}
+}
+
+// readSheet returns the cell texts of the only sheet of an XLSX file, values
+// of cells that are not inline strings are prefixed with "=".
+func readSheet(t *testing.T, b []byte) [][]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatalf("open xlsx: %v", err)
+	}
+	names := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		names[f.Name] = f
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		if names[name] == nil {
+			t.Errorf("xlsx has no %s", name)
+		}
+	}
+	sheet, err := names["xl/worksheets/sheet1.xml"].Open()
+	if err != nil {
+		t.Fatalf("open sheet: %v", err)
+	}
+	defer sheet.Close()
+	var ws struct {
+		Rows []struct {
+			Cells []struct {
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err = xml.NewDecoder(sheet).Decode(&ws); err != nil {
+		t.Fatalf("decode sheet: %v", err)
+	}
+	rows := make([][]string, 0, len(ws.Rows))
+	for _, r := range ws.Rows {
+		row := make([]string, 0, len(r.Cells))
+		for _, c := range r.Cells {
+			if c.Type == "inlineStr" {
+				row = append(row, c.Inline)
+			} else {
+				row = append(row, "="+c.Value)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestExport_XLSX(t *testing.T) {
+	s := NewService(newMockRepo(), &MockLogger{})
+	var buf bytes.Buffer
+	if err := s.Export(context.Background(), KindHouses, FormatXLSX, Filter{}, &buf); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	rows := readSheet(t, buf.Bytes())
+	want := [][]string{
+		houseColumns,
+		{"=1", `Lenina 1, "A" & <B>`, "=2001", "=", "", "=", "=", "=55.75", "=37.62", "Moscow", "=", "false", "true",
+			"2024-08-01T10:00:00Z", "2024-08-01T10:00:00Z"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %v", len(rows), len(want), rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestExport_XLSXOptionalColumns(t *testing.T) {
+	s := NewService(newMockRepo(), &MockLogger{})
+	var buf bytes.Buffer
+	if err := s.Export(context.Background(), KindFlats, FormatXLSX, Filter{HouseID: 1}, &buf); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	rows := readSheet(t, buf.Bytes())
+	want := []string{
+		"=1|=1|=1|=100|=1|=2|=54.5|=|=|true|studio|approved",
+		"=3|=1|=2|=300|=3|=|=|=|=|false|=|on moderation",
+	}
+	if len(rows) != len(want)+1 {
+		t.Fatalf("got %d rows, want %d: %v", len(rows), len(want)+1, rows)
+	}
+	for i := range want {
+		if got := strings.Join(rows[i+1], "|"); got != want[i] {
+			t.Errorf("row %d = %q, want %q", i+1, got, want[i])
+		}
+	}
+}
+
+func TestHandler_Export(t *testing.T) {
+	tests := []struct {
+		name            string
+		url             string
+		failAfter       int
+		wantCode        int
+		wantContentType string
+		wantBody        string
+	}{
+		{name: "csv by default", url: "/export/flats?status=approved", failAfter: -1, wantCode: http.StatusOK, wantContentType: "text/csv; charset=utf-8", wantBody: "id,house_id,number,price,rooms,floor,total_area,living_area,kitchen_area,balcony,layout,status\n1,1,1,100,1,2,54.5,,,true,studio,approved\n"},
+		{name: "empty jsonl", url: "/export/flats?format=jsonl&house_id=9", failAfter: -1, wantCode: http.StatusOK, wantContentType: "application/x-ndjson"},
+		{name: "unknown format", url: "/export/flats?format=pdf", failAfter: -1, wantCode: http.StatusBadRequest},
+		{name: "invalid status", url: "/export/flats?status=sold", failAfter: -1, wantCode: http.StatusBadRequest},
+		{name: "error before the first byte", url: "/export/flats?format=jsonl", failAfter: 0, wantCode: http.StatusInternalServerError, wantContentType: "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepo()
+			repo.failAfter = tt.failAfter
+			router := mux.NewRouter()
+			NewHandler(middlewaretest.Auth{}, NewService(repo, &MockLogger{}), &MockLogger{}).Register(router)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if rr.Code != tt.wantCode {
+				t.Fatalf("code = %d, want %d: %s", rr.Code, tt.wantCode, rr.Body)
+			}
+			if tt.wantContentType != "" && rr.Header().Get("Content-Type") != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", rr.Header().Get("Content-Type"), tt.wantContentType)
+			}
+			if tt.wantBody != "" && rr.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rr.Body, tt.wantBody)
+			}
+			if rr.Code == http.StatusOK && !strings.HasPrefix(rr.Header().Get("Content-Disposition"), `attachment; filename="flats-`) {
+				t.Errorf("Content-Disposition = %q", rr.Header().Get("Content-Disposition"))
+			}
+		})
+	}
+}
+
+func TestHandler_ExportAbortsTruncatedFile(t *testing.T) {
+	repo := newMockRepo()
+	repo.flats = append(repo.flats, make([]FlatRow, 10000)...)
+	repo.failAfter = len(repo.flats) - 1
+	router := mux.NewRouter()
+	NewHandler(middlewaretest.Auth{}, NewService(repo, &MockLogger{}), &MockLogger{}).Register(router)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/export/flats")
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+	if _, err = io.ReadAll(resp.Body); err == nil {
+		t.Errorf("truncated export was read without an error")
+	}
+}
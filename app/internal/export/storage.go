@@ -0,0 +1,11 @@
+package export
+
+import "context"
+
+// Repository reads whole tables through a server-side cursor, calling chunk
+// before every fetch and fn for every row in ID order. An error from either
+// stops the scan and is returned.
+type Repository interface {
+	ScanHouses(ctx context.Context, chunk func() error, fn func(h HouseRow) error) error
+	ScanFlats(ctx context.Context, f Filter, chunk func() error, fn func(f FlatRow) error) error
+}
@@ -0,0 +1,99 @@
+package favorite
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/handlers"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/gorilla/mux"
+)
+
+const (
+	favoriteURL  = "/flat/{id}/favorite"
+	favoritesURL = "/my/favorites"
+)
+
+type handler struct {
+	aumw middleware.Middleware
+	s    *Service
+	l    logging.Logger
+}
+
+func NewHandler(aumw middleware.Middleware, s *Service, l logging.Logger) handlers.Handler {
+	return &handler{aumw: aumw, s: s, l: l}
+}
+
+func (h *handler) Register(r *mux.Router) {
+	r.Handle(favoriteURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Add))).Methods(http.MethodPost)
+	r.Handle(favoriteURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Remove))).Methods(http.MethodDelete)
+	r.Handle(favoritesURL, h.aumw.DoInMiddle(http.HandlerFunc(h.List))).Methods(http.MethodGet)
+}
+
+func (h *handler) Add(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.flatID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.s.Add(r.Context(), id); err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) Remove(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.flatID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.s.Remove(r.Context(), id); err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) List(w http.ResponseWriter, r *http.Request) {
+	favs, err := h.s.List(r.Context())
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(favs); err != nil {
+		h.l.Errorf("internal error req_id=%s: %v", middleware.RequestID(r.Context()), err)
+	}
+}
+
+func (h *handler) flatID(w http.ResponseWriter, r *http.Request) (flat.FlatID, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id < 1 {
+		reqID := middleware.RequestID(r.Context())
+		invalidIDErr := errors.New("invalid flat id")
+		h.l.Errorf("bad request req_id=%s: %v", reqID, invalidIDErr)
+		apierror.Write(w, invalidIDErr, reqID, http.StatusBadRequest)
+		return 0, false
+	}
+	return flat.FlatID(id), true
+}
+
+func (h *handler) fail(w http.ResponseWriter, r *http.Request, err error) {
+	reqID := middleware.RequestID(r.Context())
+	switch {
+	case errors.Is(err, ErrNotFound):
+		h.l.Errorf("not found req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusNotFound)
+	case errors.Is(err, ErrNotAuthenticated):
+		h.l.Errorf("unauthorized req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusUnauthorized)
+	default:
+		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusInternalServerError)
+	}
+}
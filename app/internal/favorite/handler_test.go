@@ -0,0 +1,156 @@
+package favorite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/internal/middleware/middlewaretest"
+	"github.com/Polyrom/houses_api/internal/modstatus"
+	"github.com/gorilla/mux"
+)
+
+type MockLogger struct{}
+
+func (ml *MockLogger) Trace(args ...interface{})                   {}
+func (ml *MockLogger) Debug(args ...interface{})                   {}
+func (ml *MockLogger) Info(args ...interface{})                    {}
+func (ml *MockLogger) Warn(args ...interface{})                    {}
+func (ml *MockLogger) Warning(args ...interface{})                 {}
+func (ml *MockLogger) Error(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   {}
+func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
+func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
+func (ml *MockLogger) Infof(format string, args ...interface{})    {}
+func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
+func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
+func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
+func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
+
+// MockFavoriteRepo keeps the flat statuses and the favorites of one user in
+// memory and lists them like the SQL does, hiding flats that are not
+// approved.
+type MockFavoriteRepo struct {
+	statuses  map[flat.FlatID]string
+	favorites []flat.FlatID
+}
+
+func (mr *MockFavoriteRepo) FlatStatus(ctx context.Context, id flat.FlatID) (string, error) {
+	status, ok := mr.statuses[id]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return status, nil
+}
+
+func (mr *MockFavoriteRepo) Add(ctx context.Context, uid string, id flat.FlatID) error {
+	for _, fid := range mr.favorites {
+		if fid == id {
+			return nil
+		}
+	}
+	mr.favorites = append(mr.favorites, id)
+	return nil
+}
+
+func (mr *MockFavoriteRepo) Remove(ctx context.Context, uid string, id flat.FlatID) error {
+	for i, fid := range mr.favorites {
+		if fid == id {
+			mr.favorites = append(mr.favorites[:i], mr.favorites[i+1:]...)
+		}
+	}
+	return nil
+}
+
+func (mr *MockFavoriteRepo) List(ctx context.Context, uid string) ([]Favorite, error) {
+	favs := make([]Favorite, 0)
+	for _, id := range mr.favorites {
+		if mr.statuses[id] == modstatus.Approved.String() {
+			favs = append(favs, Favorite{FlatDTO: flat.FlatDTO{ID: int(id), Status: mr.statuses[id]}})
+		}
+	}
+	return favs, nil
+}
+
+func (mr *MockFavoriteRepo) FlatIDs(ctx context.Context, uid string) ([]flat.FlatID, error) {
+	return mr.favorites, nil
+}
+
+func TestHandler(t *testing.T) {
+	repo := &MockFavoriteRepo{statuses: map[flat.FlatID]string{
+		1: modstatus.Approved.String(),
+		2: modstatus.Approved.String(),
+		3: modstatus.OnModeration.String(),
+	}}
+	router := mux.NewRouter()
+	NewHandler(middlewaretest.Auth{Role: middleware.Client}, NewService(repo, &MockLogger{}), &MockLogger{}).Register(router)
+	do := func(method, url, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, nil)
+		req.Header.Set("X-User", user)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	list := func() []int {
+		t.Helper()
+		rr := do(http.MethodGet, "/my/favorites", "user-1")
+		var favs []Favorite
+		if err := json.Unmarshal(rr.Body.Bytes(), &favs); rr.Code != http.StatusOK || err != nil {
+			t.Fatalf("list: %d %s", rr.Code, rr.Body)
+		}
+		ids := make([]int, 0, len(favs))
+		for _, f := range favs {
+			ids = append(ids, f.ID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		user   string
+		code   int
+	}{
+		{name: "add", method: http.MethodPost, url: "/flat/1/favorite", user: "user-1", code: http.StatusNoContent},
+		{name: "add again", method: http.MethodPost, url: "/flat/1/favorite", user: "user-1", code: http.StatusNoContent},
+		{name: "add another", method: http.MethodPost, url: "/flat/2/favorite", user: "user-1", code: http.StatusNoContent},
+		{name: "add not approved", method: http.MethodPost, url: "/flat/3/favorite", user: "user-1", code: http.StatusNotFound},
+		{name: "add missing", method: http.MethodPost, url: "/flat/9/favorite", user: "user-1", code: http.StatusNotFound},
+		{name: "add invalid id", method: http.MethodPost, url: "/flat/x/favorite", user: "user-1", code: http.StatusBadRequest},
+		{name: "add anonymous", method: http.MethodPost, url: "/flat/1/favorite", code: http.StatusUnauthorized},
+		{name: "remove not added", method: http.MethodDelete, url: "/flat/3/favorite", user: "user-1", code: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rr := do(tt.method, tt.url, tt.user); rr.Code != tt.code {
+				t.Errorf("code = %d, want %d: %s", rr.Code, tt.code, rr.Body)
+			}
+		})
+	}
+	if got := list(); len(got) != 2 {
+		t.Fatalf("favorites = %v, want flats 1 and 2", got)
+	}
+
+	// a declined flat is hidden and comes back once approved again
+	repo.statuses[2] = modstatus.Declined.String()
+	if got := list(); len(got) != 1 || got[0] != 1 {
+		t.Errorf("favorites after decline = %v, want [1]", got)
+	}
+	repo.statuses[2] = modstatus.Approved.String()
+	if got := list(); len(got) != 2 {
+		t.Errorf("favorites after approval = %v, want flats 1 and 2", got)
+	}
+
+	if rr := do(http.MethodDelete, "/flat/1/favorite", "user-1"); rr.Code != http.StatusNoContent {
+		t.Fatalf("remove: %d %s", rr.Code, rr.Body)
+	}
+	if got := list(); len(got) != 1 || got[0] != 2 {
+		t.Errorf("favorites after removal = %v, want [2]", got)
+	}
+}
@@ -0,0 +1,15 @@
+package favorite
+
+import (
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/house"
+)
+
+// Favorite is a bookmarked flat as it is now, with its house.
+type Favorite struct {
+	flat.FlatDTO
+	House   house.House `json:"house"`
+	AddedAt time.Time   `json:"added_at"`
+}
@@ -0,0 +1,103 @@
+package favorite
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/house"
+	"github.com/Polyrom/houses_api/pkg/client/postgres"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/jackc/pgx/v5"
+)
+
+type repository struct {
+	client postgres.Client
+	logger logging.Logger
+}
+
+// scanFunc turns a function into a pgx.Row, so that flat.Scan can read
+// the columns house.Scan asks for after its own.
+type scanFunc func(dest ...any) error
+
+func (f scanFunc) Scan(dest ...any) error { return f(dest...) }
+
+func (r *repository) FlatStatus(ctx context.Context, id flat.FlatID) (string, error) {
+	q := `SELECT status FROM flats WHERE id = $1`
+	var status string
+	err := r.client.QueryRow(ctx, q, id).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return status, err
+}
+
+func (r *repository) Add(ctx context.Context, userID string, id flat.FlatID) error {
+	q := `INSERT INTO favorites 
+					(user_id, flat_id) 
+				VALUES 
+					($1, $2)
+				ON CONFLICT DO NOTHING`
+	_, err := r.client.Exec(ctx, q, userID, id)
+	return err
+}
+
+func (r *repository) Remove(ctx context.Context, userID string, id flat.FlatID) error {
+	q := `DELETE FROM favorites WHERE user_id = $1 AND flat_id = $2`
+	_, err := r.client.Exec(ctx, q, userID, id)
+	return err
+}
+
+func (r *repository) List(ctx context.Context, userID string) ([]Favorite, error) {
+	q := `SELECT 
+					` + flat.Columns + `, fv.created_at, ` + house.Columns + ` 
+				FROM 
+					` + house.FromHouses + `
+					JOIN flats AS f ON f.house_id = h.id
+					JOIN favorites AS fv ON fv.flat_id = f.id
+				WHERE fv.user_id = $1
+				AND f.status = 'approved'
+				ORDER BY fv.created_at DESC, f.id`
+	rows, err := r.client.Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	favs := make([]Favorite, 0)
+	for rows.Next() {
+		var fav Favorite
+		err = house.Scan(scanFunc(func(houseDest ...any) error {
+			return flat.Scan(rows, &fav.FlatDTO, append([]any{&fav.AddedAt}, houseDest...)...)
+		}), &fav.House)
+		if err != nil {
+			return nil, err
+		}
+		favs = append(favs, fav)
+	}
+	return favs, rows.Err()
+}
+
+func (r *repository) FlatIDs(ctx context.Context, userID string) ([]flat.FlatID, error) {
+	q := `SELECT flat_id FROM favorites WHERE user_id = $1`
+	rows, err := r.client.Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make([]flat.FlatID, 0)
+	for rows.Next() {
+		var id flat.FlatID
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func NewRepository(c postgres.Client, l logging.Logger) Repository {
+	return &repository{
+		client: c,
+		logger: l,
+	}
+}
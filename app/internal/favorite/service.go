@@ -0,0 +1,67 @@
+package favorite
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/internal/modstatus"
+	"github.com/Polyrom/houses_api/pkg/logging"
+)
+
+var (
+	ErrNotFound         = errors.New("flat not found")
+	ErrNotAuthenticated = errors.New("user not authenticated")
+)
+
+// Service keeps the favorite flats of users. Favorites of flats that leave
+// approved are kept and hidden until the flat is approved again.
+type Service struct {
+	repo   Repository
+	logger logging.Logger
+}
+
+// Add bookmarks an approved flat for the current user.
+func (s *Service) Add(ctx context.Context, id flat.FlatID) error {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		return ErrNotAuthenticated
+	}
+	status, err := s.repo.FlatStatus(ctx, id)
+	if err != nil {
+		return err
+	}
+	if status != modstatus.Approved.String() {
+		return ErrNotFound
+	}
+	return s.repo.Add(ctx, userID, id)
+}
+
+// Remove drops a flat from the favorites of the current user, removing a
+// flat that is not there succeeds.
+func (s *Service) Remove(ctx context.Context, id flat.FlatID) error {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		return ErrNotAuthenticated
+	}
+	return s.repo.Remove(ctx, userID, id)
+}
+
+func (s *Service) List(ctx context.Context) ([]Favorite, error) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		return nil, ErrNotAuthenticated
+	}
+	return s.repo.List(ctx, userID)
+}
+
+// FavoriteFlatIDs returns the flats bookmarked by the user, including the
+// hidden ones.
+func (s *Service) FavoriteFlatIDs(ctx context.Context, userID string) ([]flat.FlatID, error) {
+	return s.repo.FlatIDs(ctx, userID)
+}
+
+func NewService(r Repository, l logging.Logger) *Service {
+	return &Service{repo: r, logger: l}
+}
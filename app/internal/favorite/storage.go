@@ -0,0 +1,19 @@
+package favorite
+
+import (
+	"context"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+)
+
+type Repository interface {
+	// FlatStatus returns the moderation status of the flat.
+	FlatStatus(ctx context.Context, id flat.FlatID) (string, error)
+	// Add bookmarks the flat, adding it again keeps the first date.
+	Add(ctx context.Context, userID string, id flat.FlatID) error
+	Remove(ctx context.Context, userID string, id flat.FlatID) error
+	// List returns the approved flats bookmarked by the user, latest first.
+	List(ctx context.Context, userID string) ([]Favorite, error)
+	// FlatIDs returns every flat bookmarked by the user, approved or not.
+	FlatIDs(ctx context.Context, userID string) ([]flat.FlatID, error)
+}
@@ -1,29 +1,128 @@
 package flat
 
+import (
+	"math"
+
+	"github.com/Polyrom/houses_api/internal/modstatus"
+	"github.com/go-playground/validator/v10"
+)
+
 type FlatID int
 
 type FlatDTO struct {
-	ID        int    `json:"id" validate:"required"`
-	HouseID   int    `json:"house_id" validate:"required"`
-	Price     int    `json:"price" validate:"required"`
-	Rooms     int    `json:"rooms" validate:"required"`
-	Moderator string `json:"-"`
-	Status    string `json:"status" validate:"required"`
+	ID          int      `json:"id" validate:"required"`
+	HouseID     int      `json:"house_id" validate:"required"`
+	Number      int      `json:"number"`
+	Price       int      `json:"price" validate:"required"`
+	Rooms       int      `json:"rooms" validate:"required"`
+	Floor       *int     `json:"floor,omitempty"`
+	TotalArea   *float64 `json:"total_area,omitempty"`
+	LivingArea  *float64 `json:"living_area,omitempty"`
+	KitchenArea *float64 `json:"kitchen_area,omitempty"`
+	Balcony     bool     `json:"balcony"`
+	Layout      *string  `json:"layout,omitempty"`
+	// PrevPrice is the price before the last change, PriceDropped and
+	// PriceDropPercent tell whether and how much that change lowered it.
+	PrevPrice        *int    `json:"prev_price,omitempty"`
+	PriceDropped     bool    `json:"price_dropped"`
+	PriceDropPercent float64 `json:"price_drop_percent,omitempty"`
+	Moderator        string  `json:"-"`
+	OwnerID          string  `json:"-"`
+	Status           string  `json:"status" validate:"required"`
+}
+
+// setPriceDrop fills in whether the last price change lowered the price and
+// by how many percent, rounded to one decimal.
+func (f *FlatDTO) setPriceDrop() {
+	f.PriceDropped, f.PriceDropPercent = false, 0
+	if f.PrevPrice != nil && *f.PrevPrice > f.Price {
+		f.PriceDropped = true
+		f.PriceDropPercent = math.Round(float64(*f.PrevPrice-f.Price)/float64(*f.PrevPrice)*1000) / 10
+	}
 }
 
+// UpdateFlatStatusDTO identifies the flat either by id or by its number in
+// the house.
 type UpdateFlatStatusDTO struct {
-	ID      int    `json:"id" validate:"required"`
+	ID      int    `json:"id,omitempty" validate:"required_without=Number"`
 	HouseID int    `json:"house_id" validate:"required"`
+	Number  int    `json:"number,omitempty" validate:"omitempty,min=1"`
 	Status  string `json:"status" validate:"required,oneof_modstat"`
 }
 
+type UpdateFlatPriceDTO struct {
+	Price int `json:"price" validate:"required,min=1"`
+}
+
+// CreateFlatDTO describes a new flat. Without a number the flat gets the
+// next free one in its house. OwnerID is the user creating the flat, it is
+// not read from requests.
 type CreateFlatDTO struct {
-	HouseID int `json:"house_id" validate:"required"`
-	Price   int `json:"price" validate:"required,min=1"`
-	Rooms   int `json:"rooms" validate:"required,min=0"`
+	HouseID     int      `json:"house_id" validate:"required"`
+	Number      int      `json:"number,omitempty" validate:"omitempty,min=1"`
+	Price       int      `json:"price" validate:"required,min=1"`
+	Rooms       int      `json:"rooms" validate:"required,min=0"`
+	Floor       *int     `json:"floor,omitempty" validate:"omitempty,min=-5,max=300"`
+	TotalArea   *float64 `json:"total_area,omitempty" validate:"omitempty,gt=0,max=10000"`
+	LivingArea  *float64 `json:"living_area,omitempty" validate:"omitempty,gt=0,max=10000"`
+	KitchenArea *float64 `json:"kitchen_area,omitempty" validate:"omitempty,gt=0,max=10000"`
+	Balcony     bool     `json:"balcony,omitempty"`
+	Layout      *string  `json:"layout,omitempty" validate:"omitempty,oneof_layout"`
+	OwnerID     string   `json:"-"`
+}
+
+// Filter narrows a listing of flats, zero values match everything.
+type Filter struct {
+	Rooms    int
+	PriceMin int
+	PriceMax int
+	FloorMin *int
+	FloorMax *int
+	AreaMin  float64
+	AreaMax  float64
+	Balcony  *bool
+	Layout   string
 }
 
 type GetFlatByIDDTO struct {
 	ID      int `json:"id" validate:"required"`
 	HouseID int `json:"house_id" validate:"required"`
 }
+
+// NewValidator returns a validator for the DTOs above that knows the
+// oneof_modstat rule, i.e. the statuses a moderator may set, the
+// oneof_layout rule and that rooms of a flat fit into its total area.
+func NewValidator() *validator.Validate {
+	validStatuses := []string{modstatus.Approved.String(), modstatus.Declined.String(), modstatus.OnModeration.String()}
+	validate := validator.New()
+	validate.RegisterValidation("oneof_modstat", func(fl validator.FieldLevel) bool {
+		for _, allowed := range validStatuses {
+			if fl.Field().String() == allowed {
+				return true
+			}
+		}
+		return false
+	})
+	validate.RegisterValidation("oneof_layout", func(fl validator.FieldLevel) bool {
+		return IsLayout(fl.Field().String())
+	})
+	validate.RegisterStructValidation(validateAreas, CreateFlatDTO{})
+	return validate
+}
+
+func validateAreas(sl validator.StructLevel) {
+	f := sl.Current().Interface().(CreateFlatDTO)
+	if f.TotalArea == nil {
+		return
+	}
+	var rooms float64
+	if f.LivingArea != nil {
+		rooms += *f.LivingArea
+	}
+	if f.KitchenArea != nil {
+		rooms += *f.KitchenArea
+	}
+	if rooms > *f.TotalArea {
+		sl.ReportError(f.LivingArea, "living_area", "LivingArea", "fits_total_area", "")
+	}
+}
@@ -3,24 +3,33 @@ package flat
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/Polyrom/houses_api/internal/apierror"
 	"github.com/Polyrom/houses_api/internal/handlers"
 	"github.com/Polyrom/houses_api/internal/middleware"
-	"github.com/Polyrom/houses_api/internal/modstatus"
 	"github.com/Polyrom/houses_api/pkg/logging"
-	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 )
 
 const (
-	createURL   = "/flat/create"
-	updateURL   = "/flat/update"
-	findByIDURL = "/house/{id}"
+	createURL         = "/flat/create"
+	updateURL         = "/flat/update"
+	findByHouseIDURL  = "/house/{id}/flats"
+	legacyFindByIDURL = "/house/{id}"
+	priceURL          = "/flat/{id}/price"
+	priceHistoryURL   = "/flat/{id}/price-history"
 )
 
+// LegacyURLs maps the legacy routes that moved under /api/v1 to their new
+// path.
+func LegacyURLs() map[string]string {
+	return map[string]string{legacyFindByIDURL: findByHouseIDURL}
+}
+
 type handler struct {
 	aumw  middleware.Middleware
 	modmw middleware.Middleware
@@ -35,19 +44,29 @@ func NewHandler(aumw middleware.Middleware, modmw middleware.Middleware, s *Serv
 func (h *handler) Register(r *mux.Router) {
 	r.Handle(createURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Create))).Methods(http.MethodPost)
 	r.Handle(updateURL, h.modmw.DoInMiddle(http.HandlerFunc(h.Update))).Methods(http.MethodPost)
-	r.Handle(findByIDURL, h.aumw.DoInMiddle(http.HandlerFunc(h.FindByID))).Methods(http.MethodGet)
+	r.Handle(findByHouseIDURL, h.aumw.DoInMiddle(http.HandlerFunc(h.FindByID))).Methods(http.MethodGet)
+	r.Handle(priceURL, h.aumw.DoInMiddle(http.HandlerFunc(h.UpdatePrice))).Methods(http.MethodPut)
+	r.Handle(priceHistoryURL, h.aumw.DoInMiddle(http.HandlerFunc(h.PriceHistory))).Methods(http.MethodGet)
+}
+
+// RegisterDeprecated mounts the routes that existed before /api/v1, price
+// routes are not among them.
+func (h *handler) RegisterDeprecated(r *mux.Router) {
+	r.Handle(createURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Create))).Methods(http.MethodPost)
+	r.Handle(updateURL, h.modmw.DoInMiddle(http.HandlerFunc(h.Update))).Methods(http.MethodPost)
+	r.Handle(legacyFindByIDURL, h.aumw.DoInMiddle(http.HandlerFunc(h.FindByID))).Methods(http.MethodGet)
 }
 
 func (h *handler) Create(w http.ResponseWriter, r *http.Request) {
-	reqID := r.Context().Value(middleware.ContextKeyRequestID).(string)
+	reqID := middleware.RequestID(r.Context())
 	var fdto CreateFlatDTO
-	err := json.NewDecoder(r.Body).Decode(&fdto)
+	code, err := handlers.DecodeJSON(r, &fdto)
 	if err != nil {
 		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
-		apierror.Write(w, err, reqID, http.StatusBadRequest)
+		apierror.Write(w, err, reqID, code)
 		return
 	}
-	validate := validator.New()
+	validate := NewValidator()
 	err = validate.Struct(fdto)
 	if err != nil {
 		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
@@ -55,6 +74,11 @@ func (h *handler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	newFlat, err := h.s.Create(r.Context(), fdto)
+	if errors.Is(err, ErrNumberTaken) {
+		h.l.Errorf("conflict req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusConflict)
+		return
+	}
 	if err != nil {
 		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
 		apierror.Write(w, err, reqID, http.StatusInternalServerError)
@@ -70,24 +94,15 @@ func (h *handler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *handler) Update(w http.ResponseWriter, r *http.Request) {
-	reqID := r.Context().Value(middleware.ContextKeyRequestID).(string)
+	reqID := middleware.RequestID(r.Context())
 	var ufsdto UpdateFlatStatusDTO
-	err := json.NewDecoder(r.Body).Decode(&ufsdto)
+	code, err := handlers.DecodeJSON(r, &ufsdto)
 	if err != nil {
 		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
-		apierror.Write(w, err, reqID, http.StatusBadRequest)
+		apierror.Write(w, err, reqID, code)
 		return
 	}
-	validStatuses := []string{modstatus.Approved.String(), modstatus.Declined.String(), modstatus.OnModeration.String()}
-	validate := validator.New()
-	validate.RegisterValidation("oneof_modstat", func(fl validator.FieldLevel) bool {
-		for _, allowed := range validStatuses {
-			if fl.Field().String() == allowed {
-				return true
-			}
-		}
-		return false
-	})
+	validate := NewValidator()
 	err = validate.Struct(ufsdto)
 	if err != nil {
 		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
@@ -110,7 +125,7 @@ func (h *handler) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *handler) FindByID(w http.ResponseWriter, r *http.Request) {
-	reqID := r.Context().Value(middleware.ContextKeyRequestID).(string)
+	reqID := middleware.RequestID(r.Context())
 	var fid FlatID
 	vars := mux.Vars(r)
 	fidParam, ok := vars["id"]
@@ -128,7 +143,13 @@ func (h *handler) FindByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	fid = FlatID(fidParamConv)
-	flatsFound, err := h.s.GetByHouseID(r.Context(), fid)
+	filter, err := parseFilter(r.URL.Query())
+	if err != nil {
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusBadRequest)
+		return
+	}
+	flatsFound, err := h.s.GetByHouseID(r.Context(), fid, filter)
 	if err != nil {
 		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
 		apierror.Write(w, err, reqID, http.StatusInternalServerError)
@@ -142,3 +163,139 @@ func (h *handler) FindByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// UpdatePrice is allowed to moderators and the owner of the flat, the
+// service checks which one the user is.
+func (h *handler) UpdatePrice(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestID(r.Context())
+	id, ok := h.flatID(w, r)
+	if !ok {
+		return
+	}
+	var pdto UpdateFlatPriceDTO
+	code, err := handlers.DecodeJSON(r, &pdto)
+	if err != nil {
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, code)
+		return
+	}
+	if err = NewValidator().Struct(pdto); err != nil {
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusBadRequest)
+		return
+	}
+	updatedFlat, err := h.s.UpdatePrice(r.Context(), id, pdto)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.respond(w, r, updatedFlat)
+}
+
+func (h *handler) PriceHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.flatID(w, r)
+	if !ok {
+		return
+	}
+	changes, err := h.s.PriceHistory(r.Context(), id)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.respond(w, r, changes)
+}
+
+func (h *handler) flatID(w http.ResponseWriter, r *http.Request) (FlatID, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id < 1 {
+		reqID := middleware.RequestID(r.Context())
+		invalidIDErr := errors.New("invalid flat id")
+		h.l.Errorf("bad request req_id=%s: %v", reqID, invalidIDErr)
+		apierror.Write(w, invalidIDErr, reqID, http.StatusBadRequest)
+		return 0, false
+	}
+	return FlatID(id), true
+}
+
+func (h *handler) fail(w http.ResponseWriter, r *http.Request, err error) {
+	reqID := middleware.RequestID(r.Context())
+	switch {
+	case errors.Is(err, ErrNotFound):
+		h.l.Errorf("not found req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusNotFound)
+	case errors.Is(err, ErrForbidden):
+		h.l.Errorf("forbidden req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusForbidden)
+	case errors.Is(err, ErrNotAuthenticated):
+		h.l.Errorf("unauthorized req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusUnauthorized)
+	default:
+		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusInternalServerError)
+	}
+}
+
+func (h *handler) respond(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.l.Errorf("internal error req_id=%s: %v", middleware.RequestID(r.Context()), err)
+	}
+}
+
+// parseFilter reads the listing filters from the query string.
+func parseFilter(q url.Values) (Filter, error) {
+	var f Filter
+	ints := []struct {
+		name string
+		dst  *int
+	}{{"rooms", &f.Rooms}, {"price_min", &f.PriceMin}, {"price_max", &f.PriceMax}}
+	for _, p := range ints {
+		if v := q.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return Filter{}, fmt.Errorf("invalid %s", p.name)
+			}
+			*p.dst = n
+		}
+	}
+	floors := []struct {
+		name string
+		dst  **int
+	}{{"floor_min", &f.FloorMin}, {"floor_max", &f.FloorMax}}
+	for _, p := range floors {
+		if v := q.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return Filter{}, fmt.Errorf("invalid %s", p.name)
+			}
+			*p.dst = &n
+		}
+	}
+	areas := []struct {
+		name string
+		dst  *float64
+	}{{"area_min", &f.AreaMin}, {"area_max", &f.AreaMax}}
+	for _, p := range areas {
+		if v := q.Get(p.name); v != "" {
+			a, err := strconv.ParseFloat(v, 64)
+			if err != nil || a < 0 {
+				return Filter{}, fmt.Errorf("invalid %s", p.name)
+			}
+			*p.dst = a
+		}
+	}
+	if v := q.Get("balcony"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return Filter{}, errors.New("invalid balcony")
+		}
+		f.Balcony = &b
+	}
+	if v := q.Get("layout"); v != "" {
+		if !IsLayout(v) {
+			return Filter{}, errors.New("invalid layout")
+		}
+		f.Layout = v
+	}
+	return f, nil
+}
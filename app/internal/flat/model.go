@@ -1,6 +1,10 @@
 package flat
 
-import "github.com/Polyrom/houses_api/internal/house"
+import (
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/house"
+)
 
 type Flat struct {
 	ID     int         `json:"id"`
@@ -9,3 +13,31 @@ type Flat struct {
 	Rooms  int         `json:"rooms"`
 	Status string      `json:"status"`
 }
+
+// PriceChange is an entry of the price history of a flat. ChangedBy is
+// shown to moderators only and is empty once the user is deleted.
+type PriceChange struct {
+	OldPrice  int       `json:"old_price"`
+	Price     int       `json:"price"`
+	ChangedBy *string   `json:"changed_by,omitempty"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// Layouts of a flat.
+const (
+	LayoutStudio   = "studio"
+	LayoutIsolated = "isolated"
+	LayoutAdjacent = "adjacent"
+	LayoutOpenPlan = "open_plan"
+)
+
+var Layouts = []string{LayoutStudio, LayoutIsolated, LayoutAdjacent, LayoutOpenPlan}
+
+func IsLayout(s string) bool {
+	for _, l := range Layouts {
+		if s == l {
+			return true
+		}
+	}
+	return false
+}
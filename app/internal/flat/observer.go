@@ -0,0 +1,46 @@
+package flat
+
+import "context"
+
+type EventType string
+
+const (
+	EventCreated       EventType = "flat.created"
+	EventStatusChanged EventType = "flat.status_changed"
+	EventPriceChanged  EventType = "flat.price_changed"
+)
+
+// Event describes a change stored by the Service.
+type Event struct {
+	Type       EventType
+	Flat       FlatDTO
+	PrevStatus string
+}
+
+// EventData is the JSON payload of an event sent to subscribers.
+type EventData struct {
+	FlatDTO
+	PrevStatus string `json:"prev_status,omitempty"`
+}
+
+func (e Event) Data() EventData {
+	return EventData{FlatDTO: e.Flat, PrevStatus: e.PrevStatus}
+}
+
+// Observer is notified synchronously after a change is stored, so it should
+// be quick and must not fail the request.
+type Observer interface {
+	OnFlatEvent(ctx context.Context, e Event)
+}
+
+// AddObserver registers o for all subsequent changes. It is not safe to call
+// while requests are served.
+func (s *Service) AddObserver(o Observer) {
+	s.observers = append(s.observers, o)
+}
+
+func (s *Service) notify(ctx context.Context, e Event) {
+	for _, o := range s.observers {
+		o.OnFlatEvent(ctx, e)
+	}
+}
@@ -4,75 +4,127 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/Polyrom/houses_api/pkg/client/postgres"
 	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
-type repository struct {
-	client postgres.Client
-	logger logging.Logger
+const uniqueViolation = "23505"
+
+// flatColumns are read by Scan in this order.
+const flatColumns = `id, house_id, number, price, rooms, floor, total_area, living_area, kitchen_area, balcony, layout, status, prev_price`
+
+// Columns are flatColumns of the flats joined as f, so that other
+// repositories can read flats too.
+const Columns = `f.id, f.house_id, f.number, f.price, f.rooms, f.floor, f.total_area, f.living_area, f.kitchen_area, f.balcony, f.layout, f.status, f.prev_price`
+
+// Scan reads flatColumns or Columns into f and the columns selected after
+// them into extra.
+func Scan(row pgx.Row, f *FlatDTO, extra ...any) error {
+	dest := []any{&f.ID, &f.HouseID, &f.Number, &f.Price, &f.Rooms, &f.Floor,
+		&f.TotalArea, &f.LivingArea, &f.KitchenArea, &f.Balcony, &f.Layout, &f.Status, &f.PrevPrice}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	f.setPriceDrop()
+	return nil
 }
 
-func (r *repository) GetByHouseIDClient(ctx context.Context, fl FlatID) ([]FlatDTO, error) {
-	q := `SELECT 
-					id, house_id, price, rooms, status 
-				FROM 
-					flats 
-				WHERE house_id = $1
-				AND status = 'approved'`
+// filterClause appends the conditions of f to the WHERE clause of a listing
+// and their values to args.
+func filterClause(f Filter, args []any) (string, []any) {
+	var b strings.Builder
+	cond := func(expr string, v any) {
+		args = append(args, v)
+		fmt.Fprintf(&b, "\n\t\t\t\tAND "+expr, len(args))
+	}
+	if f.Rooms != 0 {
+		cond("rooms = $%d", f.Rooms)
+	}
+	if f.PriceMin != 0 {
+		cond("price >= $%d", f.PriceMin)
+	}
+	if f.PriceMax != 0 {
+		cond("price <= $%d", f.PriceMax)
+	}
+	if f.FloorMin != nil {
+		cond("floor >= $%d", *f.FloorMin)
+	}
+	if f.FloorMax != nil {
+		cond("floor <= $%d", *f.FloorMax)
+	}
+	if f.AreaMin != 0 {
+		cond("total_area >= $%d", f.AreaMin)
+	}
+	if f.AreaMax != 0 {
+		cond("total_area <= $%d", f.AreaMax)
+	}
+	if f.Balcony != nil {
+		cond("balcony = $%d", *f.Balcony)
+	}
+	if f.Layout != "" {
+		cond("layout = $%d", f.Layout)
+	}
+	return b.String(), args
+}
 
-	rows, err := r.client.Query(ctx, q, fl)
+func (r *repository) list(ctx context.Context, q string, args []any) ([]FlatDTO, error) {
+	rows, err := r.client.Query(ctx, q+"\n\t\t\t\tORDER BY number", args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 	fls := make([]FlatDTO, 0)
 	for rows.Next() {
 		var f FlatDTO
-		err = rows.Scan(&f.ID, &f.HouseID, &f.Price, &f.Rooms, &f.Status)
-		if err != nil {
+		if err = Scan(rows, &f); err != nil {
 			return nil, err
 		}
 		fls = append(fls, f)
 	}
-	return fls, nil
+	return fls, rows.Err()
+}
+
+type repository struct {
+	client postgres.Client
+	logger logging.Logger
 }
 
-func (r *repository) GetByHouseIDModerator(ctx context.Context, fl FlatID) ([]FlatDTO, error) {
+func (r *repository) GetByHouseIDClient(ctx context.Context, fl FlatID, f Filter) ([]FlatDTO, error) {
+	where, args := filterClause(f, []any{fl})
 	q := `SELECT 
-					id, house_id, price, rooms, status 
+					` + flatColumns + ` 
 				FROM 
 					flats 
-				WHERE house_id = $1`
+				WHERE house_id = $1
+				AND status = 'approved'` + where
+	return r.list(ctx, q, args)
+}
 
-	rows, err := r.client.Query(ctx, q, fl)
-	if err != nil {
-		return nil, err
-	}
-	fls := make([]FlatDTO, 0)
-	for rows.Next() {
-		var f FlatDTO
-		err = rows.Scan(&f.ID, &f.HouseID, &f.Price, &f.Rooms, &f.Status)
-		if err != nil {
-			return nil, err
-		}
-		fls = append(fls, f)
-	}
-	return fls, nil
+func (r *repository) GetByHouseIDModerator(ctx context.Context, fl FlatID, f Filter) ([]FlatDTO, error) {
+	where, args := filterClause(f, []any{fl})
+	q := `SELECT 
+					` + flatColumns + ` 
+				FROM 
+					flats 
+				WHERE house_id = $1` + where
+	return r.list(ctx, q, args)
 }
 
 func (r *repository) GetByID(ctx context.Context, fl GetFlatByIDDTO) (FlatDTO, error) {
 	q := `SELECT 
-					id, house_id, price, rooms, moderator, status 
+					` + flatColumns + `, moderator 
 				FROM 
 					flats 
 				WHERE id = $1
 				AND house_id = $2`
 	var fdto FlatDTO
 	var modid sql.NullString
-	err := r.client.QueryRow(ctx, q, fl.ID, fl.HouseID).
-		Scan(&fdto.ID, &fdto.HouseID, &fdto.Price, &fdto.Rooms, &modid, &fdto.Status)
+	err := Scan(r.client.QueryRow(ctx, q, fl.ID, fl.HouseID), &fdto, &modid)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.Is(err, pgErr) {
@@ -88,18 +140,36 @@ func (r *repository) GetByID(ctx context.Context, fl GetFlatByIDDTO) (FlatDTO, e
 	return fdto, nil
 }
 
+func (r *repository) GetByNumber(ctx context.Context, hid int, number int) (FlatDTO, error) {
+	q := `SELECT 
+					` + flatColumns + ` 
+				FROM 
+					flats 
+				WHERE house_id = $1
+				AND number = $2`
+	var f FlatDTO
+	err := Scan(r.client.QueryRow(ctx, q, hid, number), &f)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return FlatDTO{}, ErrNotFound
+	}
+	return f, err
+}
+
 func (r *repository) Create(ctx context.Context, fl CreateFlatDTO) (FlatDTO, error) {
 	q := `INSERT INTO flats 
-					(house_id, price, rooms) 
+					(house_id, number, price, rooms, floor, total_area, living_area, kitchen_area, balcony, layout, owner_id) 
 				VALUES 
-					($1, $2, $3)
+					($1, NULLIF($2, 0), $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, '')::uuid)
 				RETURNING 
-					id, house_id, price, rooms, status`
+					` + flatColumns
 	var f FlatDTO
-	err := r.client.QueryRow(ctx, q, fl.HouseID, fl.Price, fl.Rooms).
-		Scan(&f.ID, &f.HouseID, &f.Price, &f.Rooms, &f.Status)
+	err := Scan(r.client.QueryRow(ctx, q, fl.HouseID, fl.Number, fl.Price, fl.Rooms, fl.Floor,
+		fl.TotalArea, fl.LivingArea, fl.KitchenArea, fl.Balcony, fl.Layout, fl.OwnerID), &f)
 	if err != nil {
 		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return f, ErrNumberTaken
+		}
 		if errors.Is(err, pgErr) {
 			pgErr = err.(*pgconn.PgError)
 			r.logger.Errorf("SQL Error: %s, Detail: %s, Where: %s", pgErr.Message, pgErr.Detail, pgErr.Where)
@@ -116,9 +186,9 @@ func (r *repository) Update(ctx context.Context, fl UpdateFlatStatusDTO) (FlatDT
 				WHERE id = $2
 				AND house_id = $3
 				RETURNING 
-					id, house_id, price, rooms, status`
+					` + flatColumns
 	var f FlatDTO
-	err := r.client.QueryRow(ctx, q, fl.Status, fl.ID, fl.HouseID).Scan(&f.ID, &f.HouseID, &f.Price, &f.Rooms, &f.Status)
+	err := Scan(r.client.QueryRow(ctx, q, fl.Status, fl.ID, fl.HouseID), &f)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.Is(err, pgErr) {
@@ -137,10 +207,9 @@ func (r *repository) UpdateWithNewMod(ctx context.Context, uid string, fl Update
 				WHERE id = $3
 				AND house_id = $4
 				RETURNING 
-				id, house_id, price, rooms, status`
+				` + flatColumns
 	var f FlatDTO
-	err := r.client.QueryRow(ctx, q, fl.Status, uid, fl.ID, fl.HouseID).
-		Scan(&f.ID, &f.HouseID, &f.Price, &f.Rooms, &f.Status)
+	err := Scan(r.client.QueryRow(ctx, q, fl.Status, uid, fl.ID, fl.HouseID), &f)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.Is(err, pgErr) {
@@ -153,6 +222,76 @@ func (r *repository) UpdateWithNewMod(ctx context.Context, uid string, fl Update
 	return f, nil
 }
 
+func (r *repository) Get(ctx context.Context, id FlatID) (FlatDTO, error) {
+	q := `SELECT 
+					` + flatColumns + `, owner_id 
+				FROM 
+					flats 
+				WHERE id = $1`
+	var f FlatDTO
+	var ownerID sql.NullString
+	err := Scan(r.client.QueryRow(ctx, q, id), &f, &ownerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return FlatDTO{}, ErrNotFound
+	}
+	f.OwnerID = ownerID.String
+	return f, err
+}
+
+// UpdatePrice changes the price and logs the change in one statement, so
+// the history cannot miss a change.
+func (r *repository) UpdatePrice(ctx context.Context, id FlatID, price int, uid string) (FlatDTO, error) {
+	q := `WITH updated AS (
+					UPDATE flats 
+					SET prev_price = price, price = $2
+					WHERE id = $1
+					AND price <> $2
+					RETURNING 
+						` + flatColumns + `
+				), logged AS (
+					INSERT INTO flat_prices 
+						(flat_id, old_price, price, changed_by) 
+					SELECT id, prev_price, price, NULLIF($3, '')::uuid FROM updated
+				)
+				SELECT 
+					` + flatColumns + ` 
+				FROM 
+					updated`
+	var f FlatDTO
+	err := Scan(r.client.QueryRow(ctx, q, id, price, uid), &f)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return FlatDTO{}, ErrNotFound
+	}
+	return f, err
+}
+
+func (r *repository) PriceHistory(ctx context.Context, id FlatID) ([]PriceChange, error) {
+	q := `SELECT 
+					old_price, price, changed_by, changed_at 
+				FROM 
+					flat_prices 
+				WHERE flat_id = $1
+				ORDER BY id`
+	rows, err := r.client.Query(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	changes := make([]PriceChange, 0)
+	for rows.Next() {
+		var c PriceChange
+		var changedBy sql.NullString
+		if err = rows.Scan(&c.OldPrice, &c.Price, &changedBy, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		if changedBy.Valid {
+			c.ChangedBy = &changedBy.String
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
 func NewRepository(c postgres.Client, l logging.Logger) Repository {
 	return &repository{
 		client: c,
@@ -9,43 +9,150 @@ import (
 	"github.com/Polyrom/houses_api/pkg/logging"
 )
 
+var (
+	ErrNotAuthenticated = errors.New("user not authenticated")
+	ErrNotFound         = errors.New("flat not found")
+	ErrStatusJump       = errors.New("cannot approve/decline without moderation")
+	ErrTakenByOther     = errors.New("already taken by another moderator")
+	ErrNumberTaken      = errors.New("flat with this number already exists in the house")
+	ErrForbidden        = errors.New("only moderators and the owner of the flat may change its price")
+)
+
 type Service struct {
-	repo   Repository
-	logger logging.Logger
+	repo      Repository
+	logger    logging.Logger
+	observers []Observer
 }
 
-func (s *Service) GetByHouseID(ctx context.Context, f FlatID) ([]FlatDTO, error) {
-	userRole := ctx.Value(middleware.UserRole).(middleware.Role)
+func (s *Service) GetByHouseID(ctx context.Context, f FlatID, filter Filter) ([]FlatDTO, error) {
+	userRole, _ := middleware.CurrentRole(ctx)
 	if userRole == middleware.Moderator {
-		return s.repo.GetByHouseIDModerator(ctx, f)
+		return s.repo.GetByHouseIDModerator(ctx, f, filter)
 	}
-	return s.repo.GetByHouseIDClient(ctx, f)
+	return s.repo.GetByHouseIDClient(ctx, f, filter)
 }
 
 func (s *Service) Create(ctx context.Context, f CreateFlatDTO) (FlatDTO, error) {
-	return s.repo.Create(ctx, f)
+	f.OwnerID, _ = middleware.CurrentUserID(ctx)
+	created, err := s.repo.Create(ctx, f)
+	if err != nil {
+		return FlatDTO{}, err
+	}
+	s.notify(ctx, Event{Type: EventCreated, Flat: created})
+	return created, nil
 }
 
 func (s *Service) Update(ctx context.Context, f UpdateFlatStatusDTO) (FlatDTO, error) {
-	userID := ctx.Value(middleware.UserID).(string)
-	fldto := GetFlatByIDDTO{ID: f.ID, HouseID: f.HouseID}
-	storedFlat, err := s.repo.GetByID(ctx, fldto)
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		return FlatDTO{}, ErrNotAuthenticated
+	}
+	storedFlat, err := s.find(ctx, f)
 	if err != nil {
-		userNotFoundErr := errors.New("user not found")
-		return FlatDTO{}, userNotFoundErr
+		return FlatDTO{}, ErrNotFound
 	}
+	f.ID = storedFlat.ID
 	if storedFlat.Status == modstatus.Created.String() {
 		if f.Status != modstatus.OnModeration.String() {
-			canJumpStatusErr := errors.New("cannot approve/decline without moderation")
-			return FlatDTO{}, canJumpStatusErr
+			return FlatDTO{}, ErrStatusJump
 		}
-		return s.repo.UpdateWithNewMod(ctx, userID, f)
+		updated, err := s.repo.UpdateWithNewMod(ctx, userID, f)
+		return s.statusChanged(ctx, updated, storedFlat.Status, err)
 	}
 	if storedFlat.Status == modstatus.OnModeration.String() && storedFlat.Moderator != userID {
-		wrongModErr := errors.New("already taken by another moderator")
-		return FlatDTO{}, wrongModErr
+		return FlatDTO{}, ErrTakenByOther
+	}
+	updated, err := s.repo.Update(ctx, f)
+	return s.statusChanged(ctx, updated, storedFlat.Status, err)
+}
+
+// find looks the flat up by id or, without one, by its number in the house.
+func (s *Service) find(ctx context.Context, f UpdateFlatStatusDTO) (FlatDTO, error) {
+	if f.ID == 0 {
+		return s.repo.GetByNumber(ctx, f.HouseID, f.Number)
+	}
+	stored, err := s.repo.GetByID(ctx, GetFlatByIDDTO{ID: f.ID, HouseID: f.HouseID})
+	if err == nil && f.Number != 0 && stored.Number != f.Number {
+		return FlatDTO{}, ErrNotFound
+	}
+	return stored, err
+}
+
+func (s *Service) statusChanged(ctx context.Context, f FlatDTO, prevStatus string, err error) (FlatDTO, error) {
+	if err != nil {
+		return FlatDTO{}, err
+	}
+	if f.Status != prevStatus {
+		s.notify(ctx, Event{Type: EventStatusChanged, Flat: f, PrevStatus: prevStatus})
+	}
+	return f, nil
+}
+
+// UpdatePrice changes the price of a flat on behalf of a moderator or its
+// owner. Subscribers learn about price changes of approved flats only.
+func (s *Service) UpdatePrice(ctx context.Context, id FlatID, p UpdateFlatPriceDTO) (FlatDTO, error) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		return FlatDTO{}, ErrNotAuthenticated
+	}
+	stored, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return FlatDTO{}, err
+	}
+	if !canSee(ctx, stored) {
+		return FlatDTO{}, ErrNotFound
+	}
+	if role, _ := middleware.CurrentRole(ctx); role != middleware.Moderator && stored.OwnerID != userID {
+		return FlatDTO{}, ErrForbidden
+	}
+	if stored.Price == p.Price {
+		return stored, nil
+	}
+	updated, err := s.repo.UpdatePrice(ctx, id, p.Price, userID)
+	if errors.Is(err, ErrNotFound) {
+		// a concurrent request has set the same price
+		return s.repo.Get(ctx, id)
+	}
+	if err != nil {
+		return FlatDTO{}, err
+	}
+	if updated.Status == modstatus.Approved.String() {
+		s.notify(ctx, Event{Type: EventPriceChanged, Flat: updated})
+	}
+	return updated, nil
+}
+
+// PriceHistory lists the price changes of a flat, oldest first. Only
+// moderators see who made each change.
+func (s *Service) PriceHistory(ctx context.Context, id FlatID) ([]PriceChange, error) {
+	stored, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canSee(ctx, stored) {
+		return nil, ErrNotFound
+	}
+	changes, err := s.repo.PriceHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if role, _ := middleware.CurrentRole(ctx); role != middleware.Moderator {
+		for i := range changes {
+			changes[i].ChangedBy = nil
+		}
+	}
+	return changes, nil
+}
+
+// canSee tells whether the user may see the flat: everyone once it is
+// approved, before that only moderators and its owner.
+func canSee(ctx context.Context, f FlatDTO) bool {
+	if f.Status == modstatus.Approved.String() {
+		return true
 	}
-	return s.repo.Update(ctx, f)
+	role, _ := middleware.CurrentRole(ctx)
+	userID, _ := middleware.CurrentUserID(ctx)
+	return role == middleware.Moderator || (f.OwnerID != "" && f.OwnerID == userID)
 }
 
 func NewService(r Repository, l logging.Logger) *Service {
@@ -2,7 +2,9 @@ package flat
 
 import (
 	"context"
+	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/Polyrom/houses_api/internal/middleware"
@@ -37,17 +39,28 @@ func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
 func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
 func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
 
-type MockFlatRepo struct{}
+type MockFlatRepo struct {
+	// updated records the last flat passed to UpdateWithNewMod
+	updated UpdateFlatStatusDTO
+	// priced records the prices passed to UpdatePrice
+	priced []int
+}
 
-func (mfr *MockFlatRepo) GetByHouseIDModerator(ctx context.Context, fl FlatID) ([]FlatDTO, error) {
+func (mfr *MockFlatRepo) GetByHouseIDModerator(ctx context.Context, fl FlatID, f Filter) ([]FlatDTO, error) {
 	return moderFlatDTOList, nil
 }
-func (mfr *MockFlatRepo) GetByHouseIDClient(ctx context.Context, fl FlatID) ([]FlatDTO, error) {
+func (mfr *MockFlatRepo) GetByHouseIDClient(ctx context.Context, fl FlatID, f Filter) ([]FlatDTO, error) {
 	return clientFlatDTOList, nil
 }
 func (mfr *MockFlatRepo) GetByID(ctx context.Context, fl GetFlatByIDDTO) (FlatDTO, error) {
 	return FlatDTO{}, nil
 }
+func (mfr *MockFlatRepo) GetByNumber(ctx context.Context, hid int, number int) (FlatDTO, error) {
+	if hid == 1 && number == 3 {
+		return FlatDTO{ID: 7, HouseID: 1, Number: 3, Status: "created"}, nil
+	}
+	return FlatDTO{}, ErrNotFound
+}
 func (mfr *MockFlatRepo) Create(ctx context.Context, fl CreateFlatDTO) (FlatDTO, error) {
 	return mockFlatDTO, nil
 }
@@ -55,7 +68,38 @@ func (mfr *MockFlatRepo) Update(ctx context.Context, fl UpdateFlatStatusDTO) (Fl
 	return FlatDTO{}, nil
 }
 func (mfr *MockFlatRepo) UpdateWithNewMod(ctx context.Context, uid string, fl UpdateFlatStatusDTO) (FlatDTO, error) {
-	return FlatDTO{}, nil
+	mfr.updated = fl
+	return FlatDTO{ID: fl.ID, HouseID: fl.HouseID, Status: fl.Status}, nil
+}
+
+// Get knows flat 7, not approved yet, and approved flat 8, both of owner.
+func (mfr *MockFlatRepo) Get(ctx context.Context, id FlatID) (FlatDTO, error) {
+	switch id {
+	case 7:
+		return FlatDTO{ID: 7, HouseID: 1, Price: 100, Status: "created", OwnerID: "owner"}, nil
+	case 8:
+		return FlatDTO{ID: 8, HouseID: 1, Price: 100, Status: "approved", OwnerID: "owner"}, nil
+	}
+	return FlatDTO{}, ErrNotFound
+}
+func (mfr *MockFlatRepo) UpdatePrice(ctx context.Context, id FlatID, price int, uid string) (FlatDTO, error) {
+	mfr.priced = append(mfr.priced, price)
+	f, err := mfr.Get(ctx, id)
+	prev := f.Price
+	f.Price, f.PrevPrice = price, &prev
+	return f, err
+}
+func (mfr *MockFlatRepo) PriceHistory(ctx context.Context, id FlatID) ([]PriceChange, error) {
+	changedBy := "owner"
+	return []PriceChange{{OldPrice: 120, Price: 100, ChangedBy: &changedBy}}, nil
+}
+
+type recordingObserver struct {
+	events []Event
+}
+
+func (ro *recordingObserver) OnFlatEvent(ctx context.Context, e Event) {
+	ro.events = append(ro.events, e)
 }
 
 func TestService_GetByHouseID(t *testing.T) {
@@ -83,7 +127,7 @@ func TestService_GetByHouseID(t *testing.T) {
 				repo:   tt.fields.repo,
 				logger: tt.fields.logger,
 			}
-			got, err := s.GetByHouseID(tt.args.ctx, tt.args.f)
+			got, err := s.GetByHouseID(tt.args.ctx, tt.args.f, Filter{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Service.GetByHouseID() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -130,3 +174,173 @@ func TestService_Create(t *testing.T) {
 		})
 	}
 }
+
+func TestService_UpdateByNumber(t *testing.T) {
+	repo := &MockFlatRepo{}
+	s := NewService(repo, &MockLogger{})
+	ctx := context.WithValue(setUpRoleCtx(context.Background(), middleware.Moderator), middleware.UserID, "moder")
+	got, err := s.Update(ctx, UpdateFlatStatusDTO{HouseID: 1, Number: 3, Status: "on moderation"})
+	if err != nil {
+		t.Fatalf("Service.Update() error = %v", err)
+	}
+	if got.ID != 7 || repo.updated.ID != 7 {
+		t.Errorf("Service.Update() updated flat %d, returned %+v, want flat 7", repo.updated.ID, got)
+	}
+	if _, err = s.Update(ctx, UpdateFlatStatusDTO{HouseID: 1, Number: 4, Status: "on moderation"}); err != ErrNotFound {
+		t.Errorf("Service.Update() of a missing number error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestService_UpdatePrice(t *testing.T) {
+	userCtx := func(role middleware.Role, userID string) context.Context {
+		return context.WithValue(setUpRoleCtx(context.Background(), role), middleware.UserID, userID)
+	}
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		id      FlatID
+		price   int
+		wantErr error
+		priced  bool
+		events  int
+	}{
+		{name: "owner of a new flat", ctx: userCtx(middleware.Client, "owner"), id: 7, price: 90, priced: true},
+		{name: "moderator of an approved flat", ctx: userCtx(middleware.Moderator, "moder"), id: 8, price: 90, priced: true, events: 1},
+		{name: "same price", ctx: userCtx(middleware.Moderator, "moder"), id: 8, price: 100},
+		{name: "other client of an approved flat", ctx: userCtx(middleware.Client, "other"), id: 8, price: 90, wantErr: ErrForbidden},
+		{name: "other client of a new flat", ctx: userCtx(middleware.Client, "other"), id: 7, price: 90, wantErr: ErrNotFound},
+		{name: "missing flat", ctx: userCtx(middleware.Moderator, "moder"), id: 9, price: 90, wantErr: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, obs := &MockFlatRepo{}, &recordingObserver{}
+			s := NewService(repo, &MockLogger{})
+			s.AddObserver(obs)
+			got, err := s.UpdatePrice(tt.ctx, tt.id, UpdateFlatPriceDTO{Price: tt.price})
+			if err != tt.wantErr {
+				t.Fatalf("Service.UpdatePrice() error = %v, want %v", err, tt.wantErr)
+			}
+			if (len(repo.priced) > 0) != tt.priced {
+				t.Errorf("repository prices = %v, want a change %v", repo.priced, tt.priced)
+			}
+			if err == nil && got.Price != tt.price {
+				t.Errorf("Service.UpdatePrice() price = %d, want %d", got.Price, tt.price)
+			}
+			if len(obs.events) != tt.events {
+				t.Fatalf("events = %d, want %d", len(obs.events), tt.events)
+			}
+			if tt.events > 0 && (obs.events[0].Type != EventPriceChanged || *obs.events[0].Data().PrevPrice != 100) {
+				t.Errorf("event = %+v, want %s from 100", obs.events[0], EventPriceChanged)
+			}
+		})
+	}
+}
+
+func TestService_PriceHistory(t *testing.T) {
+	s := NewService(&MockFlatRepo{}, &MockLogger{})
+	ctx := context.WithValue(setUpRoleCtx(context.Background(), middleware.Client), middleware.UserID, "other")
+	if _, err := s.PriceHistory(ctx, 7); err != ErrNotFound {
+		t.Errorf("history of someone else's new flat error = %v, want %v", err, ErrNotFound)
+	}
+	changes, err := s.PriceHistory(ctx, 8)
+	if err != nil || len(changes) != 1 {
+		t.Fatalf("history of an approved flat = %v, %v, want one change", changes, err)
+	}
+	if changes[0].ChangedBy != nil {
+		t.Errorf("client sees changed_by = %s, want it hidden", *changes[0].ChangedBy)
+	}
+	changes, err = s.PriceHistory(setUpRoleCtx(context.Background(), middleware.Moderator), 8)
+	if err != nil || len(changes) != 1 || changes[0].ChangedBy == nil {
+		t.Errorf("moderator history = %v, %v, want changed_by set", changes, err)
+	}
+}
+
+func TestFlatDTO_SetPriceDrop(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+	tests := []struct {
+		name        string
+		prev        *int
+		price       int
+		wantDropped bool
+		wantPercent float64
+	}{
+		{name: "never changed", price: 100},
+		{name: "raised", prev: intPtr(100), price: 120},
+		{name: "dropped", prev: intPtr(12_000_000), price: 10_500_000, wantDropped: true, wantPercent: 12.5},
+		{name: "rounded", prev: intPtr(3), price: 2, wantDropped: true, wantPercent: 33.3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := FlatDTO{Price: tt.price, PrevPrice: tt.prev}
+			f.setPriceDrop()
+			if f.PriceDropped != tt.wantDropped || f.PriceDropPercent != tt.wantPercent {
+				t.Errorf("dropped = %v by %v%%, want %v by %v%%", f.PriceDropped, f.PriceDropPercent, tt.wantDropped, tt.wantPercent)
+			}
+		})
+	}
+}
+
+func TestColumns(t *testing.T) {
+	want := "f." + strings.ReplaceAll(flatColumns, ", ", ", f.")
+	if Columns != want {
+		t.Errorf("Columns = %q, want %q", Columns, want)
+	}
+}
+
+func TestNewValidator_CreateFlat(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+	area := func(a float64) *float64 { return &a }
+	layout := func(l string) *string { return &l }
+	tests := []struct {
+		name    string
+		dto     CreateFlatDTO
+		wantErr bool
+	}{
+		{name: "minimal", dto: mockCreateFlatDTO},
+		{name: "all attributes", dto: CreateFlatDTO{HouseID: 1, Number: 12, Price: 1, Rooms: 2, Floor: intPtr(0),
+			TotalArea: area(54.5), LivingArea: area(30), KitchenArea: area(9.5), Balcony: true, Layout: layout(LayoutIsolated)}},
+		{name: "negative number", dto: CreateFlatDTO{HouseID: 1, Number: -1, Price: 1, Rooms: 1}, wantErr: true},
+		{name: "floor too high", dto: CreateFlatDTO{HouseID: 1, Price: 1, Rooms: 1, Floor: intPtr(1000)}, wantErr: true},
+		{name: "zero area", dto: CreateFlatDTO{HouseID: 1, Price: 1, Rooms: 1, TotalArea: area(0)}, wantErr: true},
+		{name: "rooms larger than the flat", dto: CreateFlatDTO{HouseID: 1, Price: 1, Rooms: 1, TotalArea: area(40), LivingArea: area(30), KitchenArea: area(12)}, wantErr: true},
+		{name: "unknown layout", dto: CreateFlatDTO{HouseID: 1, Price: 1, Rooms: 1, Layout: layout("loft")}, wantErr: true},
+	}
+	validate := NewValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validate.Struct(tt.dto); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	floor, balcony := -1, true
+	tests := []struct {
+		name    string
+		query   string
+		want    Filter
+		wantErr bool
+	}{
+		{name: "empty", query: "", want: Filter{}},
+		{name: "all filters", query: "rooms=2&price_min=100&price_max=200&floor_min=-1&area_min=30.5&area_max=80&balcony=true&layout=studio",
+			want: Filter{Rooms: 2, PriceMin: 100, PriceMax: 200, FloorMin: &floor, AreaMin: 30.5, AreaMax: 80, Balcony: &balcony, Layout: LayoutStudio}},
+		{name: "negative price", query: "price_min=-1", wantErr: true},
+		{name: "invalid floor", query: "floor_max=top", wantErr: true},
+		{name: "invalid balcony", query: "balcony=maybe", wantErr: true},
+		{name: "unknown layout", query: "layout=loft", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, _ := url.ParseQuery(tt.query)
+			got, err := parseFilter(q)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFilter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
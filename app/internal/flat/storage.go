@@ -3,10 +3,17 @@ package flat
 import "context"
 
 type Repository interface {
-	GetByHouseIDModerator(ctx context.Context, fl FlatID) ([]FlatDTO, error)
-	GetByHouseIDClient(ctx context.Context, fl FlatID) ([]FlatDTO, error)
+	GetByHouseIDModerator(ctx context.Context, fl FlatID, f Filter) ([]FlatDTO, error)
+	GetByHouseIDClient(ctx context.Context, fl FlatID, f Filter) ([]FlatDTO, error)
 	GetByID(ctx context.Context, fl GetFlatByIDDTO) (FlatDTO, error)
+	// Get finds a flat by id alone and fills in its owner.
+	Get(ctx context.Context, id FlatID) (FlatDTO, error)
+	GetByNumber(ctx context.Context, hid int, number int) (FlatDTO, error)
 	Create(ctx context.Context, fl CreateFlatDTO) (FlatDTO, error)
 	Update(ctx context.Context, fl UpdateFlatStatusDTO) (FlatDTO, error)
 	UpdateWithNewMod(ctx context.Context, uid string, fl UpdateFlatStatusDTO) (FlatDTO, error)
+	// UpdatePrice sets the price and records the change made by uid. It
+	// returns ErrNotFound if the flat has this price already.
+	UpdatePrice(ctx context.Context, id FlatID, price int, uid string) (FlatDTO, error)
+	PriceHistory(ctx context.Context, id FlatID) ([]PriceChange, error)
 }
@@ -0,0 +1,56 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/house"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/internal/user"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus logs err and converts it to a gRPC status. Unknown errors become
+// Internal, the same way the HTTP handlers answer 500.
+func toStatus(ctx context.Context, l logging.Logger, err error) error {
+	reqID := middleware.RequestID(ctx)
+	code := codeOf(err)
+	if code == codes.Internal {
+		l.Errorf("internal error req_id=%s: %v", reqID, err)
+	} else {
+		l.Errorf("request failed req_id=%s code=%s: %v", reqID, code, err)
+	}
+	msg := err.Error()
+	switch {
+	case errors.Is(err, user.ErrNotFound):
+		msg = user.ErrNotFound.Error()
+	case errors.Is(err, user.ErrWrongPassword):
+		msg = user.ErrWrongPassword.Error()
+	}
+	return status.Error(code, msg)
+}
+
+func codeOf(err error) codes.Code {
+	var validationErrs validator.ValidationErrors
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.As(err, &validationErrs), errors.Is(err, house.ErrDeveloperNotFound):
+		return codes.InvalidArgument
+	case errors.Is(err, user.ErrNotFound), errors.Is(err, flat.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, user.ErrWrongPassword), errors.Is(err, flat.ErrNotAuthenticated):
+		return codes.Unauthenticated
+	case errors.Is(err, flat.ErrStatusJump), errors.Is(err, flat.ErrTakenByOther):
+		return codes.FailedPrecondition
+	case errors.Is(err, flat.ErrNumberTaken):
+		return codes.AlreadyExists
+	}
+	return codes.Internal
+}
@@ -0,0 +1,141 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/modstatus"
+	housesv1 "github.com/Polyrom/houses_api/pkg/api/houses/v1"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var statuses = map[housesv1.Status]modstatus.ModerationStatus{
+	housesv1.Status_STATUS_CREATED:       modstatus.Created,
+	housesv1.Status_STATUS_APPROVED:      modstatus.Approved,
+	housesv1.Status_STATUS_DECLINED:      modstatus.Declined,
+	housesv1.Status_STATUS_ON_MODERATION: modstatus.OnModeration,
+}
+
+type flatsServer struct {
+	housesv1.UnimplementedFlatsServer
+	s *flat.Service
+	l logging.Logger
+}
+
+func (fs *flatsServer) ListHouseFlats(ctx context.Context, req *housesv1.ListHouseFlatsRequest) (*housesv1.ListHouseFlatsResponse, error) {
+	if req.GetHouseId() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "invalid house id")
+	}
+	flatsFound, err := fs.s.GetByHouseID(ctx, flat.FlatID(req.GetHouseId()), flat.Filter{})
+	if err != nil {
+		return nil, toStatus(ctx, fs.l, err)
+	}
+	resp := &housesv1.ListHouseFlatsResponse{Flats: make([]*housesv1.Flat, 0, len(flatsFound))}
+	for _, f := range flatsFound {
+		resp.Flats = append(resp.Flats, flatToProto(f))
+	}
+	return resp, nil
+}
+
+func (fs *flatsServer) CreateFlat(ctx context.Context, req *housesv1.CreateFlatRequest) (*housesv1.Flat, error) {
+	fdto := flat.CreateFlatDTO{
+		HouseID:     int(req.GetHouseId()),
+		Number:      int(req.GetNumber()),
+		Price:       int(req.GetPrice()),
+		Rooms:       int(req.GetRooms()),
+		Floor:       fromInt32(req.Floor),
+		TotalArea:   req.TotalArea,
+		LivingArea:  req.LivingArea,
+		KitchenArea: req.KitchenArea,
+		Balcony:     req.GetBalcony(),
+		Layout:      req.Layout,
+	}
+	if err := flat.NewValidator().Struct(fdto); err != nil {
+		return nil, toStatus(ctx, fs.l, err)
+	}
+	newFlat, err := fs.s.Create(ctx, fdto)
+	if err != nil {
+		return nil, toStatus(ctx, fs.l, err)
+	}
+	return flatToProto(newFlat), nil
+}
+
+func (fs *flatsServer) UpdateFlat(ctx context.Context, req *housesv1.UpdateFlatRequest) (*housesv1.Flat, error) {
+	ufsdto := flat.UpdateFlatStatusDTO{
+		ID:      int(req.GetId()),
+		HouseID: int(req.GetHouseId()),
+		Number:  int(req.GetNumber()),
+	}
+	if st, ok := statuses[req.GetStatus()]; ok {
+		ufsdto.Status = st.String()
+	}
+	if err := flat.NewValidator().Struct(ufsdto); err != nil {
+		return nil, toStatus(ctx, fs.l, err)
+	}
+	updatedFlat, err := fs.s.Update(ctx, ufsdto)
+	if err != nil {
+		return nil, toStatus(ctx, fs.l, err)
+	}
+	return flatToProto(updatedFlat), nil
+}
+
+func flatToProto(f flat.FlatDTO) *housesv1.Flat {
+	pf := &housesv1.Flat{
+		Id:               int64(f.ID),
+		HouseId:          int64(f.HouseID),
+		Number:           int32(f.Number),
+		Price:            int64(f.Price),
+		Rooms:            int32(f.Rooms),
+		Floor:            toInt32(f.Floor),
+		TotalArea:        f.TotalArea,
+		LivingArea:       f.LivingArea,
+		KitchenArea:      f.KitchenArea,
+		Balcony:          f.Balcony,
+		Layout:           f.Layout,
+		PrevPrice:        toInt64(f.PrevPrice),
+		PriceDropped:     f.PriceDropped,
+		PriceDropPercent: f.PriceDropPercent,
+	}
+	for ps, ms := range statuses {
+		if ms.String() == f.Status {
+			pf.Status = ps
+		}
+	}
+	return pf
+}
+
+// toInt32 and the helpers below convert optional fields between the domain
+// types and the generated ones.
+func toInt32(v *int) *int32 {
+	if v == nil {
+		return nil
+	}
+	n := int32(*v)
+	return &n
+}
+
+func fromInt32(v *int32) *int {
+	if v == nil {
+		return nil
+	}
+	n := int(*v)
+	return &n
+}
+
+func toInt64(v *int) *int64 {
+	if v == nil {
+		return nil
+	}
+	n := int64(*v)
+	return &n
+}
+
+func fromInt64(v *int64) *int {
+	if v == nil {
+		return nil
+	}
+	n := int(*v)
+	return &n
+}
@@ -0,0 +1,62 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/Polyrom/houses_api/internal/house"
+	housesv1 "github.com/Polyrom/houses_api/pkg/api/houses/v1"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type housesServer struct {
+	housesv1.UnimplementedHousesServer
+	s *house.Service
+	l logging.Logger
+}
+
+func (hs *housesServer) CreateHouse(ctx context.Context, req *housesv1.CreateHouseRequest) (*housesv1.House, error) {
+	hdto := house.CreateHouseDTO{
+		Address:     req.GetAddress(),
+		Year:        int(req.GetYear()),
+		DeveloperID: fromInt64(req.DeveloperId),
+		Developer:   req.GetDeveloper(),
+		Floors:      fromInt32(req.Floors),
+		Material:    req.Material,
+		Latitude:    req.Latitude,
+		Longitude:   req.Longitude,
+		City:        req.City,
+		District:    req.District,
+		Parking:     req.GetParking(),
+		Elevator:    req.GetElevator(),
+	}
+	if err := validator.New().Struct(hdto); err != nil {
+		return nil, toStatus(ctx, hs.l, err)
+	}
+	newHouse, err := hs.s.Create(ctx, hdto)
+	if err != nil {
+		return nil, toStatus(ctx, hs.l, err)
+	}
+	return houseToProto(newHouse), nil
+}
+
+func houseToProto(h house.House) *housesv1.House {
+	return &housesv1.House{
+		Id:          int64(h.ID),
+		Address:     h.Address,
+		Year:        int32(h.Year),
+		DeveloperId: toInt64(h.DeveloperID),
+		Developer:   h.Developer,
+		Floors:      toInt32(h.Floors),
+		Material:    h.Material,
+		Latitude:    h.Latitude,
+		Longitude:   h.Longitude,
+		City:        h.City,
+		District:    h.District,
+		Parking:     h.Parking,
+		Elevator:    h.Elevator,
+		CreatedAt:   timestamppb.New(h.CreatedAt),
+		UpdateAt:    timestamppb.New(h.UpdateAt),
+	}
+}
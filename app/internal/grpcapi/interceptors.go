@@ -0,0 +1,186 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/metrics"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	housesv1 "github.com/Polyrom/houses_api/pkg/api/houses/v1"
+	"github.com/Polyrom/houses_api/pkg/client/postgres"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const authorizationKey = "authorization"
+
+var requestIDKey = strings.ToLower(middleware.RequestIDHeader)
+
+type access int
+
+const (
+	public access = iota
+	authenticated
+	moderatorOnly
+)
+
+// methodAccess mirrors the middlewares the HTTP routes are wrapped in.
+// Methods missing from the map are denied.
+var methodAccess = map[string]access{
+	housesv1.Users_Register_FullMethodName:       public,
+	housesv1.Users_Login_FullMethodName:          public,
+	housesv1.Users_DummyLogin_FullMethodName:     public,
+	housesv1.Houses_CreateHouse_FullMethodName:   moderatorOnly,
+	housesv1.Flats_ListHouseFlats_FullMethodName: authenticated,
+	housesv1.Flats_CreateFlat_FullMethodName:     authenticated,
+	housesv1.Flats_UpdateFlat_FullMethodName:     moderatorOnly,
+}
+
+// methodRoutes maps methods to the HTTP route templates they mirror, so
+// rate limits are configured once and both APIs draw from the same buckets.
+var methodRoutes = map[string]string{
+	housesv1.Users_Register_FullMethodName:       "/api/v1/register",
+	housesv1.Users_Login_FullMethodName:          "/api/v1/login",
+	housesv1.Users_DummyLogin_FullMethodName:     "/api/v1/dummyLogin",
+	housesv1.Houses_CreateHouse_FullMethodName:   "/api/v1/house/create",
+	housesv1.Flats_ListHouseFlats_FullMethodName: "/api/v1/house/{id}/flats",
+	housesv1.Flats_CreateFlat_FullMethodName:     "/api/v1/flat/create",
+	housesv1.Flats_UpdateFlat_FullMethodName:     "/api/v1/flat/update",
+}
+
+// requestIDInterceptor reuses a valid x-request-id from the metadata or
+// generates one, returns it in the response header and tags DB sessions.
+func requestIDInterceptor(l logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		id := firstMetadata(ctx, requestIDKey)
+		if !middleware.ValidRequestID(id) {
+			id = uuid.New().String()
+		}
+		ctx = context.WithValue(ctx, middleware.ContextKeyRequestID, id)
+		ctx = postgres.WithRequestID(ctx, id)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+		l.Infof("grpc call %s req_id=%s", info.FullMethod, id)
+		resp, err := handler(ctx, req)
+		l.Infof("grpc call %s handled code=%s req_id=%s", info.FullMethod, status.Code(err), id)
+		return resp, err
+	}
+}
+
+func recoveryInterceptor(l logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				metrics.Panics.Add(1)
+				l.Errorf("panic req_id=%s: %v\n%s", middleware.RequestID(ctx), rec, debug.Stack())
+				err = status.Error(codes.Internal, "internal server error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// authInterceptor resolves the token from the authorization metadata and
+// stores the user ID and role in the context the same way the HTTP auth
+// middlewares do.
+func authInterceptor(s middleware.Service, l logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		acc, ok := methodAccess[info.FullMethod]
+		if !ok {
+			return nil, status.Error(codes.Unimplemented, "unknown method")
+		}
+		if acc == public {
+			return handler(ctx, req)
+		}
+		reqID := middleware.RequestID(ctx)
+		token := firstMetadata(ctx, authorizationKey)
+		if token == "" {
+			l.Errorf("unauthorized req_id=%s: no token", reqID)
+			return nil, status.Error(codes.Unauthenticated, "no token")
+		}
+		userIDRole, err := s.GetRoleByToken(ctx, middleware.Token(token))
+		if err != nil {
+			l.Errorf("unauthorized req_id=%s: %v", reqID, err)
+			return nil, status.Error(codes.Unauthenticated, "user not found")
+		}
+		switch {
+		case acc == moderatorOnly && userIDRole.Role != middleware.Moderator:
+			l.Errorf("permission denied req_id=%s: not a moderator", reqID)
+			return nil, status.Error(codes.PermissionDenied, "not a moderator")
+		case userIDRole.Role != middleware.Client && userIDRole.Role != middleware.Moderator:
+			l.Errorf("permission denied req_id=%s: not client or moderator", reqID)
+			return nil, status.Error(codes.PermissionDenied, "not client or moderator")
+		}
+		ctx = context.WithValue(ctx, middleware.UserRole, userIDRole.Role)
+		ctx = context.WithValue(ctx, middleware.UserID, userIDRole.ID)
+		return handler(ctx, req)
+	}
+}
+
+// authRateLimitInterceptor limits calls of the methods taking a token by
+// peer address before the token is resolved, so that guessing tokens is
+// throttled as well.
+func authRateLimitInterceptor(rl *middleware.RateLimiter, l logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if acc, ok := methodAccess[info.FullMethod]; ok && acc == public {
+			return handler(ctx, req)
+		}
+		client := "ip:" + peerIP(ctx)
+		if allowed, retryAfter := rl.Allow(middleware.AuthRoute, client); !allowed {
+			l.Errorf("rate limited req_id=%s key=%s|%s", middleware.RequestID(ctx), middleware.AuthRoute, client)
+			return nil, status.Errorf(codes.ResourceExhausted, "too many requests, retry in %s", retryAfter.Round(time.Second))
+		}
+		return handler(ctx, req)
+	}
+}
+
+// rateLimitInterceptor applies the HTTP route limits to the matching
+// methods, keyed by the authenticated user or the peer address. It must run
+// after authInterceptor for the user to be known.
+func rateLimitInterceptor(rl *middleware.RateLimiter, l logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		route, ok := methodRoutes[info.FullMethod]
+		if !ok {
+			route = info.FullMethod
+		}
+		client := "ip:" + peerIP(ctx)
+		if uid, ok := middleware.CurrentUserID(ctx); ok {
+			client = "user:" + uid
+		}
+		if allowed, retryAfter := rl.Allow(route, client); !allowed {
+			l.Errorf("rate limited req_id=%s key=%s|%s", middleware.RequestID(ctx), route, client)
+			return nil, status.Errorf(codes.ResourceExhausted, "too many requests, retry in %s", retryAfter.Round(time.Second))
+		}
+		return handler(ctx, req)
+	}
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
@@ -0,0 +1,170 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/Polyrom/houses_api/internal/config"
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/house"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/internal/user"
+	housesv1 "github.com/Polyrom/houses_api/pkg/api/houses/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type MockLogger struct{}
+
+func (ml *MockLogger) Trace(args ...interface{})                   {}
+func (ml *MockLogger) Debug(args ...interface{})                   {}
+func (ml *MockLogger) Info(args ...interface{})                    {}
+func (ml *MockLogger) Warn(args ...interface{})                    {}
+func (ml *MockLogger) Warning(args ...interface{})                 {}
+func (ml *MockLogger) Error(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   {}
+func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
+func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
+func (ml *MockLogger) Infof(format string, args ...interface{})    {}
+func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
+func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
+func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
+func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
+
+type MockAuthRepo struct{}
+
+func (mr *MockAuthRepo) GetRoleByToken(ctx context.Context, token middleware.Token) (middleware.UserIDRoleDTO, error) {
+	switch token {
+	case "client":
+		return middleware.UserIDRoleDTO{ID: "client-id", Role: middleware.Client}, nil
+	case "moderator":
+		return middleware.UserIDRoleDTO{ID: "moderator-id", Role: middleware.Moderator}, nil
+	}
+	return middleware.UserIDRoleDTO{}, errors.New("no rows in result set")
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	s := middleware.NewService(&MockAuthRepo{}, &MockLogger{})
+	interceptor := authInterceptor(s, &MockLogger{})
+	tests := []struct {
+		name     string
+		method   string
+		token    string
+		wantCode codes.Code
+		wantRole middleware.Role
+	}{
+		{name: "public without token", method: housesv1.Users_DummyLogin_FullMethodName, wantCode: codes.OK},
+		{name: "no token", method: housesv1.Flats_CreateFlat_FullMethodName, wantCode: codes.Unauthenticated},
+		{name: "unknown token", method: housesv1.Flats_CreateFlat_FullMethodName, token: "nope", wantCode: codes.Unauthenticated},
+		{name: "client", method: housesv1.Flats_CreateFlat_FullMethodName, token: "client", wantCode: codes.OK, wantRole: middleware.Client},
+		{name: "client on moderator method", method: housesv1.Flats_UpdateFlat_FullMethodName, token: "client", wantCode: codes.PermissionDenied},
+		{name: "moderator", method: housesv1.Houses_CreateHouse_FullMethodName, token: "moderator", wantCode: codes.OK, wantRole: middleware.Moderator},
+		{name: "unknown method", method: "/houses.v1.Flats/DeleteFlat", token: "moderator", wantCode: codes.Unimplemented},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(authorizationKey, tt.token))
+			}
+			var gotRole middleware.Role
+			handler := func(ctx context.Context, req any) (any, error) {
+				gotRole, _ = middleware.CurrentRole(ctx)
+				return nil, nil
+			}
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code = %s, want %s", got, tt.wantCode)
+			}
+			if gotRole != tt.wantRole {
+				t.Errorf("role = %q, want %q", gotRole, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestRecoveryInterceptor(t *testing.T) {
+	interceptor := recoveryInterceptor(&MockLogger{})
+	handler := func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	}
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+	if got := status.Code(err); got != codes.Internal {
+		t.Errorf("code = %s, want %s", got, codes.Internal)
+	}
+}
+
+func TestRateLimitInterceptor(t *testing.T) {
+	rl := middleware.NewRateLimiter(config.RateLimitConfig{
+		Default: config.RateLimit{Rate: 100, Burst: 100},
+		Routes:  map[string]config.RateLimit{"/api/v1/dummyLogin": {Rate: 0.001, Burst: 2}},
+	})
+	interceptor := rateLimitInterceptor(rl, &MockLogger{})
+	handler := func(ctx context.Context, req any) (any, error) { return nil, nil }
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(1, 1, 1, 1), Port: 1}})
+	// The HTTP route shares the bucket with the gRPC method.
+	if allowed, _ := rl.Allow("/api/v1/dummyLogin", "ip:1.1.1.1"); !allowed {
+		t.Fatal("HTTP call denied")
+	}
+	tests := []struct {
+		name     string
+		method   string
+		wantCode codes.Code
+	}{
+		{name: "last token", method: housesv1.Users_DummyLogin_FullMethodName, wantCode: codes.OK},
+		{name: "exhausted", method: housesv1.Users_DummyLogin_FullMethodName, wantCode: codes.ResourceExhausted},
+		{name: "other method", method: housesv1.Users_Login_FullMethodName, wantCode: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("code = %s, want %s", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	validationErr := flat.NewValidator().Struct(flat.CreateFlatDTO{})
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{err: validationErr, want: codes.InvalidArgument},
+		{err: fmt.Errorf("%w: no rows", user.ErrNotFound), want: codes.NotFound},
+		{err: user.ErrWrongPassword, want: codes.Unauthenticated},
+		{err: flat.ErrNotFound, want: codes.NotFound},
+		{err: flat.ErrStatusJump, want: codes.FailedPrecondition},
+		{err: flat.ErrTakenByOther, want: codes.FailedPrecondition},
+		{err: flat.ErrNumberTaken, want: codes.AlreadyExists},
+		{err: house.ErrDeveloperNotFound, want: codes.InvalidArgument},
+		{err: context.DeadlineExceeded, want: codes.DeadlineExceeded},
+		{err: errors.New("connection refused"), want: codes.Internal},
+	}
+	for _, tt := range tests {
+		if got := codeOf(tt.err); got != tt.want {
+			t.Errorf("codeOf(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestFlatToProto(t *testing.T) {
+	floor, area, layout, prev := 3, 54.5, flat.LayoutStudio, 200
+	f := flat.FlatDTO{ID: 1, HouseID: 2, Number: 7, Price: 150, Rooms: 1, Floor: &floor, TotalArea: &area,
+		Balcony: true, Layout: &layout, PrevPrice: &prev, Status: "approved"}
+	f.PriceDropped, f.PriceDropPercent = true, 25
+	pf := flatToProto(f)
+	if pf.GetNumber() != 7 || pf.GetFloor() != 3 || pf.GetTotalArea() != 54.5 || pf.LivingArea != nil ||
+		!pf.GetBalcony() || pf.GetLayout() != layout || pf.GetPrevPrice() != 200 ||
+		!pf.GetPriceDropped() || pf.GetPriceDropPercent() != 25 || pf.GetStatus() != housesv1.Status_STATUS_APPROVED {
+		t.Errorf("flatToProto() = %v", pf)
+	}
+}
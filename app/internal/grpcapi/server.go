@@ -0,0 +1,28 @@
+package grpcapi
+
+import (
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/house"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/internal/user"
+	housesv1 "github.com/Polyrom/houses_api/pkg/api/houses/v1"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"google.golang.org/grpc"
+)
+
+// NewServer builds a gRPC server exposing the same services as the HTTP API.
+// Interceptors run in order: request ID, panic recovery, per-IP limit of
+// authenticated methods, authorization, per-route rate limiting.
+func NewServer(authService middleware.Service, rl *middleware.RateLimiter, us *user.Service, hs *house.Service, fs *flat.Service, l logging.Logger) *grpc.Server {
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		requestIDInterceptor(l),
+		recoveryInterceptor(l),
+		authRateLimitInterceptor(rl, l),
+		authInterceptor(authService, l),
+		rateLimitInterceptor(rl, l),
+	))
+	housesv1.RegisterUsersServer(srv, &usersServer{s: us, l: l})
+	housesv1.RegisterHousesServer(srv, &housesServer{s: hs, l: l})
+	housesv1.RegisterFlatsServer(srv, &flatsServer{s: fs, l: l})
+	return srv
+}
@@ -0,0 +1,65 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/internal/user"
+	housesv1 "github.com/Polyrom/houses_api/pkg/api/houses/v1"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var userTypes = map[housesv1.UserType]middleware.Role{
+	housesv1.UserType_USER_TYPE_CLIENT:    middleware.Client,
+	housesv1.UserType_USER_TYPE_MODERATOR: middleware.Moderator,
+}
+
+type usersServer struct {
+	housesv1.UnimplementedUsersServer
+	s *user.Service
+	l logging.Logger
+}
+
+func (us *usersServer) Register(ctx context.Context, req *housesv1.RegisterRequest) (*housesv1.RegisterResponse, error) {
+	userdto := user.UserRegisterDTO{
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+		Role:     string(userTypes[req.GetUserType()]),
+	}
+	if err := validator.New().Struct(userdto); err != nil {
+		return nil, toStatus(ctx, us.l, err)
+	}
+	u := user.User{Email: userdto.Email, Password: userdto.Password, Role: userdto.Role}
+	userid, err := us.s.Register(ctx, u)
+	if err != nil {
+		return nil, toStatus(ctx, us.l, err)
+	}
+	return &housesv1.RegisterResponse{UserId: string(userid)}, nil
+}
+
+func (us *usersServer) Login(ctx context.Context, req *housesv1.LoginRequest) (*housesv1.TokenResponse, error) {
+	uldto := user.UserLoginDTO{UserID: user.UserID(req.GetUserId()), Password: req.GetPassword()}
+	if err := validator.New().Struct(uldto); err != nil {
+		return nil, toStatus(ctx, us.l, err)
+	}
+	token, err := us.s.Login(ctx, uldto.UserID, uldto.Password)
+	if err != nil {
+		return nil, toStatus(ctx, us.l, err)
+	}
+	return &housesv1.TokenResponse{Token: string(token)}, nil
+}
+
+func (us *usersServer) DummyLogin(ctx context.Context, req *housesv1.DummyLoginRequest) (*housesv1.TokenResponse, error) {
+	role, ok := userTypes[req.GetUserType()]
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "unknown user type (client or moderator)")
+	}
+	token, err := us.s.DummyLogin(ctx, string(role))
+	if err != nil {
+		return nil, toStatus(ctx, us.l, err)
+	}
+	return &housesv1.TokenResponse{Token: string(token)}, nil
+}
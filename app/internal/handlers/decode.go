@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DecodeJSON strictly decodes a single JSON object from the request body into
+// dst. On failure it returns the HTTP status to respond with: 415 for a wrong
+// Content-Type, 413 for a body over the limit and 400 for anything else.
+func DecodeJSON(r *http.Request, dst any) (int, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return http.StatusUnsupportedMediaType, errors.New("Content-Type must be application/json")
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(dst); err != nil {
+		return decodeErrorStatus(err)
+	}
+	if err = dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return decodeErrorStatus(err)
+		}
+		return http.StatusBadRequest, errors.New("request body must contain a single JSON object")
+	}
+	return http.StatusOK, nil
+}
+
+func decodeErrorStatus(err error) (int, error) {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge, fmt.Errorf("request body must not be larger than %d bytes", maxBytesErr.Limit)
+	case errors.Is(err, io.EOF):
+		return http.StatusBadRequest, errors.New("request body must not be empty")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return http.StatusBadRequest, errors.New("request body contains malformed JSON")
+	case errors.As(err, &syntaxErr):
+		return http.StatusBadRequest, fmt.Errorf("request body contains malformed JSON at position %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			return http.StatusBadRequest, fmt.Errorf("field %q must be of type %s", typeErr.Field, typeErr.Type)
+		}
+		return http.StatusBadRequest, fmt.Errorf("request body must be a JSON %s", typeErr.Type)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return http.StatusBadRequest, fmt.Errorf("unknown field %s", strings.TrimPrefix(err.Error(), "json: unknown field "))
+	}
+	return http.StatusBadRequest, err
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testDTO struct {
+	HouseID int `json:"house_id"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		limit       int64
+		wantCode    int
+		wantErr     string
+	}{
+		{name: "valid", contentType: "application/json; charset=utf-8", body: `{"house_id": 1}`, wantCode: http.StatusOK},
+		{name: "no content type", body: `{"house_id": 1}`, wantCode: http.StatusUnsupportedMediaType},
+		{name: "wrong content type", contentType: "text/plain", body: `{"house_id": 1}`, wantCode: http.StatusUnsupportedMediaType},
+		{name: "unknown field", contentType: "application/json", body: `{"houseid": 1}`, wantCode: http.StatusBadRequest, wantErr: `unknown field "houseid"`},
+		{name: "wrong type", contentType: "application/json", body: `{"house_id": "1"}`, wantCode: http.StatusBadRequest, wantErr: `field "house_id"`},
+		{name: "trailing data", contentType: "application/json", body: `{"house_id": 1}{}`, wantCode: http.StatusBadRequest, wantErr: "single JSON object"},
+		{name: "empty", contentType: "application/json", body: ``, wantCode: http.StatusBadRequest, wantErr: "empty"},
+		{name: "malformed", contentType: "application/json", body: `{"house_id": 1,}`, wantCode: http.StatusBadRequest, wantErr: "malformed"},
+		{name: "too large", contentType: "application/json", body: `{"house_id": 1111111111}`, limit: 10, wantCode: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.limit > 0 {
+				req.Body = http.MaxBytesReader(httptest.NewRecorder(), req.Body, tt.limit)
+			}
+			var dst testDTO
+			code, err := DecodeJSON(req, &dst)
+			if code != tt.wantCode {
+				t.Errorf("DecodeJSON() code = %d, want %d (err: %v)", code, tt.wantCode, err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("DecodeJSON() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,5 +3,13 @@ package handlers
 import "github.com/gorilla/mux"
 
 type Handler interface {
+	// Register mounts the current routes, r is the /api/v1 subrouter.
 	Register(r *mux.Router)
 }
+
+// DeprecatedHandler is implemented by handlers that also serve legacy
+// unversioned routes.
+type DeprecatedHandler interface {
+	// RegisterDeprecated mounts the legacy unversioned routes.
+	RegisterDeprecated(r *mux.Router)
+}
@@ -1,7 +1,27 @@
 package house
 
+// CreateHouseDTO describes a new house, the coordinates are given both or
+// not at all. The developer is referenced by ID; a developer name, kept for
+// older clients, finds the developer with the same key or creates one.
 type CreateHouseDTO struct {
-	Address   string `json:"address" validate:"required"`
-	Year      int    `json:"year" validate:"required"`
-	Developer string `json:"developer"`
+	Address     string   `json:"address" validate:"required"`
+	Year        int      `json:"year" validate:"required"`
+	DeveloperID *int     `json:"developer_id,omitempty" validate:"omitempty,min=1"`
+	Developer   string   `json:"developer" validate:"excluded_with=DeveloperID,max=200"`
+	Floors      *int     `json:"floors,omitempty" validate:"omitempty,min=1,max=300"`
+	Material    *string  `json:"material,omitempty" validate:"omitempty,oneof=brick panel monolith monolith_brick block wood"`
+	Latitude    *float64 `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,min=-90,max=90"`
+	Longitude   *float64 `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,min=-180,max=180"`
+	City        *string  `json:"city,omitempty" validate:"omitempty,max=200"`
+	District    *string  `json:"district,omitempty" validate:"omitempty,max=200"`
+	Parking     bool     `json:"parking,omitempty"`
+	Elevator    bool     `json:"elevator,omitempty"`
+}
+
+// NearbyDTO asks for at most Limit houses within Radius meters of a point.
+type NearbyDTO struct {
+	Latitude  float64
+	Longitude float64
+	Radius    float64
+	Limit     int
 }
@@ -1,8 +1,14 @@
 package house
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/Polyrom/houses_api/internal/apierror"
 	"github.com/Polyrom/houses_api/internal/handlers"
@@ -15,6 +21,14 @@ import (
 const (
 	createURL    = "/house/create"
 	subscribeURL = "/house/{id}/subscribe"
+	nearbyURL    = "/houses/nearby"
+)
+
+const (
+	// maxNearbyRadius is in meters.
+	maxNearbyRadius    = 100_000
+	defaultNearbyLimit = 50
+	maxNearbyLimit     = 500
 )
 
 type handler struct {
@@ -30,15 +44,23 @@ func NewHandler(aumw middleware.Middleware, modmw middleware.Middleware, s *Serv
 
 func (h *handler) Register(r *mux.Router) {
 	r.Handle(createURL, h.modmw.DoInMiddle(http.HandlerFunc(h.Create))).Methods(http.MethodPost)
+	r.Handle(subscribeURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Subscribe))).Methods(http.MethodPost)
+	r.Handle(subscribeURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Unsubscribe))).Methods(http.MethodDelete)
+	r.Handle(nearbyURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Nearby))).Methods(http.MethodGet)
+}
+
+// RegisterDeprecated mounts only the routes that existed before /api/v1.
+func (h *handler) RegisterDeprecated(r *mux.Router) {
+	r.Handle(createURL, h.modmw.DoInMiddle(http.HandlerFunc(h.Create))).Methods(http.MethodPost)
 }
 
 func (h *handler) Create(w http.ResponseWriter, r *http.Request) {
-	reqID := r.Context().Value(middleware.ContextKeyRequestID).(string)
+	reqID := middleware.RequestID(r.Context())
 	var hdto CreateHouseDTO
-	err := json.NewDecoder(r.Body).Decode(&hdto)
+	code, err := handlers.DecodeJSON(r, &hdto)
 	if err != nil {
 		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
-		apierror.Write(w, err, reqID, http.StatusBadRequest)
+		apierror.Write(w, err, reqID, code)
 		return
 	}
 	validate := validator.New()
@@ -49,6 +71,11 @@ func (h *handler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	newHouse, err := h.s.Create(r.Context(), hdto)
+	if errors.Is(err, ErrDeveloperNotFound) {
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
 		apierror.Write(w, err, reqID, http.StatusInternalServerError)
@@ -62,3 +89,95 @@ func (h *handler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// Nearby lists houses around the point given by lat and lon within radius
+// meters, closest first.
+func (h *handler) Nearby(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestID(r.Context())
+	nq, err := parseNearby(r.URL.Query())
+	if err != nil {
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusBadRequest)
+		return
+	}
+	houses, err := h.s.Nearby(r.Context(), nq)
+	if err != nil {
+		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(houses)
+	if err != nil {
+		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusInternalServerError)
+		return
+	}
+}
+
+func parseNearby(q url.Values) (NearbyDTO, error) {
+	nq := NearbyDTO{Limit: defaultNearbyLimit}
+	params := []struct {
+		name     string
+		dst      *float64
+		min, max float64
+	}{
+		{"lat", &nq.Latitude, -90, 90},
+		{"lon", &nq.Longitude, -180, 180},
+		{"radius", &nq.Radius, 0, maxNearbyRadius},
+	}
+	for _, p := range params {
+		v, err := strconv.ParseFloat(q.Get(p.name), 64)
+		if err != nil || math.IsNaN(v) || v < p.min || v > p.max {
+			return NearbyDTO{}, fmt.Errorf("%s must be a number between %g and %g", p.name, p.min, p.max)
+		}
+		*p.dst = v
+	}
+	if nq.Radius == 0 {
+		return NearbyDTO{}, errors.New("radius must be positive")
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxNearbyLimit {
+			return NearbyDTO{}, errors.New("limit must be between 1 and " + strconv.Itoa(maxNearbyLimit))
+		}
+		nq.Limit = n
+	}
+	return nq, nil
+}
+
+// Subscribe makes the user receive events of the house in the event stream.
+func (h *handler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	h.changeSubscription(w, r, h.s.Subscribe)
+}
+
+func (h *handler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	h.changeSubscription(w, r, h.s.Unsubscribe)
+}
+
+func (h *handler) changeSubscription(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, hid HouseID) error) {
+	reqID := middleware.RequestID(r.Context())
+	hid, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || hid < 1 {
+		invalidIDErr := errors.New("invalid house id")
+		h.l.Errorf("bad request req_id=%s: %v", reqID, invalidIDErr)
+		apierror.Write(w, invalidIDErr, reqID, http.StatusBadRequest)
+		return
+	}
+	err = change(r.Context(), HouseID(hid))
+	switch {
+	case errors.Is(err, ErrNotFound):
+		h.l.Errorf("house not found req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusNotFound)
+		return
+	case errors.Is(err, ErrNotAuthenticated):
+		h.l.Errorf("unauthorized req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusUnauthorized)
+		return
+	case err != nil:
+		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
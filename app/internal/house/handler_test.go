@@ -0,0 +1,101 @@
+package house
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Polyrom/houses_api/internal/middleware/middlewaretest"
+	"github.com/gorilla/mux"
+)
+
+type MockLogger struct{}
+
+func (ml *MockLogger) Trace(args ...interface{})                   {}
+func (ml *MockLogger) Debug(args ...interface{})                   {}
+func (ml *MockLogger) Info(args ...interface{})                    {}
+func (ml *MockLogger) Warn(args ...interface{})                    {}
+func (ml *MockLogger) Warning(args ...interface{})                 {}
+func (ml *MockLogger) Error(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   {}
+func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
+func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
+func (ml *MockLogger) Infof(format string, args ...interface{})    {}
+func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
+func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
+func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
+func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
+
+type MockHouseRepo struct {
+	Repository
+	// query records the last Nearby call
+	query NearbyDTO
+}
+
+func (mr *MockHouseRepo) Nearby(ctx context.Context, q NearbyDTO) ([]NearbyHouse, error) {
+	mr.query = q
+	return []NearbyHouse{{House: House{ID: 1, Address: "Lenina 1"}, Distance: 120.5}}, nil
+}
+
+func TestParseNearby(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    NearbyDTO
+		wantErr bool
+	}{
+		{name: "default limit", query: "lat=55.75&lon=37.62&radius=1500", want: NearbyDTO{Latitude: 55.75, Longitude: 37.62, Radius: 1500, Limit: defaultNearbyLimit}},
+		{name: "limit", query: "lat=-33.9&lon=151.2&radius=10&limit=5", want: NearbyDTO{Latitude: -33.9, Longitude: 151.2, Radius: 10, Limit: 5}},
+		{name: "missing lat", query: "lon=37.62&radius=1500", wantErr: true},
+		{name: "lat out of range", query: "lat=91&lon=37.62&radius=1500", wantErr: true},
+		{name: "lon out of range", query: "lat=55.75&lon=-181&radius=1500", wantErr: true},
+		{name: "not a number", query: "lat=NaN&lon=37.62&radius=1500", wantErr: true},
+		{name: "zero radius", query: "lat=55.75&lon=37.62&radius=0", wantErr: true},
+		{name: "radius too large", query: "lat=55.75&lon=37.62&radius=100001", wantErr: true},
+		{name: "limit too large", query: "lat=55.75&lon=37.62&radius=1500&limit=501", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, _ := url.ParseQuery(tt.query)
+			got, err := parseNearby(q)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseNearby() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseNearby() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_Nearby(t *testing.T) {
+	repo := &MockHouseRepo{}
+	router := mux.NewRouter()
+	NewHandler(middlewaretest.Auth{}, middlewaretest.Auth{}, NewService(repo, &MockLogger{}), &MockLogger{}).Register(router)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/houses/nearby?lat=55.75&lon=37.62&radius=500", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("code = %d, want 200: %s", rr.Code, rr.Body)
+	}
+	var got []NearbyHouse
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 1 || got[0].Distance != 120.5 {
+		t.Errorf("houses = %+v", got)
+	}
+	if repo.query.Radius != 500 {
+		t.Errorf("repo queried with %+v", repo.query)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/houses/nearby?lat=55.75&lon=37.62", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("without radius code = %d, want 400", rr.Code)
+	}
+}
@@ -2,11 +2,29 @@ package house
 
 import "time"
 
+type HouseID int
+
+// House carries the name of its developer along with the developer ID.
 type House struct {
-	ID        int       `json:"id"`
-	Address   string    `json:"address"`
-	Year      int       `json:"year"`
-	Developer string    `json:"developer,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdateAt  time.Time `json:"update_at"`
+	ID          int       `json:"id"`
+	Address     string    `json:"address"`
+	Year        int       `json:"year"`
+	DeveloperID *int      `json:"developer_id,omitempty"`
+	Developer   string    `json:"developer,omitempty"`
+	Floors      *int      `json:"floors,omitempty"`
+	Material    *string   `json:"material,omitempty"`
+	Latitude    *float64  `json:"latitude,omitempty"`
+	Longitude   *float64  `json:"longitude,omitempty"`
+	City        *string   `json:"city,omitempty"`
+	District    *string   `json:"district,omitempty"`
+	Parking     bool      `json:"parking"`
+	Elevator    bool      `json:"elevator"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdateAt    time.Time `json:"update_at"`
+}
+
+// NearbyHouse is a house found by Nearby with its distance in meters.
+type NearbyHouse struct {
+	House
+	Distance float64 `json:"distance"`
 }
@@ -2,31 +2,77 @@ package house
 
 import (
 	"context"
+	"math"
+	"strings"
+	"sync"
 
 	"errors"
 
 	"github.com/Polyrom/houses_api/pkg/client/postgres"
 	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
+const foreignKeyViolation = "23503"
+
+// earthRadius is the mean radius of the Earth in meters.
+const earthRadius = 6371008.8
+
+// Columns are read by Scan in this order. They expect the houses as h
+// joined with their developers as d, like FromHouses does, so that other
+// repositories can read houses too.
+const Columns = `h.id, h.address, h.year, h.developer_id, COALESCE(d.name, ''), h.floors, h.material, h.latitude, h.longitude, h.city, h.district, h.parking, h.elevator, h.created_at, h.update_at`
+
+// FromHouses joins the houses with their developers for Columns.
+const FromHouses = `houses AS h LEFT JOIN developers AS d ON d.id = h.developer_id`
+
+// Scan reads Columns into h and the columns selected after them into extra.
+func Scan(row pgx.Row, h *House, extra ...any) error {
+	dest := []any{&h.ID, &h.Address, &h.Year, &h.DeveloperID, &h.Developer, &h.Floors, &h.Material, &h.Latitude,
+		&h.Longitude, &h.City, &h.District, &h.Parking, &h.Elevator, &h.CreatedAt, &h.UpdateAt}
+	return row.Scan(append(dest, extra...)...)
+}
+
 type repository struct {
 	client postgres.Client
 	logger logging.Logger
+
+	// earthMu guards the check whether the earthdistance extension is
+	// installed, otherwise Nearby computes haversine distances itself.
+	earthMu      sync.Mutex
+	earthChecked bool
+	earth        bool
 }
 
 func (r *repository) Create(ctx context.Context, h CreateHouseDTO) (House, error) {
-	q := `INSERT INTO houses 
-					(address, year, developer) 
-				VALUES 
-					($1, $2, $3)
-				RETURNING 
-					id, address, year, developer, created_at, update_at`
+	developerID := h.DeveloperID
+	if developerID == nil && strings.TrimSpace(h.Developer) != "" {
+		id, err := r.developerID(ctx, h.Developer)
+		if err != nil {
+			return House{}, err
+		}
+		developerID = &id
+	}
+	q := `WITH h AS (
+					INSERT INTO houses 
+						(address, year, developer_id, floors, material, latitude, longitude, city, district, parking, elevator) 
+					VALUES 
+						($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+					RETURNING *
+				)
+				SELECT 
+					` + Columns + `
+				FROM 
+					h LEFT JOIN developers AS d ON d.id = h.developer_id`
 	var nh House
-	err := r.client.QueryRow(ctx, q, h.Address, h.Year, h.Developer).
-		Scan(&nh.ID, &nh.Address, &nh.Year, &nh.Developer, &nh.CreatedAt, &nh.UpdateAt)
+	err := Scan(r.client.QueryRow(ctx, q, h.Address, h.Year, developerID, h.Floors, h.Material,
+		h.Latitude, h.Longitude, h.City, h.District, h.Parking, h.Elevator), &nh)
 	if err != nil {
 		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return House{}, ErrDeveloperNotFound
+		}
 		if errors.Is(err, pgErr) {
 			pgErr = err.(*pgconn.PgError)
 			r.logger.Errorf("SQL Error: %s, Detail: %s, Where: %s", pgErr.Message, pgErr.Detail, pgErr.Where)
@@ -37,6 +83,153 @@ func (r *repository) Create(ctx context.Context, h CreateHouseDTO) (House, error
 	return nh, nil
 }
 
+// developerID returns the developer whose key matches the name, creating
+// the developer if there is none.
+func (r *repository) developerID(ctx context.Context, name string) (int, error) {
+	q := `INSERT INTO developers 
+					(name) 
+				VALUES 
+					(btrim($1))
+				ON CONFLICT (key) DO UPDATE SET name = developers.name
+				RETURNING id`
+	var id int
+	err := r.client.QueryRow(ctx, q, name).Scan(&id)
+	return id, err
+}
+
+func (r *repository) Nearby(ctx context.Context, nq NearbyDTO) ([]NearbyHouse, error) {
+	var q string
+	var args []any
+	if r.hasEarth(ctx) {
+		q = `SELECT 
+					` + Columns + `, distance
+				FROM (
+					SELECT 
+						*, earth_distance(ll_to_earth($1, $2), ll_to_earth(latitude, longitude)) AS distance
+					FROM 
+						houses
+					WHERE latitude IS NOT NULL
+					AND earth_box(ll_to_earth($1, $2), $3) @> ll_to_earth(latitude, longitude)
+				) AS h LEFT JOIN developers AS d ON d.id = h.developer_id
+				WHERE distance <= $3
+				ORDER BY distance, h.id
+				LIMIT $4`
+		args = []any{nq.Latitude, nq.Longitude, nq.Radius, nq.Limit}
+	} else {
+		minLat, maxLat, minLon, maxLon := boundingBox(nq.Latitude, nq.Longitude, nq.Radius)
+		q = `SELECT 
+					` + Columns + `, distance
+				FROM (
+					SELECT 
+						*, 2 * $3::float8 * asin(least(1, sqrt(
+							power(sin(radians(latitude - $1) / 2), 2) +
+							cos(radians($1)) * cos(radians(latitude)) * power(sin(radians(longitude - $2) / 2), 2)
+						))) AS distance
+					FROM 
+						houses
+					WHERE latitude BETWEEN $5 AND $6
+					AND longitude BETWEEN $7 AND $8
+				) AS h LEFT JOIN developers AS d ON d.id = h.developer_id
+				WHERE distance <= $4
+				ORDER BY distance, h.id
+				LIMIT $9`
+		args = []any{nq.Latitude, nq.Longitude, earthRadius, nq.Radius, minLat, maxLat, minLon, maxLon, nq.Limit}
+	}
+	rows, err := r.client.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	hs := make([]NearbyHouse, 0)
+	for rows.Next() {
+		var h NearbyHouse
+		if err = Scan(rows, &h.House, &h.Distance); err != nil {
+			return nil, err
+		}
+		hs = append(hs, h)
+	}
+	return hs, rows.Err()
+}
+
+// boundingBox returns the coordinates of a box around the circle, so that
+// the index can narrow the houses before distances are computed. Longitude
+// is not limited when the circle reaches a pole or the antimeridian.
+func boundingBox(lat, lon, radius float64) (minLat, maxLat, minLon, maxLon float64) {
+	d := radius / earthRadius * 180 / math.Pi
+	minLat, maxLat = lat-d, lat+d
+	minLon, maxLon = -180, 180
+	if minLat <= -90 || maxLat >= 90 {
+		return math.Max(minLat, -90), math.Min(maxLat, 90), minLon, maxLon
+	}
+	dLon := math.Asin(math.Sin(radius/earthRadius)/math.Cos(lat*math.Pi/180)) * 180 / math.Pi
+	if lon-dLon >= -180 && lon+dLon <= 180 {
+		minLon, maxLon = lon-dLon, lon+dLon
+	}
+	return minLat, maxLat, minLon, maxLon
+}
+
+func (r *repository) Subscribe(ctx context.Context, hid HouseID, uid string) error {
+	q := `INSERT INTO house_subscriptions
+					(house_id, user_id)
+				VALUES
+					($1, $2)
+				ON CONFLICT DO NOTHING`
+	_, err := r.client.Exec(ctx, q, hid, uid)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *repository) Unsubscribe(ctx context.Context, hid HouseID, uid string) error {
+	q := `DELETE FROM house_subscriptions
+				WHERE house_id = $1
+				AND user_id = $2`
+	_, err := r.client.Exec(ctx, q, hid, uid)
+	return err
+}
+
+func (r *repository) SubscribedHouseIDs(ctx context.Context, uid string) ([]HouseID, error) {
+	q := `SELECT house_id FROM house_subscriptions WHERE user_id = $1`
+	rows, err := r.client.Query(ctx, q, uid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make([]HouseID, 0)
+	for rows.Next() {
+		var id HouseID
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// hasEarth tells whether the earthdistance extension is installed. The
+// answer is kept once known; a failed check, e.g. of a cancelled request,
+// falls back to haversine and is repeated on the next call.
+func (r *repository) hasEarth(ctx context.Context) bool {
+	r.earthMu.Lock()
+	defer r.earthMu.Unlock()
+	if r.earthChecked {
+		return r.earth
+	}
+	q := `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'earthdistance')`
+	if err := r.client.QueryRow(ctx, q).Scan(&r.earth); err != nil {
+		r.logger.Errorf("failed to check for earthdistance, using haversine: %v", err)
+		r.earth = false
+		return false
+	}
+	r.earthChecked = true
+	return r.earth
+}
+
 func NewRepository(c postgres.Client, l logging.Logger) Repository {
 	return &repository{
 		client: c,
@@ -0,0 +1,97 @@
+package house
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/Polyrom/houses_api/pkg/client/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+// haversine is the distance the fallback query computes, in meters.
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	a := math.Pow(math.Sin((lat2-lat1)*rad/2), 2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Pow(math.Sin((lon2-lon1)*rad/2), 2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func TestBoundingBox(t *testing.T) {
+	tests := []struct {
+		name      string
+		lat, lon  float64
+		radius    float64
+		wholeLons bool
+	}{
+		{name: "Moscow", lat: 55.75, lon: 37.62, radius: 5000},
+		{name: "equator", lat: 0, lon: 0, radius: 100_000},
+		{name: "southern hemisphere", lat: -33.87, lon: 151.21, radius: 20_000},
+		{name: "antimeridian", lat: 64.73, lon: 179.5, radius: 100_000, wholeLons: true},
+		{name: "pole", lat: 89.9, lon: 10, radius: 50_000, wholeLons: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minLat, maxLat, minLon, maxLon := boundingBox(tt.lat, tt.lon, tt.radius)
+			if wholeLons := minLon == -180 && maxLon == 180; wholeLons != tt.wholeLons {
+				t.Fatalf("longitudes %v..%v, want whole range %v", minLon, maxLon, tt.wholeLons)
+			}
+			// points on the circle must fall into the box
+			for deg := 0.0; deg < 360; deg += 5 {
+				b := deg * math.Pi / 180
+				d := tt.radius / earthRadius
+				lat1, lon1 := tt.lat*math.Pi/180, tt.lon*math.Pi/180
+				lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(b))
+				lon2 := lon1 + math.Atan2(math.Sin(b)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+				plat, plon := lat2*180/math.Pi, math.Remainder(lon2*180/math.Pi, 360)
+				if dist := haversine(tt.lat, tt.lon, plat, plon); math.Abs(dist-tt.radius) > 1 {
+					t.Fatalf("test point is %v m away, want %v", dist, tt.radius)
+				}
+				const eps = 1e-9
+				if plat < minLat-eps || plat > maxLat+eps || plon < minLon-eps || plon > maxLon+eps {
+					t.Errorf("point %v,%v at bearing %v is outside %v..%v, %v..%v", plat, plon, deg, minLat, maxLat, minLon, maxLon)
+				}
+			}
+		})
+	}
+}
+
+type earthRow struct{ err error }
+
+func (er earthRow) Scan(dest ...any) error {
+	if er.err != nil {
+		return er.err
+	}
+	*dest[0].(*bool) = true
+	return nil
+}
+
+// earthClient fails the extension check until ok is set.
+type earthClient struct {
+	postgres.Client
+	ok    bool
+	calls int
+}
+
+func (ec *earthClient) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	ec.calls++
+	if !ec.ok {
+		return earthRow{err: context.Canceled}
+	}
+	return earthRow{}
+}
+
+func TestRepository_HasEarthRetriesFailedCheck(t *testing.T) {
+	c := &earthClient{}
+	r := &repository{client: c, logger: &MockLogger{}}
+	if r.hasEarth(context.Background()) {
+		t.Fatal("failed check reported earthdistance")
+	}
+	c.ok = true
+	if !r.hasEarth(context.Background()) || !r.hasEarth(context.Background()) {
+		t.Error("earthdistance not detected after a failed check")
+	}
+	if c.calls != 2 {
+		t.Errorf("checked %d times, want 2", c.calls)
+	}
+}
@@ -2,10 +2,18 @@ package house
 
 import (
 	"context"
+	"errors"
 
+	"github.com/Polyrom/houses_api/internal/middleware"
 	"github.com/Polyrom/houses_api/pkg/logging"
 )
 
+var (
+	ErrNotFound          = errors.New("house not found")
+	ErrNotAuthenticated  = errors.New("user not authenticated")
+	ErrDeveloperNotFound = errors.New("developer not found")
+)
+
 type Service struct {
 	repo   Repository
 	logger logging.Logger
@@ -15,6 +23,33 @@ func (s *Service) Create(ctx context.Context, h CreateHouseDTO) (House, error) {
 	return s.repo.Create(ctx, h)
 }
 
+// Nearby finds houses within the radius of the point, closest first.
+func (s *Service) Nearby(ctx context.Context, q NearbyDTO) ([]NearbyHouse, error) {
+	return s.repo.Nearby(ctx, q)
+}
+
+// Subscribe makes the current user follow events of the house.
+func (s *Service) Subscribe(ctx context.Context, hid HouseID) error {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		return ErrNotAuthenticated
+	}
+	return s.repo.Subscribe(ctx, hid, userID)
+}
+
+func (s *Service) Unsubscribe(ctx context.Context, hid HouseID) error {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		return ErrNotAuthenticated
+	}
+	return s.repo.Unsubscribe(ctx, hid, userID)
+}
+
+// SubscribedHouseIDs lists houses the user follows.
+func (s *Service) SubscribedHouseIDs(ctx context.Context, uid string) ([]HouseID, error) {
+	return s.repo.SubscribedHouseIDs(ctx, uid)
+}
+
 func NewService(r Repository, l logging.Logger) *Service {
 	return &Service{repo: r, logger: l}
 }
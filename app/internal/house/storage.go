@@ -6,4 +6,9 @@ import (
 
 type Repository interface {
 	Create(ctx context.Context, h CreateHouseDTO) (House, error)
+	// Nearby returns houses with coordinates ordered by distance.
+	Nearby(ctx context.Context, q NearbyDTO) ([]NearbyHouse, error)
+	Subscribe(ctx context.Context, hid HouseID, uid string) error
+	Unsubscribe(ctx context.Context, hid HouseID, uid string) error
+	SubscribedHouseIDs(ctx context.Context, uid string) ([]HouseID, error)
 }
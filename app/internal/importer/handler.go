@@ -0,0 +1,100 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/handlers"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/gorilla/mux"
+)
+
+const (
+	importHousesURL = "/import/houses"
+	importFlatsURL  = "/import/flats"
+)
+
+// formats maps accepted Content-Types to file formats.
+var formats = map[string]Format{
+	"text/csv":             FormatCSV,
+	"application/x-ndjson": FormatJSONL,
+	"application/jsonl":    FormatJSONL,
+}
+
+type handler struct {
+	modmw middleware.Middleware
+	s     *Service
+	l     logging.Logger
+}
+
+func NewHandler(modmw middleware.Middleware, s *Service, l logging.Logger) handlers.Handler {
+	return &handler{modmw: modmw, s: s, l: l}
+}
+
+func (h *handler) Register(r *mux.Router) {
+	r.Handle(importHousesURL, h.modmw.DoInMiddle(h.Import(KindHouses))).Methods(http.MethodPost)
+	r.Handle(importFlatsURL, h.modmw.DoInMiddle(h.Import(KindFlats))).Methods(http.MethodPost)
+}
+
+// Import responds with the report: 200 if the rows went in (or would in a dry
+// run, or some did in best-effort mode) and 422 if an atomic import was
+// rejected because of invalid rows.
+func (h *handler) Import(kind Kind) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.importFile(w, r, kind)
+	}
+}
+
+func (h *handler) importFile(w http.ResponseWriter, r *http.Request, kind Kind) {
+	reqID := middleware.RequestID(r.Context())
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	format, ok := formats[mediaType]
+	if !ok {
+		err := errors.New("Content-Type must be text/csv or application/x-ndjson")
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusUnsupportedMediaType)
+		return
+	}
+	q := r.URL.Query()
+	opts := Options{Format: format, Mode: Mode(q.Get("mode"))}
+	if v := q.Get("dry_run"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			err = fmt.Errorf("invalid dry_run %q", v)
+			h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+			apierror.Write(w, err, reqID, http.StatusBadRequest)
+			return
+		}
+		opts.DryRun = dryRun
+	}
+	rep, err := h.s.Import(r.Context(), kind, r.Body, opts)
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		err = fmt.Errorf("request body must not be larger than %d bytes", maxBytesErr.Limit)
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusRequestEntityTooLarge)
+		return
+	case errors.Is(err, ErrBadInput):
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusBadRequest)
+		return
+	case err != nil:
+		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if rep.Mode == ModeAtomic && !rep.OK() {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	if err = json.NewEncoder(w).Encode(rep); err != nil {
+		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+	}
+}
@@ -0,0 +1,78 @@
+package importer
+
+import (
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/house"
+)
+
+// Kind is what a file imports.
+type Kind string
+
+const (
+	KindHouses Kind = "houses"
+	KindFlats  Kind = "flats"
+)
+
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+)
+
+// Mode decides what happens when some rows are invalid.
+type Mode string
+
+const (
+	// ModeAtomic inserts every row or, if any of them fails, none.
+	ModeAtomic Mode = "atomic"
+	// ModeBestEffort inserts the valid rows and reports the rest.
+	ModeBestEffort Mode = "best_effort"
+)
+
+type Options struct {
+	Format Format
+	Mode   Mode
+	// DryRun validates the file without inserting anything.
+	DryRun bool
+}
+
+// RowResult is the outcome of one row. Line is the line of the row in the
+// file; ID is set for inserted rows.
+type RowResult struct {
+	Line   int      `json:"line"`
+	ID     int      `json:"id,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type Report struct {
+	Kind     Kind        `json:"kind"`
+	Mode     Mode        `json:"mode"`
+	DryRun   bool        `json:"dry_run"`
+	Total    int         `json:"total"`
+	Valid    int         `json:"valid"`
+	Inserted int         `json:"inserted"`
+	Failed   int         `json:"failed"`
+	Rows     []RowResult `json:"rows"`
+}
+
+// OK reports whether the import went through without any failed row.
+func (r Report) OK() bool {
+	return r.Failed == 0
+}
+
+type HouseRow struct {
+	ID int
+	house.CreateHouseDTO
+}
+
+type FlatRow struct {
+	ID int
+	flat.CreateFlatDTO
+}
+
+// FlatNumber is the natural key of a flat.
+type FlatNumber struct {
+	HouseID int
+	Number  int
+}
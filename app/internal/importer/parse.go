@@ -0,0 +1,225 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/house"
+)
+
+const maxLineBytes = 1 << 20
+
+var ErrBadInput = errors.New("invalid import file")
+
+func badInput(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrBadInput, fmt.Sprintf(format, args...))
+}
+
+// record is a parsed row; errors collects everything wrong with it.
+type record[T any] struct {
+	line   int
+	dto    T
+	errors []string
+}
+
+// fields maps CSV column names to setters of the DTO field.
+type fields[T any] map[string]func(dto *T, v string) error
+
+var houseFields = fields[house.CreateHouseDTO]{
+	"address":      func(h *house.CreateHouseDTO, v string) error { h.Address = v; return nil },
+	"year":         intField(func(h *house.CreateHouseDTO) *int { return &h.Year }),
+	"developer_id": optional(func(h *house.CreateHouseDTO) **int { return &h.DeveloperID }, strconv.Atoi, "an integer"),
+	"developer":    func(h *house.CreateHouseDTO, v string) error { h.Developer = v; return nil },
+	"floors":       optional(func(h *house.CreateHouseDTO) **int { return &h.Floors }, strconv.Atoi, "an integer"),
+	"material":     optional(func(h *house.CreateHouseDTO) **string { return &h.Material }, parseString, ""),
+	"latitude":     optional(func(h *house.CreateHouseDTO) **float64 { return &h.Latitude }, parseFloat, "a number"),
+	"longitude":    optional(func(h *house.CreateHouseDTO) **float64 { return &h.Longitude }, parseFloat, "a number"),
+	"city":         optional(func(h *house.CreateHouseDTO) **string { return &h.City }, parseString, ""),
+	"district":     optional(func(h *house.CreateHouseDTO) **string { return &h.District }, parseString, ""),
+	"parking":      boolField(func(h *house.CreateHouseDTO) *bool { return &h.Parking }),
+	"elevator":     boolField(func(h *house.CreateHouseDTO) *bool { return &h.Elevator }),
+}
+
+var flatFields = fields[flat.CreateFlatDTO]{
+	"house_id":     intField(func(f *flat.CreateFlatDTO) *int { return &f.HouseID }),
+	"number":       intField(func(f *flat.CreateFlatDTO) *int { return &f.Number }),
+	"price":        intField(func(f *flat.CreateFlatDTO) *int { return &f.Price }),
+	"rooms":        intField(func(f *flat.CreateFlatDTO) *int { return &f.Rooms }),
+	"floor":        optional(func(f *flat.CreateFlatDTO) **int { return &f.Floor }, strconv.Atoi, "an integer"),
+	"total_area":   optional(func(f *flat.CreateFlatDTO) **float64 { return &f.TotalArea }, parseFloat, "a number"),
+	"living_area":  optional(func(f *flat.CreateFlatDTO) **float64 { return &f.LivingArea }, parseFloat, "a number"),
+	"kitchen_area": optional(func(f *flat.CreateFlatDTO) **float64 { return &f.KitchenArea }, parseFloat, "a number"),
+	"balcony":      boolField(func(f *flat.CreateFlatDTO) *bool { return &f.Balcony }),
+	"layout":       optional(func(f *flat.CreateFlatDTO) **string { return &f.Layout }, parseString, ""),
+}
+
+func parseFloat(v string) (float64, error) {
+	return strconv.ParseFloat(v, 64)
+}
+
+func parseString(v string) (string, error) {
+	return v, nil
+}
+
+// intField parses an integer column, an empty value leaves the field zero.
+func intField[T any](field func(dto *T) *int) func(dto *T, v string) error {
+	return func(dto *T, v string) error {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		*field(dto) = n
+		return nil
+	}
+}
+
+// boolField parses a boolean column, an empty value leaves the field false.
+func boolField[T any](field func(dto *T) *bool) func(dto *T, v string) error {
+	return func(dto *T, v string) error {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return nil
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.New("must be a boolean")
+		}
+		*field(dto) = b
+		return nil
+	}
+}
+
+// optional parses a nullable column, an empty value leaves the field nil.
+func optional[T, V any](field func(dto *T) **V, parse func(v string) (V, error), what string) func(dto *T, v string) error {
+	return func(dto *T, v string) error {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return nil
+		}
+		val, err := parse(v)
+		if err != nil {
+			return fmt.Errorf("must be %s", what)
+		}
+		*field(dto) = &val
+		return nil
+	}
+}
+
+func parse[T any](r io.Reader, format Format, fs fields[T]) ([]record[T], error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r, fs)
+	case FormatJSONL:
+		return readJSONL[T](r)
+	}
+	return nil, badInput("unknown format %q", format)
+}
+
+// readCSV reads a CSV file with a header row naming the columns in any order.
+// Missing columns are left to validation.
+func readCSV[T any](r io.Reader, fs fields[T]) ([]record[T], error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, badInput("file is empty")
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	setters := make([]func(dto *T, v string) error, len(header))
+	for i, name := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(name))
+		set, ok := fs[header[i]]
+		if !ok {
+			return nil, badInput("unknown column %q", name)
+		}
+		for _, prev := range header[:i] {
+			if prev == header[i] {
+				return nil, badInput("duplicate column %q", name)
+			}
+		}
+		setters[i] = set
+	}
+	recs := make([]record[T], 0)
+	for {
+		values, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return recs, nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount) {
+			recs = append(recs, record[T]{
+				line:   parseErr.StartLine,
+				errors: []string{fmt.Sprintf("expected %d fields, got %d", len(header), len(values))},
+			})
+			continue
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+		line, _ := cr.FieldPos(0)
+		rec := record[T]{line: line}
+		for i, v := range values {
+			if err = setters[i](&rec.dto, v); err != nil {
+				rec.errors = append(rec.errors, fmt.Sprintf("%s: %v", header[i], err))
+			}
+		}
+		recs = append(recs, rec)
+	}
+}
+
+// csvError reports malformed CSV as bad input and passes read errors, such
+// as a body over the size limit, through.
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return badInput("%v", err)
+	}
+	return err
+}
+
+// readJSONL reads one JSON object per line, blank lines are skipped.
+func readJSONL[T any](r io.Reader) ([]record[T], error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	recs := make([]record[T], 0)
+	for line := 1; sc.Scan(); line++ {
+		b := bytes.TrimSpace(sc.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		rec := record[T]{line: line}
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&rec.dto); err != nil {
+			rec.errors = append(rec.errors, strings.TrimPrefix(err.Error(), "json: "))
+		} else if dec.More() {
+			rec.errors = append(rec.errors, "line must contain a single JSON object")
+		}
+		recs = append(recs, rec)
+	}
+	if err := sc.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, badInput("line longer than %d bytes", maxLineBytes)
+		}
+		return nil, err
+	}
+	if len(recs) == 0 {
+		return nil, badInput("file is empty")
+	}
+	return recs, nil
+}
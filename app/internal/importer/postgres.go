@@ -0,0 +1,217 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/pkg/client/postgres"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const uniqueViolation = "23505"
+
+const (
+	housesTable = "houses"
+	flatsTable  = "flats"
+)
+
+type repository struct {
+	client postgres.TxClient
+	logger logging.Logger
+}
+
+func (r *repository) ReserveIDs(ctx context.Context, table string, n int) ([]int, error) {
+	q := `SELECT nextval(pg_get_serial_sequence($1, 'id')) FROM generate_series(1, $2)`
+	rows, err := r.client.Query(ctx, q, table, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make([]int, 0, n)
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *repository) ExistingHouseIDs(ctx context.Context, ids []int) (map[int]bool, error) {
+	return r.existingIDs(ctx, `SELECT id FROM houses WHERE id = ANY($1)`, ids)
+}
+
+func (r *repository) ExistingDeveloperIDs(ctx context.Context, ids []int) (map[int]bool, error) {
+	return r.existingIDs(ctx, `SELECT id FROM developers WHERE id = ANY($1)`, ids)
+}
+
+func (r *repository) existingIDs(ctx context.Context, q string, ids []int) (map[int]bool, error) {
+	rows, err := r.client.Query(ctx, q, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	existing := make(map[int]bool, len(ids))
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		existing[id] = true
+	}
+	return existing, rows.Err()
+}
+
+func (r *repository) TakenFlatNumbers(ctx context.Context, houseIDs []int) (map[FlatNumber]bool, error) {
+	q := `SELECT house_id, number FROM flats WHERE house_id = ANY($1)`
+	rows, err := r.client.Query(ctx, q, houseIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	taken := make(map[FlatNumber]bool)
+	for rows.Next() {
+		var fn FlatNumber
+		if err = rows.Scan(&fn.HouseID, &fn.Number); err != nil {
+			return nil, err
+		}
+		taken[fn] = true
+	}
+	return taken, rows.Err()
+}
+
+func (r *repository) CopyHouses(ctx context.Context, batches [][]HouseRow) error {
+	columns := []string{"id", "address", "year", "developer_id", "floors", "material",
+		"latitude", "longitude", "city", "district", "parking", "elevator"}
+	names := make([]string, 0)
+	for _, b := range batches {
+		for _, h := range b {
+			if h.DeveloperID == nil && strings.TrimSpace(h.Developer) != "" {
+				names = append(names, h.Developer)
+			}
+		}
+	}
+	var developerIDs map[string]int
+	resolve := func(ctx context.Context, tx pgx.Tx) error {
+		var err error
+		developerIDs, err = resolveDevelopers(ctx, tx, names)
+		return err
+	}
+	return r.copy(ctx, housesTable, columns, resolve, len(batches), func(i int) pgx.CopyFromSource {
+		return pgx.CopyFromSlice(len(batches[i]), func(j int) ([]any, error) {
+			h := batches[i][j]
+			developerID := h.DeveloperID
+			if id, ok := developerIDs[h.Developer]; ok && developerID == nil {
+				developerID = &id
+			}
+			return []any{h.ID, h.Address, h.Year, developerID, h.Floors, h.Material,
+				h.Latitude, h.Longitude, h.City, h.District, h.Parking, h.Elevator}, nil
+		})
+	})
+}
+
+func (r *repository) CopyFlats(ctx context.Context, batches [][]FlatRow) error {
+	columns := []string{"id", "house_id", "number", "price", "rooms", "floor",
+		"total_area", "living_area", "kitchen_area", "balcony", "layout"}
+	err := r.copy(ctx, flatsTable, columns, nil, len(batches), func(i int) pgx.CopyFromSource {
+		return pgx.CopyFromSlice(len(batches[i]), func(j int) ([]any, error) {
+			f := batches[i][j]
+			// a NULL number is filled in by the trigger
+			var number *int
+			if f.Number != 0 {
+				number = &f.Number
+			}
+			return []any{f.ID, f.HouseID, number, f.Price, f.Rooms, f.Floor,
+				f.TotalArea, f.LivingArea, f.KitchenArea, f.Balcony, f.Layout}, nil
+		})
+	})
+	// The numbers were checked before the transaction, a flat created in
+	// the meantime can still take one of them.
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return flat.ErrNumberTaken
+	}
+	return err
+}
+
+// resolveDevelopers creates the developers missing for names and returns
+// the developer ID of every name.
+func resolveDevelopers(ctx context.Context, tx pgx.Tx, names []string) (map[string]int, error) {
+	ids := make(map[string]int, len(names))
+	if len(names) == 0 {
+		return ids, nil
+	}
+	q := `INSERT INTO developers 
+					(name)
+				SELECT DISTINCT ON (developer_key(n)) 
+					btrim(n)
+				FROM 
+					unnest($1::text[]) AS n
+				ORDER BY developer_key(n), n
+				ON CONFLICT (key) DO NOTHING`
+	if _, err := tx.Exec(ctx, q, names); err != nil {
+		return nil, err
+	}
+	q = `SELECT 
+					n, d.id
+				FROM 
+					unnest($1::text[]) AS n JOIN developers AS d ON d.key = developer_key(n)`
+	rows, err := tx.Query(ctx, q, names)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var id int
+		if err = rows.Scan(&name, &id); err != nil {
+			return nil, err
+		}
+		ids[name] = id
+	}
+	return ids, rows.Err()
+}
+
+// copy runs before, if given, and then one COPY per batch in a transaction.
+func (r *repository) copy(ctx context.Context, table string, columns []string, before func(ctx context.Context, tx pgx.Tx) error, n int, batch func(i int) pgx.CopyFromSource) error {
+	tx, err := r.client.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err = postgres.Tag(ctx, tx); err != nil {
+		return err
+	}
+	if before != nil {
+		if err = before(ctx, tx); err != nil {
+			r.logError(err)
+			return err
+		}
+	}
+	for i := 0; i < n; i++ {
+		if _, err = tx.CopyFrom(ctx, pgx.Identifier{table}, columns, batch(i)); err != nil {
+			r.logError(err)
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *repository) logError(err error) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		r.logger.Errorf("SQL Error: %s, Detail: %s, Where: %s", pgErr.Message, pgErr.Detail, pgErr.Where)
+	}
+}
+
+func NewRepository(c postgres.TxClient, l logging.Logger) Repository {
+	return &repository{
+		client: c,
+		logger: l,
+	}
+}
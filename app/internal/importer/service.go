@@ -0,0 +1,318 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/house"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/go-playground/validator/v10"
+)
+
+const batchSize = 1000
+
+type Service struct {
+	repo      Repository
+	validate  *validator.Validate
+	observers []Observer
+	logger    logging.Logger
+}
+
+// Observer is notified after rows have been inserted, e.g. to drop caches
+// built from them.
+type Observer interface {
+	OnImport(ctx context.Context, kind Kind, inserted int)
+}
+
+// AddObserver registers o for all subsequent imports. It is not safe to call
+// while requests are served.
+func (s *Service) AddObserver(o Observer) {
+	s.observers = append(s.observers, o)
+}
+
+// Import reads houses or flats from r and inserts them according to opts.
+// Rows are validated with the same rules as the create endpoints; flats must
+// also refer to existing houses. Problems with single rows end up in the
+// report, a file that cannot be read at all is an ErrBadInput error.
+//
+// Imported rows do not notify flat observers, import observers are told
+// once the rows are inserted.
+func (s *Service) Import(ctx context.Context, kind Kind, r io.Reader, opts Options) (Report, error) {
+	rep, err := s.importRows(ctx, kind, r, opts)
+	if err == nil && rep.Inserted > 0 {
+		for _, o := range s.observers {
+			o.OnImport(ctx, kind, rep.Inserted)
+		}
+	}
+	return rep, err
+}
+
+func (s *Service) importRows(ctx context.Context, kind Kind, r io.Reader, opts Options) (Report, error) {
+	if opts.Mode == "" {
+		opts.Mode = ModeAtomic
+	}
+	if opts.Mode != ModeAtomic && opts.Mode != ModeBestEffort {
+		return Report{}, badInput("unknown mode %q", opts.Mode)
+	}
+	switch kind {
+	case KindHouses:
+		recs, err := parse(r, opts.Format, houseFields)
+		if err != nil {
+			return Report{}, err
+		}
+		return run(ctx, s, kind, recs, opts, s.checkHouses, s.insertHouses)
+	case KindFlats:
+		recs, err := parse(r, opts.Format, flatFields)
+		if err != nil {
+			return Report{}, err
+		}
+		return run(ctx, s, kind, recs, opts, s.checkFlats, s.insertFlats)
+	}
+	return Report{}, badInput("unknown kind %q", kind)
+}
+
+// run validates recs, applies check to the valid ones and inserts what the
+// mode allows with insert, which gets the valid records in batches.
+func run[T any](
+	ctx context.Context,
+	s *Service,
+	kind Kind,
+	recs []record[T],
+	opts Options,
+	check func(ctx context.Context, recs []record[T]) error,
+	insert func(ctx context.Context, batches [][]*record[T]) ([]int, error),
+) (Report, error) {
+	for i := range recs {
+		if len(recs[i].errors) == 0 {
+			recs[i].errors = s.validationErrors(recs[i].dto)
+		}
+	}
+	if check != nil {
+		if err := check(ctx, recs); err != nil {
+			return Report{}, err
+		}
+	}
+	rep := Report{Kind: kind, Mode: opts.Mode, DryRun: opts.DryRun, Total: len(recs), Rows: make([]RowResult, len(recs))}
+	valid := make([]*record[T], 0, len(recs))
+	for i := range recs {
+		rep.Rows[i] = RowResult{Line: recs[i].line, Errors: recs[i].errors}
+		if len(recs[i].errors) == 0 {
+			valid = append(valid, &recs[i])
+		}
+	}
+	rep.Valid = len(valid)
+	rep.Failed = rep.Total - rep.Valid
+	if opts.DryRun || len(valid) == 0 || (opts.Mode == ModeAtomic && rep.Failed > 0) {
+		return rep, nil
+	}
+	batches := make([][]*record[T], 0, len(valid)/batchSize+1)
+	for start := 0; start < len(valid); start += batchSize {
+		batches = append(batches, valid[start:min(start+batchSize, len(valid))])
+	}
+	ids := make(map[*record[T]]int, len(valid))
+	if opts.Mode == ModeAtomic {
+		inserted, err := insert(ctx, batches)
+		switch {
+		case errors.Is(err, flat.ErrNumberTaken):
+			// Lost a race with a concurrent create, nothing was inserted.
+			for _, rec := range valid {
+				rec.errors = []string{fmt.Sprintf("import failed: %v, retry it", err)}
+			}
+		case err != nil:
+			return Report{}, err
+		default:
+			for i, rec := range valid {
+				ids[rec] = inserted[i]
+			}
+		}
+	} else {
+		for _, batch := range batches {
+			inserted, err := insert(ctx, [][]*record[T]{batch})
+			if err != nil {
+				if ctx.Err() != nil {
+					return Report{}, err
+				}
+				s.logger.Errorf("import %s batch from line %d: %v", kind, batch[0].line, err)
+				for _, rec := range batch {
+					rec.errors = []string{fmt.Sprintf("batch from line %d failed: %v", batch[0].line, err)}
+				}
+				continue
+			}
+			for i, rec := range batch {
+				ids[rec] = inserted[i]
+			}
+		}
+	}
+	rep.Failed = 0
+	for i := range recs {
+		rep.Rows[i].ID = ids[&recs[i]]
+		rep.Rows[i].Errors = recs[i].errors
+		if len(recs[i].errors) > 0 {
+			rep.Failed++
+		}
+	}
+	rep.Inserted = len(ids)
+	return rep, nil
+}
+
+// validationErrors lists the failed rules of dto by JSON field name.
+func (s *Service) validationErrors(dto any) []string {
+	err := s.validate.Struct(dto)
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		if err != nil {
+			return []string{err.Error()}
+		}
+		return nil
+	}
+	msgs := make([]string, 0, len(verrs))
+	for _, fe := range verrs {
+		rule := fe.Tag()
+		if fe.Param() != "" {
+			rule += "=" + fe.Param()
+		}
+		msgs = append(msgs, fmt.Sprintf("%s: failed %s validation", fe.Field(), rule))
+	}
+	return msgs
+}
+
+// checkHouses marks valid house records whose developer does not exist.
+func (s *Service) checkHouses(ctx context.Context, recs []record[house.CreateHouseDTO]) error {
+	ids := make([]int, 0)
+	for _, rec := range recs {
+		if len(rec.errors) == 0 && rec.dto.DeveloperID != nil {
+			ids = append(ids, *rec.dto.DeveloperID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	existing, err := s.repo.ExistingDeveloperIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i, rec := range recs {
+		if len(rec.errors) == 0 && rec.dto.DeveloperID != nil && !existing[*rec.dto.DeveloperID] {
+			recs[i].errors = []string{fmt.Sprintf("developer_id: developer %d not found", *rec.dto.DeveloperID)}
+		}
+	}
+	return nil
+}
+
+// checkFlats marks valid flat records whose house does not exist or whose
+// number is already taken, in the house or earlier in the file, then numbers
+// the records without one after the highest number of their house.
+func (s *Service) checkFlats(ctx context.Context, recs []record[flat.CreateFlatDTO]) error {
+	ids := make([]int, 0)
+	seen := make(map[int]bool)
+	for _, rec := range recs {
+		if len(rec.errors) == 0 && !seen[rec.dto.HouseID] {
+			seen[rec.dto.HouseID] = true
+			ids = append(ids, rec.dto.HouseID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	existing, err := s.repo.ExistingHouseIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	taken, err := s.repo.TakenFlatNumbers(ctx, ids)
+	if err != nil {
+		return err
+	}
+	lines := make(map[FlatNumber]int)
+	for i, rec := range recs {
+		if len(rec.errors) > 0 {
+			continue
+		}
+		if !existing[rec.dto.HouseID] {
+			recs[i].errors = []string{fmt.Sprintf("house_id: house %d not found", rec.dto.HouseID)}
+			continue
+		}
+		if rec.dto.Number == 0 {
+			continue
+		}
+		fn := FlatNumber{HouseID: rec.dto.HouseID, Number: rec.dto.Number}
+		if taken[fn] {
+			recs[i].errors = []string{fmt.Sprintf("number: flat %d already exists in house %d", fn.Number, fn.HouseID)}
+		} else if line, ok := lines[fn]; ok {
+			recs[i].errors = []string{fmt.Sprintf("number: flat %d is also on line %d", fn.Number, line)}
+		} else {
+			lines[fn] = rec.line
+		}
+	}
+	// Left to the trigger, these would get MAX+1 in the middle of the copy
+	// and could collide with a number given further down the file.
+	last := make(map[int]int)
+	for fn := range taken {
+		last[fn.HouseID] = max(last[fn.HouseID], fn.Number)
+	}
+	for fn := range lines {
+		last[fn.HouseID] = max(last[fn.HouseID], fn.Number)
+	}
+	for i, rec := range recs {
+		if len(rec.errors) == 0 && rec.dto.Number == 0 {
+			last[rec.dto.HouseID]++
+			recs[i].dto.Number = last[rec.dto.HouseID]
+		}
+	}
+	return nil
+}
+
+func (s *Service) insertHouses(ctx context.Context, batches [][]*record[house.CreateHouseDTO]) ([]int, error) {
+	ids, err := s.repo.ReserveIDs(ctx, housesTable, countRecords(batches))
+	if err != nil {
+		return nil, err
+	}
+	rows := make([][]HouseRow, len(batches))
+	next := 0
+	for i, batch := range batches {
+		rows[i] = make([]HouseRow, len(batch))
+		for j, rec := range batch {
+			rows[i][j] = HouseRow{ID: ids[next], CreateHouseDTO: rec.dto}
+			next++
+		}
+	}
+	return ids, s.repo.CopyHouses(ctx, rows)
+}
+
+func (s *Service) insertFlats(ctx context.Context, batches [][]*record[flat.CreateFlatDTO]) ([]int, error) {
+	ids, err := s.repo.ReserveIDs(ctx, flatsTable, countRecords(batches))
+	if err != nil {
+		return nil, err
+	}
+	rows := make([][]FlatRow, len(batches))
+	next := 0
+	for i, batch := range batches {
+		rows[i] = make([]FlatRow, len(batch))
+		for j, rec := range batch {
+			rows[i][j] = FlatRow{ID: ids[next], CreateFlatDTO: rec.dto}
+			next++
+		}
+	}
+	return ids, s.repo.CopyFlats(ctx, rows)
+}
+
+func countRecords[T any](batches [][]*record[T]) int {
+	n := 0
+	for _, batch := range batches {
+		n += len(batch)
+	}
+	return n
+}
+
+func NewService(r Repository, l logging.Logger) *Service {
+	validate := flat.NewValidator()
+	validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		return name
+	})
+	return &Service{repo: r, validate: validate, logger: l}
+}
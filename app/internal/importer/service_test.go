@@ -0,0 +1,410 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/middleware/middlewaretest"
+	"github.com/gorilla/mux"
+)
+
+type MockLogger struct{}
+
+func (ml *MockLogger) Trace(args ...interface{})                   {}
+func (ml *MockLogger) Debug(args ...interface{})                   {}
+func (ml *MockLogger) Info(args ...interface{})                    {}
+func (ml *MockLogger) Warn(args ...interface{})                    {}
+func (ml *MockLogger) Warning(args ...interface{})                 {}
+func (ml *MockLogger) Error(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   {}
+func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
+func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
+func (ml *MockLogger) Infof(format string, args ...interface{})    {}
+func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
+func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
+func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
+func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
+
+type MockRepo struct {
+	nextID  int
+	houses  []HouseRow
+	flats   []FlatRow
+	copies  int
+	failFor int   // CopyFlats fails for batches containing this house
+	failErr error // with this error, "copy failed" if nil
+}
+
+func (mr *MockRepo) ReserveIDs(ctx context.Context, table string, n int) ([]int, error) {
+	ids := make([]int, n)
+	for i := range ids {
+		mr.nextID++
+		ids[i] = mr.nextID
+	}
+	return ids, nil
+}
+
+func (mr *MockRepo) ExistingHouseIDs(ctx context.Context, ids []int) (map[int]bool, error) {
+	return map[int]bool{1: true, 2: true}, nil
+}
+
+func (mr *MockRepo) ExistingDeveloperIDs(ctx context.Context, ids []int) (map[int]bool, error) {
+	return map[int]bool{1: true}, nil
+}
+
+func (mr *MockRepo) TakenFlatNumbers(ctx context.Context, houseIDs []int) (map[FlatNumber]bool, error) {
+	return map[FlatNumber]bool{{HouseID: 1, Number: 1}: true}, nil
+}
+
+func (mr *MockRepo) CopyHouses(ctx context.Context, batches [][]HouseRow) error {
+	for _, b := range batches {
+		mr.copies++
+		mr.houses = append(mr.houses, b...)
+	}
+	return nil
+}
+
+func (mr *MockRepo) CopyFlats(ctx context.Context, batches [][]FlatRow) error {
+	for _, b := range batches {
+		for _, f := range b {
+			if f.HouseID == mr.failFor {
+				if mr.failErr != nil {
+					return mr.failErr
+				}
+				return errors.New("copy failed")
+			}
+		}
+	}
+	for _, b := range batches {
+		mr.copies++
+		mr.flats = append(mr.flats, b...)
+	}
+	return nil
+}
+
+const flatsCSV = `house_id,price,rooms
+1,100,2
+2,abc,1
+3,300,1
+2,400
+`
+
+func lineErrors(rep Report) map[int]string {
+	errs := make(map[int]string)
+	for _, row := range rep.Rows {
+		if len(row.Errors) > 0 {
+			errs[row.Line] = strings.Join(row.Errors, "; ")
+		}
+	}
+	return errs
+}
+
+func TestImport_ReportsRowErrors(t *testing.T) {
+	repo := &MockRepo{}
+	s := NewService(repo, &MockLogger{})
+	rep, err := s.Import(context.Background(), KindFlats, strings.NewReader(flatsCSV), Options{Format: FormatCSV, Mode: ModeAtomic})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if rep.Total != 4 || rep.Valid != 1 || rep.Failed != 3 || rep.Inserted != 0 || rep.OK() {
+		t.Errorf("report = %+v", rep)
+	}
+	want := map[int]string{
+		3: "price: must be an integer",
+		4: "house_id: house 3 not found",
+		5: "expected 3 fields, got 2",
+	}
+	got := lineErrors(rep)
+	for line, msg := range want {
+		if got[line] != msg {
+			t.Errorf("line %d errors = %q, want %q", line, got[line], msg)
+		}
+	}
+	if len(repo.flats) != 0 {
+		t.Errorf("atomic import with invalid rows inserted %d flats", len(repo.flats))
+	}
+}
+
+func TestImport_FlatAttributes(t *testing.T) {
+	in := `house_id,number,price,rooms,floor,total_area,living_area,balcony,layout
+1,1,100,1,,,,,
+1,2,100,1,3,42.5,20,true,isolated
+1,2,100,1,,,,,
+2,,100,1,x,,,yes,
+2,1,100,1,,30,40,,loft
+`
+	repo := &MockRepo{}
+	s := NewService(repo, &MockLogger{})
+	rep, err := s.Import(context.Background(), KindFlats, strings.NewReader(in), Options{Format: FormatCSV, Mode: ModeBestEffort})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	want := map[int]string{
+		2: "number: flat 1 already exists in house 1",
+		4: "number: flat 2 is also on line 3",
+		5: "floor: must be an integer; balcony: must be a boolean",
+		6: "layout: failed oneof_layout validation; living_area: failed fits_total_area validation",
+	}
+	got := lineErrors(rep)
+	if len(got) != len(want) {
+		t.Errorf("errors = %v", got)
+	}
+	for line, msg := range want {
+		if got[line] != msg {
+			t.Errorf("line %d errors = %q, want %q", line, got[line], msg)
+		}
+	}
+	if len(repo.flats) != 1 {
+		t.Fatalf("inserted %d flats, want 1", len(repo.flats))
+	}
+	f := repo.flats[0]
+	if f.Number != 2 || *f.Floor != 3 || *f.TotalArea != 42.5 || *f.LivingArea != 20 || f.KitchenArea != nil || !f.Balcony || *f.Layout != "isolated" {
+		t.Errorf("inserted %+v", f)
+	}
+}
+
+func TestImport_FlatNumbers(t *testing.T) {
+	in := `house_id,number,price,rooms
+1,,100,1
+1,3,100,1
+2,,100,1
+1,,100,1
+`
+	repo := &MockRepo{}
+	s := NewService(repo, &MockLogger{})
+	rep, err := s.Import(context.Background(), KindFlats, strings.NewReader(in), Options{Format: FormatCSV, Mode: ModeAtomic})
+	if err != nil || rep.Inserted != 4 {
+		t.Fatalf("Import = %+v, %v", rep, err)
+	}
+	want := []FlatNumber{{HouseID: 1, Number: 4}, {HouseID: 1, Number: 3}, {HouseID: 2, Number: 1}, {HouseID: 1, Number: 5}}
+	for i, f := range repo.flats {
+		if got := (FlatNumber{HouseID: f.HouseID, Number: f.Number}); got != want[i] {
+			t.Errorf("flat %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+// importRecorder counts the rows it was told about.
+type importRecorder map[Kind]int
+
+func (ir importRecorder) OnImport(ctx context.Context, kind Kind, inserted int) {
+	ir[kind] += inserted
+}
+
+func TestImport_BestEffort(t *testing.T) {
+	repo := &MockRepo{}
+	s := NewService(repo, &MockLogger{})
+	imported := importRecorder{}
+	s.AddObserver(imported)
+	rep, err := s.Import(context.Background(), KindFlats, strings.NewReader(flatsCSV), Options{Format: FormatCSV, Mode: ModeBestEffort})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if rep.Inserted != 1 || rep.Failed != 3 || len(repo.flats) != 1 {
+		t.Fatalf("report = %+v, inserted flats %v", rep, repo.flats)
+	}
+	if imported[KindFlats] != 1 {
+		t.Errorf("observer told about %v, want 1 flat", imported)
+	}
+	if rep.Rows[0].ID != repo.flats[0].ID || repo.flats[0].Price != 100 {
+		t.Errorf("row 1 = %+v, inserted %+v", rep.Rows[0], repo.flats[0])
+	}
+}
+
+func TestImport_BestEffortFailedBatch(t *testing.T) {
+	repo := &MockRepo{failFor: 2}
+	s := NewService(repo, &MockLogger{})
+	var in strings.Builder
+	in.WriteString("house_id,price,rooms\n")
+	for i := 0; i < batchSize; i++ {
+		in.WriteString("1,100,1\n")
+	}
+	in.WriteString("2,100,1\n")
+	rep, err := s.Import(context.Background(), KindFlats, strings.NewReader(in.String()), Options{Format: FormatCSV, Mode: ModeBestEffort})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if rep.Inserted != batchSize || rep.Failed != 1 || repo.copies != 1 {
+		t.Errorf("inserted %d, failed %d, copies %d; want %d, 1, 1", rep.Inserted, rep.Failed, repo.copies, batchSize)
+	}
+	if last := rep.Rows[len(rep.Rows)-1]; last.ID != 0 || len(last.Errors) != 1 {
+		t.Errorf("row of the failed batch = %+v", last)
+	}
+}
+
+func TestImport_AtomicNumberTakenConcurrently(t *testing.T) {
+	repo := &MockRepo{failFor: 2, failErr: flat.ErrNumberTaken}
+	s := NewService(repo, &MockLogger{})
+	in := "house_id,number,price,rooms\n1,2,100,1\n2,5,100,1\n"
+	rep, err := s.Import(context.Background(), KindFlats, strings.NewReader(in), Options{Format: FormatCSV})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if rep.OK() || rep.Inserted != 0 || rep.Failed != 2 {
+		t.Errorf("inserted %d, failed %d; want 0, 2", rep.Inserted, rep.Failed)
+	}
+	for _, row := range rep.Rows {
+		if row.ID != 0 || len(row.Errors) != 1 || !strings.Contains(row.Errors[0], flat.ErrNumberTaken.Error()) {
+			t.Errorf("row = %+v", row)
+		}
+	}
+}
+
+func TestImport_AtomicInBatches(t *testing.T) {
+	repo := &MockRepo{}
+	s := NewService(repo, &MockLogger{})
+	var in strings.Builder
+	for i := 0; i < batchSize+1; i++ {
+		in.WriteString(`{"address": "Lenina 1", "year": 2000}` + "\n")
+	}
+	rep, err := s.Import(context.Background(), KindHouses, strings.NewReader(in.String()), Options{Format: FormatJSONL})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if !rep.OK() || rep.Inserted != batchSize+1 || rep.Mode != ModeAtomic || repo.copies != 2 {
+		t.Errorf("inserted %d, failed %d, mode %s, copies %d", rep.Inserted, rep.Failed, rep.Mode, repo.copies)
+	}
+}
+
+func TestImport_DryRun(t *testing.T) {
+	repo := &MockRepo{}
+	s := NewService(repo, &MockLogger{})
+	imported := importRecorder{}
+	s.AddObserver(imported)
+	in := `{"address": "Lenina 1", "year": 2000, "developer": "PIK"}
+
+{"address": "", "year": 2000}
+{"address": "Lenina 3", "year": 2000, "color": "red"}
+`
+	rep, err := s.Import(context.Background(), KindHouses, strings.NewReader(in), Options{Format: FormatJSONL, Mode: ModeBestEffort, DryRun: true})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if rep.Total != 3 || rep.Valid != 1 || rep.Inserted != 0 || len(repo.houses) != 0 || len(imported) != 0 {
+		t.Errorf("report = %+v, observer told about %v", rep, imported)
+	}
+	want := map[int]string{
+		3: "address: failed required validation",
+		4: `unknown field "color"`,
+	}
+	got := lineErrors(rep)
+	for line, msg := range want {
+		if got[line] != msg {
+			t.Errorf("line %d errors = %q, want %q", line, got[line], msg)
+		}
+	}
+}
+
+func TestImport_HouseAttributes(t *testing.T) {
+	in := `address,year,floors,material,latitude,longitude,city,parking,elevator
+Lenina 1,2000,9,panel,55.75,37.62,Moscow,true,1
+Lenina 2,2000,,straw,55.75,,,,
+`
+	repo := &MockRepo{}
+	s := NewService(repo, &MockLogger{})
+	rep, err := s.Import(context.Background(), KindHouses, strings.NewReader(in), Options{Format: FormatCSV, Mode: ModeBestEffort})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	want := "material: failed oneof=brick panel monolith monolith_brick block wood validation; " +
+		"longitude: failed required_with=Latitude validation"
+	if got := lineErrors(rep)[3]; got != want {
+		t.Errorf("line 3 errors = %q, want %q", got, want)
+	}
+	if len(repo.houses) != 1 {
+		t.Fatalf("inserted %d houses, want 1", len(repo.houses))
+	}
+	h := repo.houses[0]
+	if *h.Floors != 9 || *h.Material != "panel" || *h.Latitude != 55.75 || *h.Longitude != 37.62 ||
+		*h.City != "Moscow" || h.District != nil || !h.Parking || !h.Elevator {
+		t.Errorf("inserted %+v", h)
+	}
+}
+
+func TestImport_HouseDevelopers(t *testing.T) {
+	in := `address,year,developer_id,developer
+Lenina 1,2000,1,
+Lenina 2,2000,,ПИК
+Lenina 3,2000,2,
+Lenina 4,2000,1,ПИК
+`
+	repo := &MockRepo{}
+	s := NewService(repo, &MockLogger{})
+	rep, err := s.Import(context.Background(), KindHouses, strings.NewReader(in), Options{Format: FormatCSV, Mode: ModeBestEffort})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	want := map[int]string{
+		4: "developer_id: developer 2 not found",
+		5: "developer: failed excluded_with=DeveloperID validation",
+	}
+	got := lineErrors(rep)
+	for line, msg := range want {
+		if got[line] != msg {
+			t.Errorf("line %d errors = %q, want %q", line, got[line], msg)
+		}
+	}
+	if len(repo.houses) != 2 || *repo.houses[0].DeveloperID != 1 || repo.houses[1].Developer != "ПИК" {
+		t.Errorf("inserted %+v", repo.houses)
+	}
+}
+
+func TestImport_BadInput(t *testing.T) {
+	s := NewService(&MockRepo{}, &MockLogger{})
+	tests := []struct {
+		name string
+		kind Kind
+		in   string
+		opts Options
+	}{
+		{name: "unknown column", kind: KindFlats, in: "house_id,price,color\n1,2,3\n", opts: Options{Format: FormatCSV}},
+		{name: "duplicate column", kind: KindFlats, in: "price,price\n1,2\n", opts: Options{Format: FormatCSV}},
+		{name: "empty csv", kind: KindHouses, in: "", opts: Options{Format: FormatCSV}},
+		{name: "empty jsonl", kind: KindHouses, in: "\n\n", opts: Options{Format: FormatJSONL}},
+		{name: "unknown mode", kind: KindHouses, in: "address,year\n", opts: Options{Format: FormatCSV, Mode: "some"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Import(context.Background(), tt.kind, strings.NewReader(tt.in), tt.opts)
+			if !errors.Is(err, ErrBadInput) {
+				t.Errorf("Import() error = %v, want %v", err, ErrBadInput)
+			}
+		})
+	}
+}
+
+func TestHandler_Import(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+		wantCode    int
+	}{
+		{name: "csv", url: "/import/flats", contentType: "text/csv", body: "house_id,price,rooms\n1,100,2\n", wantCode: http.StatusOK},
+		{name: "atomic with invalid rows", url: "/import/flats", contentType: "text/csv", body: flatsCSV, wantCode: http.StatusUnprocessableEntity},
+		{name: "best effort with invalid rows", url: "/import/flats?mode=best_effort", contentType: "text/csv", body: flatsCSV, wantCode: http.StatusOK},
+		{name: "jsonl dry run", url: "/import/houses?dry_run=true", contentType: "application/x-ndjson", body: `{"address": "a", "year": 1}`, wantCode: http.StatusOK},
+		{name: "bad dry_run", url: "/import/houses?dry_run=maybe", contentType: "application/x-ndjson", body: `{}`, wantCode: http.StatusBadRequest},
+		{name: "bad header", url: "/import/houses", contentType: "text/csv", body: "street\n", wantCode: http.StatusBadRequest},
+		{name: "json body", url: "/import/houses", contentType: "application/json", body: `[]`, wantCode: http.StatusUnsupportedMediaType},
+	}
+	router := mux.NewRouter()
+	NewHandler(middlewaretest.Auth{}, NewService(&MockRepo{}, &MockLogger{}), &MockLogger{}).Register(router)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			if rr.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d: %s", rr.Code, tt.wantCode, rr.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package importer
+
+import "context"
+
+type Repository interface {
+	// ReserveIDs takes n values from the id sequence of table, so that
+	// copied rows can be reported with their IDs.
+	ReserveIDs(ctx context.Context, table string, n int) ([]int, error)
+	ExistingHouseIDs(ctx context.Context, ids []int) (map[int]bool, error)
+	ExistingDeveloperIDs(ctx context.Context, ids []int) (map[int]bool, error)
+	// TakenFlatNumbers returns the numbers of the flats already stored in
+	// the given houses.
+	TakenFlatNumbers(ctx context.Context, houseIDs []int) (map[FlatNumber]bool, error)
+	// CopyHouses and CopyFlats insert all batches in one transaction, one
+	// COPY per batch. CopyHouses refers houses given a developer name to the
+	// developer with the same key, creating the missing ones. CopyFlats
+	// returns flat.ErrNumberTaken if a flat number was taken concurrently.
+	CopyHouses(ctx context.Context, batches [][]HouseRow) error
+	CopyFlats(ctx context.Context, batches [][]FlatRow) error
+}
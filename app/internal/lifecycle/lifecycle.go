@@ -0,0 +1,156 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Polyrom/houses_api/pkg/logging"
+)
+
+const defaultShutdownTimeout = 15 * time.Second
+
+type server struct {
+	name     string
+	serve    func() error
+	shutdown func(ctx context.Context) error
+}
+
+type worker struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+type closer struct {
+	name  string
+	close func()
+}
+
+// Manager starts servers and background workers and stops them in order:
+// readiness is flipped first, then servers drain in-flight requests, then
+// workers are cancelled and finally resources are closed in reverse order.
+type Manager struct {
+	l               logging.Logger
+	shutdownTimeout time.Duration
+	readinessDelay  time.Duration
+	ready           atomic.Bool
+	servers         []server
+	workers         []worker
+	closers         []closer
+}
+
+// AddServer registers a server. serve must block until the server stops;
+// returning before shutdown is treated as a failure.
+func (m *Manager) AddServer(name string, serve func() error, shutdown func(ctx context.Context) error) {
+	m.servers = append(m.servers, server{name: name, serve: serve, shutdown: shutdown})
+}
+
+// AddWorker registers a background worker that runs until ctx is cancelled.
+func (m *Manager) AddWorker(name string, run func(ctx context.Context) error) {
+	m.workers = append(m.workers, worker{name: name, run: run})
+}
+
+// AddCloser registers a resource released after servers and workers stop.
+// Closers run in reverse order of registration.
+func (m *Manager) AddCloser(name string, fn func()) {
+	m.closers = append(m.closers, closer{name: name, close: fn})
+}
+
+// Ready reports whether the application accepts traffic.
+func (m *Manager) Ready() bool {
+	return m.ready.Load()
+}
+
+// Run starts everything and blocks until ctx is done or a server fails,
+// then performs the shutdown sequence.
+func (m *Manager) Run(ctx context.Context) error {
+	workerCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
+	var wg sync.WaitGroup
+	for _, wk := range m.workers {
+		wg.Add(1)
+		go func(wk worker) {
+			defer wg.Done()
+			if err := wk.run(workerCtx); err != nil && !errors.Is(err, context.Canceled) {
+				m.l.Errorf("worker %s stopped: %v", wk.name, err)
+			}
+		}(wk)
+	}
+	serveErr := make(chan error, len(m.servers))
+	for _, srv := range m.servers {
+		go func(srv server) {
+			err := srv.serve()
+			if err != nil {
+				err = fmt.Errorf("%s: %w", srv.name, err)
+			} else {
+				err = fmt.Errorf("%s: stopped unexpectedly", srv.name)
+			}
+			serveErr <- err
+		}(srv)
+	}
+	m.ready.Store(true)
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		m.l.Info("shutdown signal received")
+	case runErr = <-serveErr:
+		m.l.Errorf("server failed: %v", runErr)
+	}
+	m.ready.Store(false)
+	if runErr == nil && m.readinessDelay > 0 {
+		m.l.Infof("waiting %s for load balancers to observe readiness", m.readinessDelay)
+		time.Sleep(m.readinessDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout)
+	defer cancel()
+	var errs []error
+	if runErr != nil {
+		errs = append(errs, runErr)
+	}
+	var swg sync.WaitGroup
+	var mu sync.Mutex
+	for _, srv := range m.servers {
+		swg.Add(1)
+		go func(srv server) {
+			defer swg.Done()
+			m.l.Infof("draining %s", srv.name)
+			if err := srv.shutdown(shutdownCtx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("shutdown %s: %w", srv.name, err))
+				mu.Unlock()
+			}
+		}(srv)
+	}
+	swg.Wait()
+
+	cancelWorkers()
+	workersDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(workersDone)
+	}()
+	select {
+	case <-workersDone:
+	case <-shutdownCtx.Done():
+		errs = append(errs, errors.New("background workers did not stop in time"))
+	}
+
+	for i := len(m.closers) - 1; i >= 0; i-- {
+		m.l.Infof("closing %s", m.closers[i].name)
+		m.closers[i].close()
+	}
+	m.l.Info("shutdown complete")
+	return errors.Join(errs...)
+}
+
+func New(l logging.Logger, shutdownTimeout, readinessDelay time.Duration) *Manager {
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
+	return &Manager{l: l, shutdownTimeout: shutdownTimeout, readinessDelay: readinessDelay}
+}
@@ -0,0 +1,97 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type MockLogger struct{}
+
+func (ml *MockLogger) Trace(args ...interface{})                   {}
+func (ml *MockLogger) Debug(args ...interface{})                   {}
+func (ml *MockLogger) Info(args ...interface{})                    {}
+func (ml *MockLogger) Warn(args ...interface{})                    {}
+func (ml *MockLogger) Warning(args ...interface{})                 {}
+func (ml *MockLogger) Error(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   {}
+func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
+func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
+func (ml *MockLogger) Infof(format string, args ...interface{})    {}
+func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
+func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
+func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
+func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
+
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(e string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func TestManager_ShutdownOrder(t *testing.T) {
+	m := New(&MockLogger{}, time.Second, 0)
+	rec := &recorder{}
+	stopped := make(chan struct{})
+	m.AddServer("srv", func() error {
+		<-stopped
+		return nil
+	}, func(ctx context.Context) error {
+		if m.Ready() {
+			t.Error("server drained while still ready")
+		}
+		rec.add("server")
+		close(stopped)
+		return nil
+	})
+	m.AddWorker("worker", func(ctx context.Context) error {
+		<-ctx.Done()
+		rec.add("worker")
+		return ctx.Err()
+	})
+	m.AddCloser("first", func() { rec.add("first") })
+	m.AddCloser("second", func() { rec.add("second") })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- m.Run(ctx) }()
+	for !m.Ready() {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := []string{"server", "worker", "second", "first"}
+	if !reflect.DeepEqual(rec.events, want) {
+		t.Errorf("shutdown order = %v, want %v", rec.events, want)
+	}
+}
+
+func TestManager_ServerFailure(t *testing.T) {
+	m := New(&MockLogger{}, time.Second, 0)
+	listenErr := errors.New("address already in use")
+	m.AddServer("srv", func() error {
+		return listenErr
+	}, func(ctx context.Context) error {
+		return nil
+	})
+	closed := false
+	m.AddCloser("db", func() { closed = true })
+	err := m.Run(context.Background())
+	if !errors.Is(err, listenErr) {
+		t.Errorf("Run() error = %v, want %v", err, listenErr)
+	}
+	if !closed {
+		t.Error("closer was not called after server failure")
+	}
+}
@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"expvar"
+	"net/http"
+)
+
+// Counters are published through expvar and served as JSON at /debug/vars.
+var (
+	Panics = expvar.NewInt("http_panics_total")
+	// DeprecatedCalls counts requests to legacy routes by path template.
+	DeprecatedCalls = expvar.NewMap("http_deprecated_calls_total")
+	// StreamSubscribers is the number of open event streams.
+	StreamSubscribers = expvar.NewInt("event_stream_subscribers")
+	// StreamSubscribersDropped counts streams cut off for falling behind.
+	StreamSubscribersDropped = expvar.NewInt("event_stream_subscribers_dropped_total")
+	// EventsDropped counts flat events not logged because the queue was full.
+	EventsDropped = expvar.NewInt("events_dropped_total")
+	// WebhookAttempts counts webhook delivery attempts by outcome:
+	// delivered, failed or dead.
+	WebhookAttempts = expvar.NewMap("webhook_attempts_total")
+)
+
+func Handler() http.Handler {
+	return expvar.Handler()
+}
@@ -27,7 +27,7 @@ type isAuthMiddleware struct {
 
 func (authmw *isAuthMiddleware) DoInMiddle(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		reqID := r.Context().Value(ContextKeyRequestID).(string)
+		reqID := RequestID(r.Context())
 		token := r.Header.Get("Authorization")
 		if token == "" {
 			noTokenErr := errors.New("no token")
@@ -66,7 +66,7 @@ type isModerMiddleware struct {
 
 func (modermw *isModerMiddleware) DoInMiddle(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		reqID := r.Context().Value(ContextKeyRequestID).(string)
+		reqID := RequestID(r.Context())
 		token := r.Header.Get("Authorization")
 		if token == "" {
 			noTokenErr := errors.New("no token")
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/pkg/logging"
+)
+
+const defaultMaxBodyBytes = 1 << 20
+
+type bodyLimitMiddleware struct {
+	limit  int64
+	routes map[string]int64
+	l      logging.Logger
+}
+
+// DoInMiddle rejects bodies declared larger than the limit and caps the
+// reader for the rest, so oversized chunked bodies fail while decoding.
+func (blmw *bodyLimitMiddleware) DoInMiddle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := blmw.limit
+		if routeLimit, ok := blmw.routes[routeTemplate(r)]; ok {
+			limit = routeLimit
+		}
+		if r.ContentLength > limit {
+			reqID := RequestID(r.Context())
+			tooLargeErr := fmt.Errorf("request body must not be larger than %d bytes", limit)
+			blmw.l.Errorf("request entity too large req_id=%s: %v", reqID, tooLargeErr)
+			apierror.Write(w, tooLargeErr, reqID, http.StatusRequestEntityTooLarge)
+			return
+		}
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// NewBodyLimitMiddleware limits bodies to limit bytes, except on the route
+// templates in routes, which get their own limits.
+func NewBodyLimitMiddleware(limit int64, routes map[string]int64, l logging.Logger) Middleware {
+	if limit <= 0 {
+		limit = defaultMaxBodyBytes
+	}
+	return &bodyLimitMiddleware{limit: limit, routes: routes, l: l}
+}
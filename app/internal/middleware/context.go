@@ -0,0 +1,22 @@
+package middleware
+
+import "context"
+
+// RequestID returns the request ID set by the request ID middleware or an
+// empty string when it is missing.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(ContextKeyRequestID).(string)
+	return id
+}
+
+// CurrentUserID returns the ID of the authenticated user.
+func CurrentUserID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(UserID).(string)
+	return id, ok && id != ""
+}
+
+// CurrentRole returns the role of the authenticated user.
+func CurrentRole(ctx context.Context) (Role, bool) {
+	role, ok := ctx.Value(UserRole).(Role)
+	return role, ok
+}
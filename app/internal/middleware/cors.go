@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Polyrom/houses_api/internal/config"
+	"github.com/Polyrom/houses_api/pkg/logging"
+)
+
+type corsMiddleware struct {
+	cfg            config.CORSConfig
+	l              logging.Logger
+	allowedMethods string
+	allowedHeaders map[string]bool
+}
+
+func (cmw *corsMiddleware) DoInMiddle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if !cmw.originAllowed(origin) {
+			cmw.l.Warnf("cors origin not allowed req_id=%s: %s", RequestID(r.Context()), origin)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if cmw.cfg.AllowCredentials || !cmw.wildcard() {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		}
+		if cmw.cfg.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			if len(cmw.cfg.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(cmw.cfg.ExposedHeaders, ", "))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		if !cmw.methodAllowed(r.Header.Get("Access-Control-Request-Method")) || !cmw.headersAllowed(r.Header.Get("Access-Control-Request-Headers")) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Methods", cmw.allowedMethods)
+		if len(cmw.cfg.AllowedHeaders) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(cmw.cfg.AllowedHeaders, ", "))
+		}
+		if cmw.cfg.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(cmw.cfg.MaxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (cmw *corsMiddleware) wildcard() bool {
+	for _, o := range cmw.cfg.AllowedOrigins {
+		if o == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (cmw *corsMiddleware) originAllowed(origin string) bool {
+	for _, allowed := range cmw.cfg.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		prefix, suffix, found := strings.Cut(allowed, "*")
+		if found && len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cmw *corsMiddleware) methodAllowed(method string) bool {
+	if method == http.MethodGet || method == http.MethodHead || method == http.MethodPost {
+		return true
+	}
+	for _, m := range cmw.cfg.AllowedMethods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cmw *corsMiddleware) headersAllowed(requested string) bool {
+	for _, h := range strings.Split(requested, ",") {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h != "" && !cmw.allowedHeaders[h] {
+			return false
+		}
+	}
+	return true
+}
+
+// PreflightHandler answers OPTIONS requests that are not CORS preflights.
+// It is registered as a catch-all OPTIONS route so that gorilla mux matches
+// preflights instead of failing them with 405 before middlewares run.
+func PreflightHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func NewCORSMiddleware(cfg config.CORSConfig, l logging.Logger) Middleware {
+	headers := make(map[string]bool, len(cfg.AllowedHeaders))
+	for _, h := range cfg.AllowedHeaders {
+		headers[strings.ToLower(h)] = true
+	}
+	methods := make([]string, 0, len(cfg.AllowedMethods))
+	for _, m := range cfg.AllowedMethods {
+		methods = append(methods, strings.ToUpper(m))
+	}
+	return &corsMiddleware{cfg: cfg, l: l, allowedMethods: strings.Join(methods, ", "), allowedHeaders: headers}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/config"
+	"github.com/gorilla/mux"
+)
+
+func newTestCORSRouter() *mux.Router {
+	cfg := config.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.preview.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", "Idempotency-Key"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	r := mux.NewRouter()
+	r.Use(NewCORSMiddleware(cfg, &MockLogger{}).DoInMiddle)
+	r.PathPrefix("/").Methods(http.MethodOptions).HandlerFunc(PreflightHandler)
+	r.HandleFunc("/flat/create", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodPost)
+	return r
+}
+
+func TestCORS(t *testing.T) {
+	r := newTestCORSRouter()
+	tests := []struct {
+		name        string
+		method      string
+		origin      string
+		reqMethod   string
+		reqHeaders  string
+		wantCode    int
+		wantOrigin  string
+		wantMethods string
+		wantMaxAge  string
+	}{
+		{name: "preflight allowed", method: http.MethodOptions, origin: "https://app.example.com", reqMethod: "POST", reqHeaders: "authorization, idempotency-key", wantCode: http.StatusNoContent, wantOrigin: "https://app.example.com", wantMethods: "GET, POST", wantMaxAge: "600"},
+		{name: "preflight wildcard subdomain", method: http.MethodOptions, origin: "https://pr-1.preview.example.com", reqMethod: "POST", wantCode: http.StatusNoContent, wantOrigin: "https://pr-1.preview.example.com", wantMethods: "GET, POST", wantMaxAge: "600"},
+		{name: "preflight header not allowed", method: http.MethodOptions, origin: "https://app.example.com", reqMethod: "POST", reqHeaders: "X-Secret", wantCode: http.StatusNoContent, wantOrigin: "https://app.example.com"},
+		{name: "preflight origin not allowed", method: http.MethodOptions, origin: "https://evil.com", reqMethod: "POST", wantCode: http.StatusNoContent},
+		{name: "simple request", method: http.MethodPost, origin: "https://app.example.com", wantCode: http.StatusOK, wantOrigin: "https://app.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/flat/create", nil)
+			req.Header.Set("Origin", tt.origin)
+			if tt.reqMethod != "" {
+				req.Header.Set("Access-Control-Request-Method", tt.reqMethod)
+			}
+			if tt.reqHeaders != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.reqHeaders)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+			if rr.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", rr.Code, tt.wantCode)
+			}
+			if got := rr.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := rr.Header().Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Errorf("Access-Control-Allow-Methods = %q, want %q", got, tt.wantMethods)
+			}
+			if got := rr.Header().Get("Access-Control-Max-Age"); got != tt.wantMaxAge {
+				t.Errorf("Access-Control-Max-Age = %q, want %q", got, tt.wantMaxAge)
+			}
+		})
+	}
+}
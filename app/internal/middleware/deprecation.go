@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/metrics"
+	"github.com/Polyrom/houses_api/pkg/logging"
+)
+
+// contextKeySuccessor holds the template of the route replacing a legacy one.
+const contextKeySuccessor ContextKey = "successorRoute"
+
+type deprecationMiddleware struct {
+	deprecatedAt time.Time
+	sunset       time.Time
+	prefix       string
+	renamed      map[string]string
+	l            logging.Logger
+}
+
+// DoInMiddle marks responses of legacy routes with the Deprecation (RFC 9745)
+// and Sunset (RFC 8594) headers and counts their usage. The route is
+// rate limited as its successor under prefix, so that both share a bucket.
+func (dmw *deprecationMiddleware) DoInMiddle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(r)
+		successor, ok := dmw.renamed[route]
+		if !ok {
+			successor = route
+		}
+		r = r.WithContext(context.WithValue(r.Context(), contextKeySuccessor, dmw.prefix+successor))
+		metrics.DeprecatedCalls.Add(route, 1)
+		dmw.l.Warnf("deprecated route %s %s used req_id=%s", r.Method, route, RequestID(r.Context()))
+		if !dmw.deprecatedAt.IsZero() {
+			w.Header().Set("Deprecation", "@"+strconv.FormatInt(dmw.deprecatedAt.Unix(), 10))
+		}
+		if !dmw.sunset.IsZero() {
+			w.Header().Set("Sunset", dmw.sunset.UTC().Format(http.TimeFormat))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// NewDeprecationMiddleware builds the middleware for legacy routes, which
+// live at prefix plus the same path unless listed in renamed.
+func NewDeprecationMiddleware(deprecatedAt, sunset time.Time, prefix string, renamed map[string]string, l logging.Logger) Middleware {
+	return &deprecationMiddleware{deprecatedAt: deprecatedAt, sunset: sunset, prefix: prefix, renamed: renamed, l: l}
+}
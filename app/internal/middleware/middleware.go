@@ -5,3 +5,17 @@ import "net/http"
 type Middleware interface {
 	DoInMiddle(next http.Handler) http.Handler
 }
+
+type chain []Middleware
+
+func (c chain) DoInMiddle(next http.Handler) http.Handler {
+	for i := len(c) - 1; i >= 0; i-- {
+		next = c[i].DoInMiddle(next)
+	}
+	return next
+}
+
+// Chain combines middlewares into one; the first one runs first.
+func Chain(mws ...Middleware) Middleware {
+	return chain(mws)
+}
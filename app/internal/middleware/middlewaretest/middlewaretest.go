@@ -0,0 +1,45 @@
+// Package middlewaretest provides a stand-in for the auth middleware in
+// handler tests.
+package middlewaretest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Polyrom/houses_api/internal/middleware"
+)
+
+// Headers that name the user of a test request.
+const (
+	RoleHeader = "X-Role"
+	UserHeader = "X-User"
+)
+
+// Auth stands in for the auth middleware. The role and the user come from
+// the X-Role and X-User headers, falling back to Role and UserID; a request
+// without either is anonymous.
+type Auth struct {
+	Role   middleware.Role
+	UserID string
+}
+
+func (a Auth) DoInMiddle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		role := a.Role
+		if v := r.Header.Get(RoleHeader); v != "" {
+			role = middleware.Role(v)
+		}
+		if role != "" {
+			ctx = context.WithValue(ctx, middleware.UserRole, role)
+		}
+		userID := a.UserID
+		if v := r.Header.Get(UserHeader); v != "" {
+			userID = v
+		}
+		if userID != "" {
+			ctx = context.WithValue(ctx, middleware.UserID, userID)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,213 @@
+package middleware
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/config"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/gorilla/mux"
+)
+
+// sweepInterval is how often buckets that have refilled completely are
+// dropped, keeping memory bounded by the number of recently active clients.
+const sweepInterval = time.Minute
+
+// AuthRoute is the bucket route of the per-IP limit checked before auth.
+const AuthRoute = "auth"
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter keeps the token buckets for the configured route limits.
+// One limiter is shared by the HTTP and gRPC servers so a client gets the
+// same budget whichever API it calls.
+type RateLimiter struct {
+	cfg       config.RateLimitConfig
+	now       func() time.Time
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type rateLimitMiddleware struct {
+	rl *RateLimiter
+	l  logging.Logger
+}
+
+type authRateLimitMiddleware struct {
+	rl *RateLimiter
+	l  logging.Logger
+}
+
+// DoInMiddle limits requests by client IP before the auth middleware runs,
+// so that guessing tokens is throttled as well.
+func (armw *authRateLimitMiddleware) DoInMiddle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := "ip:" + clientIP(r, armw.rl.cfg.TrustProxy)
+		if allowed, retryAfter := armw.rl.Allow(AuthRoute, client); !allowed {
+			reqID := RequestID(r.Context())
+			tooManyErr := errors.New("too many requests")
+			armw.l.Errorf("rate limited req_id=%s key=%s|%s", reqID, AuthRoute, client)
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+			apierror.Write(w, tooManyErr, reqID, http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// DoInMiddle limits requests per route with a token bucket keyed by the
+// authenticated user, or by client IP for anonymous requests. It must run
+// after the auth middleware for the user to be known.
+func (rlmw *rateLimitMiddleware) DoInMiddle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := limitRoute(r)
+		limit := rlmw.rl.cfg.For(route)
+		if limit.Rate <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key := route + "|ip:" + clientIP(r, rlmw.rl.cfg.TrustProxy)
+		if uid, ok := CurrentUserID(r.Context()); ok {
+			key = route + "|user:" + uid
+		}
+		allowed, remaining, reset, retryAfter := rlmw.rl.take(key, limit)
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+		if !allowed {
+			reqID := RequestID(r.Context())
+			tooManyErr := errors.New("too many requests")
+			rlmw.l.Errorf("rate limited req_id=%s key=%s", reqID, key)
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+			apierror.Write(w, tooManyErr, reqID, http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Allow consumes a token from the bucket of the client on route, where
+// client is "user:<id>" or "ip:<addr>". When denied it returns the time
+// until a token becomes available.
+func (rl *RateLimiter) Allow(route, client string) (bool, time.Duration) {
+	limit := rl.limitFor(route)
+	if limit.Rate <= 0 {
+		return true, 0
+	}
+	allowed, _, _, retryAfter := rl.take(route+"|"+client, limit)
+	return allowed, retryAfter
+}
+
+// take consumes a token if available. It returns the tokens left, the time
+// until the bucket is full again and, when denied, the time until a token
+// becomes available.
+func (rl *RateLimiter) take(key string, limit config.RateLimit) (bool, int, time.Duration, time.Duration) {
+	now := rl.now()
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if now.Sub(rl.lastSweep) >= sweepInterval {
+		rl.sweep(now)
+	}
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		rl.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+	allowed := b.tokens >= 1
+	var retryAfter time.Duration
+	if allowed {
+		b.tokens--
+	} else {
+		retryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+	reset := secondsToDuration((float64(limit.Burst) - b.tokens) / limit.Rate)
+	return allowed, int(b.tokens), reset, retryAfter
+}
+
+func (rl *RateLimiter) sweep(now time.Time) {
+	for key, b := range rl.buckets {
+		route, _, _ := strings.Cut(key, "|")
+		limit := rl.limitFor(route)
+		if limit.Rate <= 0 || b.tokens+now.Sub(b.last).Seconds()*limit.Rate >= float64(limit.Burst) {
+			delete(rl.buckets, key)
+		}
+	}
+	rl.lastSweep = now
+}
+
+func (rl *RateLimiter) limitFor(route string) config.RateLimit {
+	if route == AuthRoute {
+		return rl.cfg.Auth
+	}
+	return rl.cfg.For(route)
+}
+
+// limitRoute returns the route template the limits are looked up and
+// counted by: the successor of a legacy route, or the route itself.
+func limitRoute(r *http.Request) string {
+	if successor, ok := r.Context().Value(contextKeySuccessor).(string); ok {
+		return successor
+	}
+	return routeTemplate(r)
+}
+
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			return tpl
+		}
+	}
+	return r.URL.Path
+}
+
+// clientIP returns the caller address. X-Forwarded-For is honoured only
+// behind a trusted proxy, otherwise clients could pick their own key.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			first, _, _ := strings.Cut(xff, ",")
+			return strings.TrimSpace(first)
+		}
+		if xrip := r.Header.Get("X-Real-IP"); xrip != "" {
+			return xrip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{cfg: cfg, now: time.Now, buckets: make(map[string]*bucket)}
+}
+
+func NewRateLimitMiddleware(rl *RateLimiter, l logging.Logger) Middleware {
+	return &rateLimitMiddleware{rl: rl, l: l}
+}
+
+func NewAuthRateLimitMiddleware(rl *RateLimiter, l logging.Logger) Middleware {
+	return &authRateLimitMiddleware{rl: rl, l: l}
+}
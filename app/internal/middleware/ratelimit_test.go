@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/config"
+	"github.com/gorilla/mux"
+)
+
+type MockLogger struct{}
+
+func (ml *MockLogger) Trace(args ...interface{})                   {}
+func (ml *MockLogger) Debug(args ...interface{})                   {}
+func (ml *MockLogger) Info(args ...interface{})                    {}
+func (ml *MockLogger) Warn(args ...interface{})                    {}
+func (ml *MockLogger) Warning(args ...interface{})                 {}
+func (ml *MockLogger) Error(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   {}
+func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
+func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
+func (ml *MockLogger) Infof(format string, args ...interface{})    {}
+func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
+func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
+func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
+func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
+
+func newTestRateLimitRouter(now *time.Time) *mux.Router {
+	cfg := config.RateLimitConfig{
+		Default: config.RateLimit{Rate: 100, Burst: 100},
+		Routes:  map[string]config.RateLimit{"/login": {Rate: 1, Burst: 2}},
+	}
+	rl := NewRateLimiter(cfg)
+	rl.now = func() time.Time { return *now }
+	rlmw := NewRateLimitMiddleware(rl, &MockLogger{})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	r := mux.NewRouter()
+	r.Handle("/login", rlmw.DoInMiddle(ok))
+	r.Handle("/flat", rlmw.DoInMiddle(ok))
+	return r
+}
+
+func TestRateLimit_PerRouteBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	r := newTestRateLimitRouter(&now)
+	tests := []struct {
+		name          string
+		path          string
+		remoteAddr    string
+		advance       time.Duration
+		wantCode      int
+		wantRemaining string
+	}{
+		{name: "first", path: "/login", remoteAddr: "1.1.1.1:1", wantCode: http.StatusOK, wantRemaining: "1"},
+		{name: "second", path: "/login", remoteAddr: "1.1.1.1:2", wantCode: http.StatusOK, wantRemaining: "0"},
+		{name: "burst exhausted", path: "/login", remoteAddr: "1.1.1.1:3", wantCode: http.StatusTooManyRequests, wantRemaining: "0"},
+		{name: "other ip", path: "/login", remoteAddr: "2.2.2.2:1", wantCode: http.StatusOK, wantRemaining: "1"},
+		{name: "other route", path: "/flat", remoteAddr: "1.1.1.1:4", wantCode: http.StatusOK, wantRemaining: "99"},
+		{name: "refilled", path: "/login", remoteAddr: "1.1.1.1:5", advance: time.Second, wantCode: http.StatusOK, wantRemaining: "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			req.RemoteAddr = tt.remoteAddr
+			req = req.WithContext(context.WithValue(req.Context(), ContextKeyRequestID, "test"))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+			if rr.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", rr.Code, tt.wantCode)
+			}
+			if got := rr.Header().Get("RateLimit-Remaining"); got != tt.wantRemaining {
+				t.Errorf("RateLimit-Remaining = %s, want %s", got, tt.wantRemaining)
+			}
+			if tt.wantCode == http.StatusTooManyRequests && rr.Header().Get("Retry-After") != "1" {
+				t.Errorf("Retry-After = %s, want 1", rr.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestRateLimit_KeyedByUser(t *testing.T) {
+	now := time.Unix(0, 0)
+	r := newTestRateLimitRouter(&now)
+	for i, uid := range []string{"u1", "u1", "u2"} {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = "1.1.1.1:1"
+		ctx := context.WithValue(req.Context(), ContextKeyRequestID, "test")
+		req = req.WithContext(context.WithValue(ctx, UserID, uid))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Errorf("request %d for %s: code = %d, want 200", i, uid, rr.Code)
+		}
+	}
+}
+
+func TestRateLimit_LegacySharesBucket(t *testing.T) {
+	cfg := config.RateLimitConfig{
+		Default: config.RateLimit{Rate: 100, Burst: 100},
+		Routes: map[string]config.RateLimit{
+			"/api/v1/login":            {Rate: 0.001, Burst: 2},
+			"/api/v1/house/{id}/flats": {Rate: 0.001, Burst: 1},
+		},
+	}
+	rlmw := NewRateLimitMiddleware(NewRateLimiter(cfg), &MockLogger{})
+	dmw := NewDeprecationMiddleware(time.Time{}, time.Time{}, "/api/v1", map[string]string{"/house/{id}": "/house/{id}/flats"}, &MockLogger{})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	r := mux.NewRouter()
+	r.Handle("/api/v1/login", rlmw.DoInMiddle(ok))
+	r.Handle("/api/v1/house/{id}/flats", rlmw.DoInMiddle(ok))
+	legacy := r.NewRoute().Subrouter()
+	legacy.Use(dmw.DoInMiddle)
+	legacy.Handle("/login", rlmw.DoInMiddle(ok))
+	legacy.Handle("/house/{id}", rlmw.DoInMiddle(ok))
+	tests := []struct {
+		path     string
+		wantCode int
+	}{
+		{path: "/api/v1/login", wantCode: http.StatusOK},
+		{path: "/login", wantCode: http.StatusOK},
+		{path: "/login", wantCode: http.StatusTooManyRequests},
+		{path: "/api/v1/login", wantCode: http.StatusTooManyRequests},
+		{path: "/house/1", wantCode: http.StatusOK},
+		{path: "/api/v1/house/1/flats", wantCode: http.StatusTooManyRequests},
+	}
+	for i, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.RemoteAddr = "1.1.1.1:1"
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != tt.wantCode {
+			t.Errorf("request %d to %s: code = %d, want %d", i, tt.path, rr.Code, tt.wantCode)
+		}
+	}
+}
+
+func TestAuthRateLimit_BeforeAuth(t *testing.T) {
+	rl := NewRateLimiter(config.RateLimitConfig{
+		Default: config.RateLimit{Rate: 100, Burst: 100},
+		Auth:    config.RateLimit{Rate: 0.001, Burst: 2},
+	})
+	reached := 0
+	// Every token is rejected, as when guessing them.
+	auth := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached++
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	h := NewAuthRateLimitMiddleware(rl, &MockLogger{}).DoInMiddle(auth)
+	for i, want := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodGet, "/flat/1", nil)
+		req.RemoteAddr = "1.1.1.1:1"
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != want {
+			t.Errorf("request %d: code = %d, want %d", i, rr.Code, want)
+		}
+	}
+	if reached != 2 {
+		t.Errorf("auth reached %d times, want 2", reached)
+	}
+}
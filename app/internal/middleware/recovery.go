@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/metrics"
+	"github.com/Polyrom/houses_api/pkg/logging"
+)
+
+type recoveryMiddleware struct {
+	l logging.Logger
+}
+
+func (recmw *recoveryMiddleware) DoInMiddle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w}
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			metrics.Panics.Add(1)
+			reqID := RequestID(r.Context())
+			recmw.l.Errorf("panic req_id=%s: %v\n%s", reqID, rec, debug.Stack())
+			if sw.status != 0 {
+				return
+			}
+			internalErr := errors.New("internal server error")
+			apierror.Write(sw, internalErr, reqID, http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(sw, r)
+	})
+}
+
+func NewRecoveryMiddleware(l logging.Logger) Middleware {
+	return &recoveryMiddleware{l: l}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Polyrom/houses_api/internal/metrics"
+)
+
+func TestRecovery(t *testing.T) {
+	recmw := NewRecoveryMiddleware(&MockLogger{})
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ContextKeyRequestID, "rid"))
+	rr := httptest.NewRecorder()
+	before := metrics.Panics.Value()
+	recmw.DoInMiddle(panicking).ServeHTTP(rr, req)
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("code = %d, want 500", rr.Code)
+	}
+	var body struct {
+		ReqID string `json:"req_id"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode error body: %v", err)
+	}
+	if body.ReqID != "rid" {
+		t.Errorf("req_id = %q, want rid", body.ReqID)
+	}
+	if metrics.Panics.Value() != before+1 {
+		t.Errorf("panic metric not incremented")
+	}
+}
+
+func TestRequestID_Missing(t *testing.T) {
+	if got := RequestID(context.Background()); got != "" {
+		t.Errorf("RequestID() = %q, want empty", got)
+	}
+}
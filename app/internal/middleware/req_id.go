@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/Polyrom/houses_api/pkg/client/postgres"
 	"github.com/Polyrom/houses_api/pkg/logging"
 	"github.com/google/uuid"
 )
@@ -12,22 +13,54 @@ type ContextKey string
 
 const ContextKeyRequestID ContextKey = "requestID"
 
+const (
+	RequestIDHeader = "X-Request-ID"
+	maxRequestIDLen = 64
+)
+
 type reqIDMiddleware struct {
 	l logging.Logger
 }
 
+// DoInMiddle reuses a valid incoming X-Request-ID (e.g. set by a gateway) or
+// generates a new one, echoes it in the response and tags DB sessions with it.
 func (ridmw *reqIDMiddleware) DoInMiddle(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
-		id := uuid.New()
-		ctx = context.WithValue(ctx, ContextKeyRequestID, id.String())
+		id := r.Header.Get(RequestIDHeader)
+		if !ValidRequestID(id) {
+			if id != "" {
+				ridmw.l.Warnf("invalid incoming %s ignored: %q", RequestIDHeader, id)
+			}
+			id = uuid.New().String()
+		}
+		ctx = context.WithValue(ctx, ContextKeyRequestID, id)
+		ctx = postgres.WithRequestID(ctx, id)
 		r = r.WithContext(ctx)
-		ridmw.l.Infof("request %s %s req_id=%s", r.Method, r.URL, ctx.Value(ContextKeyRequestID))
+		w.Header().Set(RequestIDHeader, id)
+		ridmw.l.Infof("request %s %s req_id=%s", r.Method, r.URL, id)
 		next.ServeHTTP(w, r)
-		ridmw.l.Infof("request %s %s handled req_id=%s", r.Method, r.URL, ctx.Value(ContextKeyRequestID))
+		ridmw.l.Infof("request %s %s handled req_id=%s", r.Method, r.URL, id)
 	})
 }
 
+// ValidRequestID accepts IDs of up to 64 characters from [A-Za-z0-9._:-],
+// which covers UUIDs and common tracing formats and is safe to log.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.', c == '_', c == ':', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 func NewReqIDMiddleware(l logging.Logger) Middleware {
 	return &reqIDMiddleware{l: l}
 }
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReqID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		wantSame bool
+	}{
+		{name: "valid incoming", incoming: "gw-1234.abcd:5_x", wantSame: true},
+		{name: "missing", incoming: "", wantSame: false},
+		{name: "too long", incoming: strings.Repeat("a", 65), wantSame: false},
+		{name: "bad charset", incoming: "id with spaces\n", wantSame: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			h := NewReqIDMiddleware(&MockLogger{}).DoInMiddle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = RequestID(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+			echoed := rr.Header().Get(RequestIDHeader)
+			if echoed == "" || echoed != seen {
+				t.Errorf("echoed id %q does not match context id %q", echoed, seen)
+			}
+			if (seen == tt.incoming) != tt.wantSame {
+				t.Errorf("context id = %q, incoming %q, wantSame %v", seen, tt.incoming, tt.wantSame)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockID is the key of the advisory lock held while migrations run, so that
+// several app instances starting at once do not migrate concurrently.
+const lockID int64 = 7_346_021_118
+
+// NilVersion is reported when no migration has been applied yet.
+const NilVersion = 0
+
+var fileNameRe = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+var (
+	// ErrDirty means schema_migrations is marked dirty. The migrator never
+	// writes dirty=true itself, since every script runs in a transaction, so
+	// this comes only from a failed golang-migrate run left in the database.
+	ErrDirty          = errors.New("database is dirty, fix it manually and force the version")
+	ErrNoChange       = errors.New("no change")
+	ErrUnknownVersion = errors.New("unknown migration version")
+)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version int
+	Name    string
+	Applied bool
+}
+
+type Status struct {
+	Version int
+	// Dirty is set only by golang-migrate, see ErrDirty.
+	Dirty      bool
+	Migrations []MigrationStatus
+}
+
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+	l          logging.Logger
+}
+
+// Load reads migration files from fsys and pairs up/down scripts by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]*Migration)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		m := fileNameRe.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		version, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("parse version of %s: %w", e.Name(), err)
+		}
+		if version <= NilVersion {
+			return nil, fmt.Errorf("invalid version in %s", e.Name())
+		}
+		content, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		mg, ok := byVersion[version]
+		if !ok {
+			mg = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mg
+		}
+		if mg.Name != m[2] {
+			return nil, fmt.Errorf("version %d has different names: %s and %s", version, mg.Name, m[2])
+		}
+		if m[3] == "up" {
+			mg.Up = string(content)
+		} else {
+			mg.Down = string(content)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mg := range byVersion {
+		if mg.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", mg.Version, mg.Name)
+		}
+		if mg.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s has no down script", mg.Version, mg.Name)
+		}
+		migrations = append(migrations, *mg)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up(ctx context.Context) error {
+	if len(m.migrations) == 0 {
+		return ErrNoChange
+	}
+	return m.Goto(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down rolls back the last applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := m.currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if current == NilVersion {
+			return ErrNoChange
+		}
+		idx := m.indexOf(current)
+		if idx < 0 {
+			return fmt.Errorf("%w: %d", ErrUnknownVersion, current)
+		}
+		target := NilVersion
+		if idx > 0 {
+			target = m.migrations[idx-1].Version
+		}
+		return m.migrate(ctx, conn, current, target)
+	})
+}
+
+// Goto migrates up or down until the schema is at the given version.
+// Version 0 rolls back every migration.
+func (m *Migrator) Goto(ctx context.Context, version int) error {
+	if version != NilVersion && m.indexOf(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := m.currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if current == version {
+			return ErrNoChange
+		}
+		return m.migrate(ctx, conn, current, version)
+	})
+}
+
+// Status reports the current schema version and which migrations are applied.
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	var st Status
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		q := `SELECT version, dirty FROM schema_migrations LIMIT 1`
+		err := conn.QueryRow(ctx, q).Scan(&st.Version, &st.Dirty)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return Status{}, err
+	}
+	st.Migrations = make([]MigrationStatus, 0, len(m.migrations))
+	for _, mg := range m.migrations {
+		st.Migrations = append(st.Migrations, MigrationStatus{
+			Version: mg.Version,
+			Name:    mg.Name,
+			Applied: mg.Version <= st.Version,
+		})
+	}
+	return st, nil
+}
+
+func (m *Migrator) migrate(ctx context.Context, conn *pgxpool.Conn, from, to int) error {
+	if from != NilVersion && m.indexOf(from) < 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, from)
+	}
+	if from < to {
+		for _, mg := range m.migrations {
+			if mg.Version <= from || mg.Version > to {
+				continue
+			}
+			m.l.Infof("applying migration %d_%s", mg.Version, mg.Name)
+			if err := m.apply(ctx, conn, mg.Up, mg.Version); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", mg.Version, mg.Name, err)
+			}
+		}
+		return nil
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mg := m.migrations[i]
+		if mg.Version > from || mg.Version <= to {
+			continue
+		}
+		prev := NilVersion
+		if i > 0 {
+			prev = m.migrations[i-1].Version
+		}
+		m.l.Infof("reverting migration %d_%s", mg.Version, mg.Name)
+		if err := m.apply(ctx, conn, mg.Down, prev); err != nil {
+			return fmt.Errorf("migration %d_%s down: %w", mg.Version, mg.Name, err)
+		}
+	}
+	return nil
+}
+
+// apply runs a single script and records the resulting version in one
+// transaction, so a failed script leaves the version untouched.
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, script string, version int) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err = tx.Exec(ctx, script); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `TRUNCATE schema_migrations`); err != nil {
+		return err
+	}
+	if version != NilVersion {
+		q := `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`
+		if _, err = tx.Exec(ctx, q, version); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (m *Migrator) currentVersion(ctx context.Context, conn *pgxpool.Conn) (int, error) {
+	var version int
+	var dirty bool
+	q := `SELECT version, dirty FROM schema_migrations LIMIT 1`
+	err := conn.QueryRow(ctx, q).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return NilVersion, nil
+	}
+	if err != nil {
+		return NilVersion, err
+	}
+	if dirty {
+		return version, ErrDirty
+	}
+	return version, nil
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory
+// lock. The schema_migrations table is compatible with golang-migrate.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	if _, err = conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID); err != nil {
+			m.l.Errorf("release migration lock: %v", err)
+		}
+	}()
+	q := `CREATE TABLE IF NOT EXISTS schema_migrations (
+					version BIGINT NOT NULL PRIMARY KEY,
+					dirty BOOLEAN NOT NULL
+				)`
+	if _, err = conn.Exec(ctx, q); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+func (m *Migrator) indexOf(version int) int {
+	for i, mg := range m.migrations {
+		if mg.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+func New(pool *pgxpool.Pool, fsys fs.FS, l logging.Logger) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{pool: pool, migrations: migrations, l: l}, nil
+}
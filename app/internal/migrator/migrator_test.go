@@ -0,0 +1,59 @@
+package migrator
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/Polyrom/houses_api/migrations"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    []int
+		wantErr bool
+	}{
+		{name: "sorted pairs", fsys: fstest.MapFS{
+			"002_b.up.sql":   {Data: []byte("b")},
+			"002_b.down.sql": {Data: []byte("-b")},
+			"001_a.up.sql":   {Data: []byte("a")},
+			"001_a.down.sql": {Data: []byte("-a")},
+			"README.md":      {Data: []byte("skip")},
+		}, want: []int{1, 2}, wantErr: false},
+		{name: "missing down", fsys: fstest.MapFS{
+			"001_a.up.sql": {Data: []byte("a")},
+		}, wantErr: true},
+		{name: "name mismatch", fsys: fstest.MapFS{
+			"001_a.up.sql":   {Data: []byte("a")},
+			"001_b.down.sql": {Data: []byte("-b")},
+		}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.fsys)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Load() returned %d migrations, want %d", len(got), len(tt.want))
+			}
+			for i, v := range tt.want {
+				if got[i].Version != v {
+					t.Errorf("Load()[%d].Version = %d, want %d", i, got[i].Version, v)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadEmbedded(t *testing.T) {
+	got, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Load(migrations.FS) error = %v", err)
+	}
+	if len(got) == 0 {
+		t.Errorf("no embedded migrations found")
+	}
+}
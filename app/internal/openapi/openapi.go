@@ -0,0 +1,158 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gorilla/mux"
+)
+
+const (
+	specURL = "/openapi.json"
+	docsURL = "/docs"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+//go:embed docs.html
+var docsHTML string
+
+var docsTemplate = template.Must(template.New("docs").Parse(docsHTML))
+
+// methodOrder lists operations of one path in the order readers expect.
+var methodOrder = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+func init() {
+	// Formats are not checked unless defined; user IDs are UUIDs.
+	openapi3.DefineStringFormatValidator("uuid", openapi3.NewRegexpFormatValidator(openapi3.FormatOfStringForUUIDOfRFC4122))
+	// Import files are validated row by row by the handler, which reports
+	// malformed rows instead of rejecting the whole file.
+	openapi3filter.RegisterBodyDecoder("text/csv", openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("application/x-ndjson", openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("application/jsonl", openapi3filter.FileBodyDecoder)
+	// Uploaded photos are checked by their content, not the declared type.
+	openapi3filter.RegisterBodyDecoder("image/jpeg", openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("image/png", openapi3filter.FileBodyDecoder)
+}
+
+// Load parses and validates the embedded OpenAPI document.
+func Load() (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(specYAML)
+	if err != nil {
+		return nil, err
+	}
+	if err = doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+type Handler struct {
+	spec []byte
+	docs []byte
+	l    logging.Logger
+}
+
+// NewHandler serves the document as JSON along with a docs page. The page is
+// rendered on the server, so it loads no scripts from elsewhere.
+func NewHandler(doc *openapi3.T, l logging.Logger) (*Handler, error) {
+	spec, err := doc.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	docs, err := renderDocs(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{spec: spec, docs: docs, l: l}, nil
+}
+
+type docsOperation struct {
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Parameters  []*openapi3.Parameter
+	Responses   []string
+}
+
+func renderDocs(doc *openapi3.T) ([]byte, error) {
+	var ops []docsOperation
+	paths := doc.Paths.Map()
+	keys := make([]string, 0, len(paths))
+	for p := range paths {
+		keys = append(keys, p)
+	}
+	slices.Sort(keys)
+	for _, p := range keys {
+		item := paths[p]
+		for _, method := range methodOrder {
+			op := item.GetOperation(method)
+			if op == nil {
+				continue
+			}
+			o := docsOperation{Method: method, Path: p, Summary: op.Summary, Description: op.Description}
+			for _, params := range []openapi3.Parameters{item.Parameters, op.Parameters} {
+				for _, ref := range params {
+					if ref.Value != nil {
+						o.Parameters = append(o.Parameters, ref.Value)
+					}
+				}
+			}
+			if op.Responses != nil {
+				for code := range op.Responses.Map() {
+					o.Responses = append(o.Responses, code)
+				}
+				slices.Sort(o.Responses)
+			}
+			ops = append(ops, o)
+		}
+	}
+	data := struct {
+		Title       string
+		Version     string
+		Description string
+		Operations  []docsOperation
+	}{Operations: ops}
+	if doc.Info != nil {
+		data.Title, data.Version, data.Description = doc.Info.Title, doc.Info.Version, strings.TrimSpace(doc.Info.Description)
+	}
+	var buf bytes.Buffer
+	if err := docsTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (h *Handler) Register(r *mux.Router) {
+	r.HandleFunc(specURL, h.Spec).Methods(http.MethodGet)
+	r.HandleFunc(docsURL, h.Docs).Methods(http.MethodGet)
+}
+
+func (h *Handler) Spec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, err := w.Write(h.spec)
+	if err != nil {
+		reqID := middleware.RequestID(r.Context())
+		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	_, _ = w.Write(h.docs)
+}
@@ -0,0 +1,145 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+const (
+	invalidContentTypePrefix = "header Content-Type has unexpected value"
+	eventStreamType          = "text/event-stream"
+	jsonType                 = "application/json"
+)
+
+type validationMiddleware struct {
+	router            routers.Router
+	validateResponses bool
+	l                 logging.Logger
+}
+
+// DoInMiddle rejects requests that do not match the spec. Routes missing from
+// the spec are passed through untouched. When response validation is on, the
+// response is buffered and replaced with a 500 if it does not match either.
+func (vmw *validationMiddleware) DoInMiddle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := vmw.router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		reqID := middleware.RequestID(r.Context())
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+				IncludeResponseStatus: true,
+			},
+		}
+		if err = openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			code, reqErr := requestErrorStatus(route, err)
+			vmw.l.Errorf("request does not match spec req_id=%s: %v", reqID, err)
+			apierror.Write(w, reqErr, reqID, code)
+			return
+		}
+		if !vmw.validateResponses || isStream(route) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rec := &bufferedWriter{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		respInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 rec.status,
+			Header:                 rec.header,
+			Options:                input.Options,
+		}
+		respInput.SetBodyBytes(rec.body.Bytes())
+		if err = openapi3filter.ValidateResponse(r.Context(), respInput); err != nil {
+			vmw.l.Errorf("response does not match spec req_id=%s: %v", reqID, err)
+			apierror.Write(w, errors.New("response does not match API specification"), reqID, http.StatusInternalServerError)
+			return
+		}
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.status)
+		_, _ = w.Write(rec.body.Bytes())
+	})
+}
+
+// isStream reports whether the operation answers with an event stream or a
+// file download, which cannot be buffered for validation.
+func isStream(route *routers.Route) bool {
+	resp := route.Operation.Responses.Status(http.StatusOK)
+	if resp == nil || resp.Value == nil || len(resp.Value.Content) == 0 {
+		return false
+	}
+	return resp.Value.Content.Get(eventStreamType) != nil || resp.Value.Content.Get(jsonType) == nil
+}
+
+// requestErrorStatus maps a validation error to the status the handlers
+// themselves would respond with, keeping the first line as the message.
+func requestErrorStatus(route *routers.Route, err error) (int, error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge, fmt.Errorf("request body must not be larger than %d bytes", maxBytesErr.Limit)
+	}
+	var reqErr *openapi3filter.RequestError
+	if errors.As(err, &reqErr) && strings.HasPrefix(reqErr.Reason, invalidContentTypePrefix) {
+		types := []string{"application/json"}
+		if body := route.Operation.RequestBody; body != nil && body.Value != nil && len(body.Value.Content) > 0 {
+			types = types[:0]
+			for ct := range body.Value.Content {
+				types = append(types, ct)
+			}
+			slices.Sort(types)
+		}
+		return http.StatusUnsupportedMediaType, fmt.Errorf("Content-Type must be %s", strings.Join(types, " or "))
+	}
+	msg, _, _ := strings.Cut(err.Error(), "\n")
+	return http.StatusBadRequest, errors.New(msg)
+}
+
+// bufferedWriter holds the whole response until it has been validated.
+type bufferedWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (bw *bufferedWriter) Header() http.Header {
+	return bw.header
+}
+
+func (bw *bufferedWriter) WriteHeader(status int) {
+	bw.status = status
+}
+
+func (bw *bufferedWriter) Write(b []byte) (int, error) {
+	return bw.body.Write(b)
+}
+
+// NewValidationMiddleware validates requests against doc and, if
+// validateResponses is set, responses too. Response validation buffers every
+// body, so it is meant for development only.
+func NewValidationMiddleware(doc *openapi3.T, validateResponses bool, l logging.Logger) (middleware.Middleware, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &validationMiddleware{router: router, validateResponses: validateResponses, l: l}, nil
+}
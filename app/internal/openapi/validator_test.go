@@ -0,0 +1,88 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type MockLogger struct{}
+
+func (ml *MockLogger) Trace(args ...interface{})                   {}
+func (ml *MockLogger) Debug(args ...interface{})                   {}
+func (ml *MockLogger) Info(args ...interface{})                    {}
+func (ml *MockLogger) Warn(args ...interface{})                    {}
+func (ml *MockLogger) Warning(args ...interface{})                 {}
+func (ml *MockLogger) Error(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   {}
+func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
+func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
+func (ml *MockLogger) Infof(format string, args ...interface{})    {}
+func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
+func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
+func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
+func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
+
+func TestValidationMiddleware(t *testing.T) {
+	doc, err := Load()
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+	tokenHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"token":"abc"}`))
+	})
+	badHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"tok":1}`))
+	})
+	const validLogin = `{"user_id":"0b6c8f1e-3a52-4d5e-9c55-2f0f1c1f7c11","password":"secret"}`
+	const photoForm = "multipart/form-data; boundary=b"
+	photoBody := func(kind string) string {
+		return "--b\r\nContent-Disposition: form-data; name=\"kind\"\r\n\r\n" + kind +
+			"\r\n--b\r\nContent-Disposition: form-data; name=\"file\"; filename=\"plan.png\"\r\nContent-Type: image/png\r\n\r\n\x89PNG" +
+			"\r\n--b--\r\n"
+	}
+	tests := []struct {
+		name              string
+		method            string
+		path              string
+		contentType       string
+		body              string
+		handler           http.Handler
+		validateResponses bool
+		wantCode          int
+	}{
+		{name: "valid request", method: http.MethodPost, path: "/api/v1/login", contentType: "application/json", body: validLogin, handler: tokenHandler, wantCode: http.StatusOK},
+		{name: "legacy route", method: http.MethodPost, path: "/login", contentType: "application/json", body: validLogin, handler: tokenHandler, wantCode: http.StatusOK},
+		{name: "unknown field", method: http.MethodPost, path: "/api/v1/login", contentType: "application/json", body: `{"user_id":"0b6c8f1e-3a52-4d5e-9c55-2f0f1c1f7c11","password":"x","x":1}`, handler: tokenHandler, wantCode: http.StatusBadRequest},
+		{name: "invalid uuid", method: http.MethodPost, path: "/api/v1/login", contentType: "application/json", body: `{"user_id":"nope","password":"x"}`, handler: tokenHandler, wantCode: http.StatusBadRequest},
+		{name: "wrong content type", method: http.MethodPost, path: "/api/v1/login", contentType: "text/plain", body: validLogin, handler: tokenHandler, wantCode: http.StatusUnsupportedMediaType},
+		{name: "photo upload", method: http.MethodPost, path: "/api/v1/flat/1/photos", contentType: photoForm, body: photoBody("floor_plan"), handler: tokenHandler, wantCode: http.StatusOK},
+		{name: "bad photo kind", method: http.MethodPost, path: "/api/v1/flat/1/photos", contentType: photoForm, body: photoBody("selfie"), handler: tokenHandler, wantCode: http.StatusBadRequest},
+		{name: "bad query parameter", method: http.MethodGet, path: "/api/v1/dummyLogin?user_type=admin", handler: tokenHandler, wantCode: http.StatusBadRequest},
+		{name: "route not in spec", method: http.MethodGet, path: "/debug/vars", handler: badHandler, validateResponses: true, wantCode: http.StatusOK},
+		{name: "response not validated", method: http.MethodGet, path: "/api/v1/dummyLogin?user_type=client", handler: badHandler, wantCode: http.StatusOK},
+		{name: "valid response", method: http.MethodGet, path: "/api/v1/dummyLogin?user_type=client", handler: tokenHandler, validateResponses: true, wantCode: http.StatusOK},
+		{name: "invalid response", method: http.MethodGet, path: "/api/v1/dummyLogin?user_type=client", handler: badHandler, validateResponses: true, wantCode: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vmw, err := NewValidationMiddleware(doc, tt.validateResponses, &MockLogger{})
+			if err != nil {
+				t.Fatalf("new middleware: %v", err)
+			}
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rr := httptest.NewRecorder()
+			vmw.DoInMiddle(tt.handler).ServeHTTP(rr, req)
+			if rr.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d, body %s", rr.Code, tt.wantCode, rr.Body.String())
+			}
+		})
+	}
+}
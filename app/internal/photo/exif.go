@@ -0,0 +1,76 @@
+package photo
+
+import "encoding/binary"
+
+const (
+	jpegSOS        = 0xDA
+	jpegAPP1       = 0xE1
+	orientationTag = 0x0112
+	exifHeader     = "Exif\x00\x00"
+	ifdEntrySize   = 12
+)
+
+// orientation returns the EXIF orientation of a JPEG file, 1 if the file
+// has none. Only the first IFD is looked at, where cameras put it.
+func orientation(b []byte) int {
+	if len(b) < 2 || b[0] != 0xFF || b[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(b); {
+		if b[i] != 0xFF {
+			return 1
+		}
+		marker := b[i+1]
+		if marker == 0xFF {
+			// fill byte before a marker
+			i++
+			continue
+		}
+		if marker == jpegSOS {
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(b[i+2:]))
+		if size < 2 || i+2+size > len(b) {
+			return 1
+		}
+		seg := b[i+4 : i+2+size]
+		if marker == jpegAPP1 && len(seg) > len(exifHeader) && string(seg[:len(exifHeader)]) == exifHeader {
+			return tiffOrientation(seg[len(exifHeader):])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+func tiffOrientation(t []byte) int {
+	if len(t) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(t[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(t[4:]))
+	if ifd < 8 || ifd+2 > len(t) {
+		return 1
+	}
+	n := int(order.Uint16(t[ifd:]))
+	for i := 0; i < n; i++ {
+		e := ifd + 2 + i*ifdEntrySize
+		if e+ifdEntrySize > len(t) {
+			return 1
+		}
+		if order.Uint16(t[e:]) == orientationTag {
+			if o := int(order.Uint16(t[e+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 1
+		}
+	}
+	return 1
+}
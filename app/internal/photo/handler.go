@@ -0,0 +1,291 @@
+package photo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/handlers"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/gorilla/mux"
+)
+
+const (
+	housePhotosURL = "/house/{id}/photos"
+	flatPhotosURL  = "/flat/{id}/photos"
+	photoURL       = "/photos/{id}"
+	thumbnailURL   = "/photos/{id}/thumbnail"
+
+	fileField = "file"
+	kindField = "kind"
+	// maxKindBytes bounds the kind form field.
+	maxKindBytes = 64
+)
+
+// UploadURLs are the routes taking photo files, which need a larger body
+// limit than the JSON routes.
+func UploadURLs() []string {
+	return []string{housePhotosURL, flatPhotosURL}
+}
+
+type handler struct {
+	aumw  middleware.Middleware
+	modmw middleware.Middleware
+	s     *Service
+	l     logging.Logger
+	// photoRoute and thumbnailRoute build the URLs of photo files.
+	photoRoute     *mux.Route
+	thumbnailRoute *mux.Route
+}
+
+func NewHandler(aumw middleware.Middleware, modmw middleware.Middleware, s *Service, l logging.Logger) handlers.Handler {
+	return &handler{aumw: aumw, modmw: modmw, s: s, l: l}
+}
+
+// Register mounts the photo routes. Flat owners upload photos of their
+// flats too, so only house uploads are limited to moderators by middleware.
+func (h *handler) Register(r *mux.Router) {
+	r.Handle(housePhotosURL, h.modmw.DoInMiddle(http.HandlerFunc(h.UploadHouse))).Methods(http.MethodPost)
+	r.Handle(housePhotosURL, h.aumw.DoInMiddle(http.HandlerFunc(h.ListHouse))).Methods(http.MethodGet)
+	r.Handle(flatPhotosURL, h.aumw.DoInMiddle(http.HandlerFunc(h.UploadFlat))).Methods(http.MethodPost)
+	r.Handle(flatPhotosURL, h.aumw.DoInMiddle(http.HandlerFunc(h.ListFlat))).Methods(http.MethodGet)
+	h.photoRoute = r.Handle(photoURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Serve))).Methods(http.MethodGet)
+	r.Handle(photoURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Delete))).Methods(http.MethodDelete)
+	h.thumbnailRoute = r.Handle(thumbnailURL, h.aumw.DoInMiddle(http.HandlerFunc(h.ServeThumbnail))).Methods(http.MethodGet)
+}
+
+func (h *handler) UploadHouse(w http.ResponseWriter, r *http.Request) {
+	if id, ok := h.targetID(w, r, "house"); ok {
+		h.upload(w, r, Target{HouseID: id})
+	}
+}
+
+func (h *handler) UploadFlat(w http.ResponseWriter, r *http.Request) {
+	if id, ok := h.targetID(w, r, "flat"); ok {
+		h.upload(w, r, Target{FlatID: id})
+	}
+}
+
+func (h *handler) ListHouse(w http.ResponseWriter, r *http.Request) {
+	if id, ok := h.targetID(w, r, "house"); ok {
+		h.list(w, r, Target{HouseID: id})
+	}
+}
+
+func (h *handler) ListFlat(w http.ResponseWriter, r *http.Request) {
+	if id, ok := h.targetID(w, r, "flat"); ok {
+		h.list(w, r, Target{FlatID: id})
+	}
+}
+
+// upload takes a multipart/form-data body with the image in the file field
+// and optionally its kind, photo by default.
+func (h *handler) upload(w http.ResponseWriter, r *http.Request, t Target) {
+	reqID := middleware.RequestID(r.Context())
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		ctErr := errors.New("Content-Type must be multipart/form-data")
+		h.l.Errorf("unsupported media type req_id=%s: %v", reqID, ctErr)
+		apierror.Write(w, ctErr, reqID, http.StatusUnsupportedMediaType)
+		return
+	}
+	kind, file, err := h.readForm(r)
+	if err != nil {
+		code := http.StatusBadRequest
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			code = http.StatusRequestEntityTooLarge
+			err = fmt.Errorf("request body must not be larger than %d bytes", maxBytesErr.Limit)
+		}
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, code)
+		return
+	}
+	p, err := h.s.Upload(r.Context(), t, kind, bytes.NewReader(file))
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.respond(w, r, h.withURLs(p))
+}
+
+func (h *handler) readForm(r *http.Request) (Kind, []byte, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return "", nil, err
+	}
+	kind := KindPhoto
+	var file []byte
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		switch part.FormName() {
+		case kindField:
+			b, err := io.ReadAll(io.LimitReader(part, maxKindBytes))
+			if err != nil {
+				return "", nil, err
+			}
+			kind = Kind(strings.TrimSpace(string(b)))
+			if kind != KindPhoto && kind != KindFloorPlan {
+				return "", nil, fmt.Errorf("kind must be one of %s, %s", KindPhoto, KindFloorPlan)
+			}
+		case fileField:
+			if file != nil {
+				return "", nil, errors.New("only one file can be uploaded at a time")
+			}
+			// one byte over the limit lets the service report the file as too large
+			if file, err = io.ReadAll(io.LimitReader(part, h.s.cfg.MaxBytes+1)); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	if file == nil {
+		return "", nil, errors.New("file is required")
+	}
+	return kind, file, nil
+}
+
+func (h *handler) list(w http.ResponseWriter, r *http.Request, t Target) {
+	ps, err := h.s.List(r.Context(), t)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	for i := range ps {
+		ps[i] = h.withURLs(ps[i])
+	}
+	h.respond(w, r, ps)
+}
+
+func (h *handler) Serve(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, false)
+}
+
+func (h *handler) ServeThumbnail(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r, true)
+}
+
+// serve writes a photo file. Files never change once uploaded, so clients
+// may keep them for cache_max_age and revalidate with If-None-Match. The
+// cache is private because photos of unapproved flats are not public.
+func (h *handler) serve(w http.ResponseWriter, r *http.Request, thumbnail bool) {
+	id, ok := h.photoID(w, r)
+	if !ok {
+		return
+	}
+	p, rc, err := h.s.Open(r.Context(), id, thumbnail)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	defer rc.Close()
+	key, size := p.Key, p.Size
+	if thumbnail {
+		key, size = p.ThumbnailKey, p.ThumbnailSize
+	}
+	etag := `"` + strings.TrimSuffix(path.Base(key), path.Ext(key)) + `"`
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d, immutable", int(h.s.cfg.CacheMaxAge.Seconds())))
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", p.CreatedAt.UTC().Format(http.TimeFormat))
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", p.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if _, err = io.Copy(w, rc); err != nil {
+		h.l.Errorf("failed to send photo req_id=%s: %v", middleware.RequestID(r.Context()), err)
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists etag.
+func etagMatches(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == etag || v == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *handler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.photoID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.s.Delete(r.Context(), id); err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) withURLs(p Photo) Photo {
+	id := strconv.Itoa(int(p.ID))
+	if u, err := h.photoRoute.URLPath("id", id); err == nil {
+		p.URL = u.Path
+	}
+	if u, err := h.thumbnailRoute.URLPath("id", id); err == nil {
+		p.ThumbnailURL = u.Path
+	}
+	return p
+}
+
+func (h *handler) targetID(w http.ResponseWriter, r *http.Request, target string) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id < 1 {
+		reqID := middleware.RequestID(r.Context())
+		invalidIDErr := fmt.Errorf("invalid %s id", target)
+		h.l.Errorf("bad request req_id=%s: %v", reqID, invalidIDErr)
+		apierror.Write(w, invalidIDErr, reqID, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *handler) photoID(w http.ResponseWriter, r *http.Request) (PhotoID, bool) {
+	id, ok := h.targetID(w, r, "photo")
+	return PhotoID(id), ok
+}
+
+func (h *handler) fail(w http.ResponseWriter, r *http.Request, err error) {
+	reqID := middleware.RequestID(r.Context())
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, ErrForbidden):
+		code = http.StatusForbidden
+	case errors.Is(err, ErrTooLarge):
+		code = http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrUnsupportedType):
+		code = http.StatusUnsupportedMediaType
+	case errors.Is(err, ErrInvalidImage), errors.Is(err, ErrTooManyPixels):
+		code = http.StatusBadRequest
+	}
+	h.l.Errorf("%s req_id=%s: %v", strings.ToLower(http.StatusText(code)), reqID, err)
+	apierror.Write(w, err, reqID, code)
+}
+
+func (h *handler) respond(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.l.Errorf("internal error req_id=%s: %v", middleware.RequestID(r.Context()), err)
+	}
+}
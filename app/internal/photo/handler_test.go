@@ -0,0 +1,348 @@
+package photo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/blob"
+	"github.com/Polyrom/houses_api/internal/config"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/internal/middleware/middlewaretest"
+	"github.com/gorilla/mux"
+)
+
+type MockLogger struct{}
+
+func (ml *MockLogger) Trace(args ...interface{})                   {}
+func (ml *MockLogger) Debug(args ...interface{})                   {}
+func (ml *MockLogger) Info(args ...interface{})                    {}
+func (ml *MockLogger) Warn(args ...interface{})                    {}
+func (ml *MockLogger) Warning(args ...interface{})                 {}
+func (ml *MockLogger) Error(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   {}
+func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
+func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
+func (ml *MockLogger) Infof(format string, args ...interface{})    {}
+func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
+func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
+func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
+func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
+
+const ownerID = "3f1c2a9e-5b1d-4c7e-9a0b-1d2e3f4a5b6c"
+
+// MockPhotoRepo knows house 1, approved flat 1 and flat 2 on moderation
+// owned by ownerID.
+type MockPhotoRepo struct {
+	photos []Photo
+}
+
+func (mr *MockPhotoRepo) HouseExists(ctx context.Context, id int) (bool, error) {
+	return id == 1, nil
+}
+
+func (mr *MockPhotoRepo) Flat(ctx context.Context, id int) (FlatAccess, error) {
+	owner := ownerID
+	switch id {
+	case 1:
+		return FlatAccess{Status: "approved"}, nil
+	case 2:
+		return FlatAccess{Status: "on moderation", OwnerID: &owner}, nil
+	}
+	return FlatAccess{}, ErrNotFound
+}
+
+func (mr *MockPhotoRepo) Create(ctx context.Context, p Photo) (Photo, error) {
+	p.ID = PhotoID(len(mr.photos) + 1)
+	p.CreatedAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mr.photos = append(mr.photos, p)
+	return p, nil
+}
+
+func (mr *MockPhotoRepo) Get(ctx context.Context, id PhotoID) (Photo, *FlatAccess, error) {
+	for _, p := range mr.photos {
+		if p.ID != id {
+			continue
+		}
+		if p.FlatID == nil {
+			return p, nil, nil
+		}
+		fa, err := mr.Flat(ctx, *p.FlatID)
+		return p, &fa, err
+	}
+	return Photo{}, nil, ErrNotFound
+}
+
+func (mr *MockPhotoRepo) List(ctx context.Context, t Target) ([]Photo, error) {
+	var ps []Photo
+	for _, p := range mr.photos {
+		if (p.FlatID != nil && *p.FlatID == t.FlatID) || (p.HouseID != nil && *p.HouseID == t.HouseID) {
+			ps = append(ps, p)
+		}
+	}
+	return ps, nil
+}
+
+func (mr *MockPhotoRepo) Delete(ctx context.Context, id PhotoID) error {
+	for i, p := range mr.photos {
+		if p.ID == id {
+			mr.photos = append(mr.photos[:i], mr.photos[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+type MockStorage struct {
+	blobs map[string][]byte
+}
+
+func (ms *MockStorage) Put(ctx context.Context, key string, r io.Reader) error {
+	b, err := io.ReadAll(r)
+	ms.blobs[key] = b
+	return err
+}
+
+func (ms *MockStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	b, ok := ms.blobs[key]
+	if !ok {
+		return nil, blob.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (ms *MockStorage) Delete(ctx context.Context, key string) error {
+	delete(ms.blobs, key)
+	return nil
+}
+
+func newRouter() (*mux.Router, *MockStorage) {
+	storage := &MockStorage{blobs: make(map[string][]byte)}
+	cfg := config.PhotosConfig{MaxBytes: 1 << 20, ThumbnailSize: 64, CacheMaxAge: time.Hour}
+	router := mux.NewRouter()
+	s := NewService(&MockPhotoRepo{}, storage, cfg, &MockLogger{})
+	NewHandler(middlewaretest.Auth{}, middlewaretest.Auth{}, s, &MockLogger{}).Register(router)
+	return router, storage
+}
+
+func uploadRequest(t *testing.T, url string, file []byte, kind Kind) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if kind != "" {
+		if err := mw.WriteField(kindField, string(kind)); err != nil {
+			t.Fatalf("write kind: %v", err)
+		}
+	}
+	fw, err := mw.CreateFormFile(fileField, "plan.png")
+	if err != nil {
+		t.Fatalf("create file: %v", err)
+	}
+	if _, err = fw.Write(file); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err = mw.Close(); err != nil {
+		t.Fatalf("close form: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, url, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func as(req *http.Request, role middleware.Role, userID string) *http.Request {
+	req.Header.Set("X-Role", string(role))
+	req.Header.Set("X-User", userID)
+	return req
+}
+
+func TestHandler_UploadFlat(t *testing.T) {
+	router, storage := newRouter()
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, as(uploadRequest(t, "/flat/2/photos", encodePNG(t, halves(200, 100)), KindFloorPlan), middleware.Client, ownerID))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("code = %d, want 200: %s", rr.Code, rr.Body)
+	}
+	var p Photo
+	if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if p.ID != 1 || p.Kind != KindFloorPlan || p.ContentType != pngType || p.Width != 200 || p.Height != 100 {
+		t.Errorf("photo = %+v", p)
+	}
+	if p.URL != "/photos/1" || p.ThumbnailURL != "/photos/1/thumbnail" {
+		t.Errorf("urls = %q, %q", p.URL, p.ThumbnailURL)
+	}
+	if len(storage.blobs) != 2 {
+		t.Errorf("stored %d files, want the photo and its thumbnail", len(storage.blobs))
+	}
+}
+
+func TestService_UploadWaitsForProcessing(t *testing.T) {
+	cfg := config.PhotosConfig{MaxBytes: 1 << 20, ThumbnailSize: 64, MaxProcessing: 1}
+	storage := &MockStorage{blobs: make(map[string][]byte)}
+	s := NewService(&MockPhotoRepo{}, storage, cfg, &MockLogger{})
+	s.processing <- struct{}{}
+	ctx := context.WithValue(context.Background(), middleware.UserRole, middleware.Moderator)
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.Upload(waitCtx, Target{HouseID: 1}, KindPhoto, bytes.NewReader(encodePNG(t, halves(4, 4)))); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Upload with no free slot = %v, want %v", err, context.DeadlineExceeded)
+	}
+	<-s.processing
+	if _, err := s.Upload(ctx, Target{HouseID: 1}, KindPhoto, bytes.NewReader(encodePNG(t, halves(4, 4)))); err != nil {
+		t.Errorf("Upload with a free slot = %v", err)
+	}
+}
+
+func TestHandler_UploadForbidden(t *testing.T) {
+	router, _ := newRouter()
+	tests := []struct {
+		name string
+		url  string
+		code int
+	}{
+		{name: "house", url: "/house/1/photos", code: http.StatusForbidden},
+		{name: "approved flat of someone else", url: "/flat/1/photos", code: http.StatusForbidden},
+		{name: "unapproved flat of someone else", url: "/flat/2/photos", code: http.StatusNotFound},
+		{name: "missing flat", url: "/flat/3/photos", code: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, as(uploadRequest(t, tt.url, encodePNG(t, halves(4, 4)), ""), middleware.Client, "someone"))
+			if rr.Code != tt.code {
+				t.Errorf("code = %d, want %d: %s", rr.Code, tt.code, rr.Body)
+			}
+		})
+	}
+}
+
+func TestHandler_UploadInvalid(t *testing.T) {
+	router, _ := newRouter()
+	jsonReq := httptest.NewRequest(http.MethodPost, "/house/1/photos", strings.NewReader(`{}`))
+	jsonReq.Header.Set("Content-Type", "application/json")
+	tests := []struct {
+		name string
+		req  *http.Request
+		code int
+	}{
+		{name: "not multipart", req: jsonReq, code: http.StatusUnsupportedMediaType},
+		{name: "not an image", req: uploadRequest(t, "/house/1/photos", []byte("hello"), ""), code: http.StatusUnsupportedMediaType},
+		{name: "bad kind", req: uploadRequest(t, "/house/1/photos", encodePNG(t, halves(4, 4)), "selfie"), code: http.StatusBadRequest},
+		{name: "too large", req: uploadRequest(t, "/house/1/photos", make([]byte, 1<<20+1), ""), code: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, as(tt.req, middleware.Moderator, "moderator"))
+			if rr.Code != tt.code {
+				t.Errorf("code = %d, want %d: %s", rr.Code, tt.code, rr.Body)
+			}
+		})
+	}
+}
+
+func TestHandler_Visibility(t *testing.T) {
+	router, _ := newRouter()
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, as(uploadRequest(t, "/flat/2/photos", encodePNG(t, halves(4, 4)), ""), middleware.Client, ownerID))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("upload code = %d: %s", rr.Code, rr.Body)
+	}
+	tests := []struct {
+		name   string
+		role   middleware.Role
+		userID string
+		code   int
+	}{
+		{name: "owner", role: middleware.Client, userID: ownerID, code: http.StatusOK},
+		{name: "moderator", role: middleware.Moderator, userID: "moderator", code: http.StatusOK},
+		{name: "other client", role: middleware.Client, userID: "someone", code: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, url := range []string{"/flat/2/photos", "/photos/1", "/photos/1/thumbnail"} {
+				rr := httptest.NewRecorder()
+				router.ServeHTTP(rr, as(httptest.NewRequest(http.MethodGet, url, nil), tt.role, tt.userID))
+				if rr.Code != tt.code {
+					t.Errorf("GET %s code = %d, want %d", url, rr.Code, tt.code)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_ServeCaching(t *testing.T) {
+	router, storage := newRouter()
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, as(uploadRequest(t, "/house/1/photos", encodeJPEG(t, halves(16, 8), 0), ""), middleware.Moderator, "moderator"))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("upload code = %d: %s", rr.Code, rr.Body)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, as(httptest.NewRequest(http.MethodGet, "/photos/1", nil), middleware.Client, "someone"))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("code = %d, want 200: %s", rr.Code, rr.Body)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != jpegType {
+		t.Errorf("Content-Type = %q, want %q", ct, jpegType)
+	}
+	if cc := rr.Header().Get("Cache-Control"); cc != "private, max-age=3600, immutable" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+	etag := rr.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("ETag is missing")
+	}
+	var stored []byte
+	for key, b := range storage.blobs {
+		if !strings.HasSuffix(key, "_thumb.jpg") {
+			stored = b
+		}
+	}
+	if !bytes.Equal(rr.Body.Bytes(), stored) {
+		t.Error("body is not the stored photo")
+	}
+
+	req := as(httptest.NewRequest(http.MethodGet, "/photos/1", nil), middleware.Client, "someone")
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Errorf("code = %d with %d bytes, want 304 without a body", rr.Code, rr.Body.Len())
+	}
+}
+
+func TestHandler_Delete(t *testing.T) {
+	router, storage := newRouter()
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, as(uploadRequest(t, "/flat/1/photos", encodePNG(t, halves(4, 4)), ""), middleware.Moderator, "moderator"))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("upload code = %d: %s", rr.Code, rr.Body)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, as(httptest.NewRequest(http.MethodDelete, "/photos/1", nil), middleware.Client, "someone"))
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("client code = %d, want 403", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, as(httptest.NewRequest(http.MethodDelete, "/photos/1", nil), middleware.Moderator, "moderator"))
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("moderator code = %d, want 204: %s", rr.Code, rr.Body)
+	}
+	if len(storage.blobs) != 0 {
+		t.Errorf("%d files left after delete", len(storage.blobs))
+	}
+}
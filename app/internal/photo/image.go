@@ -0,0 +1,169 @@
+package photo
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const (
+	jpegType = "image/jpeg"
+	pngType  = "image/png"
+
+	// maxPixels bounds the memory a decoded upload takes.
+	maxPixels        = 24_000_000
+	jpegQuality      = 90
+	thumbnailQuality = 80
+)
+
+var (
+	ErrUnsupportedType = errors.New("only JPEG and PNG images are accepted")
+	ErrInvalidImage    = errors.New("file is not a valid image")
+	ErrTooManyPixels   = errors.New("image has too many pixels")
+)
+
+// processed is an upload ready to be stored.
+type processed struct {
+	contentType string
+	width       int
+	height      int
+	image       []byte
+	thumbnail   []byte
+}
+
+// process checks that b is a JPEG or PNG image by its content and encodes
+// it again, which drops EXIF and any other metadata. The EXIF orientation
+// is applied to the pixels first, so the image does not turn sideways.
+// The thumbnail fits in a thumbSize square and has the same type.
+func process(b []byte, thumbSize int) (processed, error) {
+	contentType := http.DetectContentType(b)
+	if contentType != jpegType && contentType != pngType {
+		return processed{}, ErrUnsupportedType
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(b))
+	if err != nil {
+		return processed{}, ErrInvalidImage
+	}
+	if cfg.Width < 1 || cfg.Height < 1 {
+		return processed{}, ErrInvalidImage
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return processed{}, ErrTooManyPixels
+	}
+	img, _, err := image.Decode(bytes.NewReader(b))
+	if err != nil {
+		return processed{}, ErrInvalidImage
+	}
+	if contentType == jpegType {
+		img = orient(img, orientation(b))
+	}
+	p := processed{contentType: contentType, width: img.Bounds().Dx(), height: img.Bounds().Dy()}
+	if p.image, err = encode(img, contentType, jpegQuality); err != nil {
+		return processed{}, err
+	}
+	if p.thumbnail, err = encode(thumbnail(img, thumbSize), contentType, thumbnailQuality); err != nil {
+		return processed{}, err
+	}
+	return p, nil
+}
+
+func encode(img image.Image, contentType string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == pngType {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	}
+	return buf.Bytes(), err
+}
+
+// thumbnail scales img down to fit in a size square averaging the pixels
+// each thumbnail pixel covers. Smaller images are returned as they are.
+func thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if sw <= size && sh <= size {
+		return img
+	}
+	dw, dh := size, size
+	if sw > sh {
+		dh = max(1, sh*size/sw)
+	} else {
+		dw = max(1, sw*size/sh)
+	}
+	src := toRGBA(img)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			off := y*dst.Stride + x*4
+			for i := range sum {
+				dst.Pix[off+i] = uint8(sum[i] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// toRGBA copies img into a premultiplied RGBA image starting at 0,0, so
+// averaging transparent pixels does not darken the edges.
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// orient turns img upright according to an EXIF orientation value.
+func orient(img image.Image, o int) image.Image {
+	if o < 2 || o > 8 {
+		return img
+	}
+	src := toRGBA(img)
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if o >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch o {
+			case 2: // mirrored
+				sx, sy = w-1-x, y
+			case 3: // upside down
+				sx, sy = w-1-x, h-1-y
+			case 4: // mirrored upside down
+				sx, sy = x, h-1-y
+			case 5: // mirrored along the main diagonal
+				sx, sy = y, x
+			case 6: // needs a clockwise turn
+				sx, sy = y, h-1-x
+			case 7: // mirrored along the other diagonal
+				sx, sy = w-1-y, h-1-x
+			case 8: // needs a counterclockwise turn
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:sy*src.Stride+sx*4+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,167 @@
+package photo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// exifOrientation builds an APP1 segment holding only an orientation tag.
+func exifOrientation(o uint16) []byte {
+	tiff := []byte("II\x2a\x00")
+	tiff = binary.LittleEndian.AppendUint32(tiff, 8)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, orientationTag)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, o)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	payload := append([]byte(exifHeader), tiff...)
+	seg := []byte{0xFF, jpegAPP1}
+	seg = binary.BigEndian.AppendUint16(seg, uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// halves returns a w×h image, red on the left half and blue on the right.
+func halves(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{B: 255, A: 255}
+			if x < w/2 {
+				c = color.RGBA{R: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image, o uint16) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	b := buf.Bytes()
+	if o == 0 {
+		return b
+	}
+	return append(append(append([]byte{}, b[:2]...), exifOrientation(o)...), b[2:]...)
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestOrientation(t *testing.T) {
+	img := halves(16, 8)
+	for _, o := range []uint16{0, 3, 6, 8} {
+		want := int(o)
+		if o == 0 {
+			want = 1
+		}
+		if got := orientation(encodeJPEG(t, img, o)); got != want {
+			t.Errorf("orientation = %d, want %d", got, want)
+		}
+	}
+	if got := orientation([]byte("not a jpeg")); got != 1 {
+		t.Errorf("orientation of garbage = %d, want 1", got)
+	}
+}
+
+func TestProcess_RotatesAndStripsExif(t *testing.T) {
+	p, err := process(encodeJPEG(t, halves(16, 8), 6), 320)
+	if err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if p.contentType != jpegType || p.width != 8 || p.height != 16 {
+		t.Fatalf("got %s %dx%d, want image/jpeg 8x16", p.contentType, p.width, p.height)
+	}
+	if bytes.Contains(p.image, []byte("Exif")) {
+		t.Error("processed image still has EXIF")
+	}
+	img, err := jpeg.Decode(bytes.NewReader(p.image))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	// a clockwise turn puts the left half on top
+	if r, _, b, _ := img.At(4, 3).RGBA(); r < b {
+		t.Errorf("top is not red: r=%d b=%d", r>>8, b>>8)
+	}
+	if r, _, b, _ := img.At(4, 12).RGBA(); b < r {
+		t.Errorf("bottom is not blue: r=%d b=%d", r>>8, b>>8)
+	}
+}
+
+func TestProcess_Thumbnail(t *testing.T) {
+	tests := []struct {
+		name           string
+		w, h           int
+		thumbW, thumbH int
+	}{
+		{name: "landscape", w: 400, h: 100, thumbW: 64, thumbH: 16},
+		{name: "portrait", w: 100, h: 400, thumbW: 16, thumbH: 64},
+		{name: "small", w: 40, h: 20, thumbW: 40, thumbH: 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := process(encodePNG(t, halves(tt.w, tt.h)), 64)
+			if err != nil {
+				t.Fatalf("process: %v", err)
+			}
+			if p.contentType != pngType || p.width != tt.w || p.height != tt.h {
+				t.Fatalf("got %s %dx%d, want image/png %dx%d", p.contentType, p.width, p.height, tt.w, tt.h)
+			}
+			cfg, err := png.DecodeConfig(bytes.NewReader(p.thumbnail))
+			if err != nil {
+				t.Fatalf("decode thumbnail: %v", err)
+			}
+			if cfg.Width != tt.thumbW || cfg.Height != tt.thumbH {
+				t.Errorf("thumbnail %dx%d, want %dx%d", cfg.Width, cfg.Height, tt.thumbW, tt.thumbH)
+			}
+		})
+	}
+}
+
+func TestProcess_Rejects(t *testing.T) {
+	var gifBuf bytes.Buffer
+	if err := gif.Encode(&gifBuf, halves(4, 4), nil); err != nil {
+		t.Fatalf("encode gif: %v", err)
+	}
+	// a PNG claiming to be 6000x5000, DecodeConfig only reads the header
+	huge := encodePNG(t, halves(4, 4))
+	binary.BigEndian.PutUint32(huge[16:], 6000)
+	binary.BigEndian.PutUint32(huge[20:], 5000)
+	binary.BigEndian.PutUint32(huge[29:], crc32.ChecksumIEEE(huge[12:29]))
+	truncated := encodePNG(t, halves(4, 4))[:40]
+	tests := []struct {
+		name string
+		b    []byte
+		want error
+	}{
+		{name: "gif", b: gifBuf.Bytes(), want: ErrUnsupportedType},
+		{name: "text", b: []byte("hello"), want: ErrUnsupportedType},
+		{name: "too many pixels", b: huge, want: ErrTooManyPixels},
+		{name: "truncated", b: truncated, want: ErrInvalidImage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := process(tt.b, 64); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package photo
+
+import "time"
+
+type PhotoID int
+
+type Kind string
+
+const (
+	KindPhoto     Kind = "photo"
+	KindFloorPlan Kind = "floor_plan"
+)
+
+// Photo is an image of a house or a flat, exactly one of HouseID and FlatID
+// is set. The URLs are filled in by the handler.
+type Photo struct {
+	ID            PhotoID   `json:"id"`
+	HouseID       *int      `json:"house_id,omitempty"`
+	FlatID        *int      `json:"flat_id,omitempty"`
+	Kind          Kind      `json:"kind"`
+	ContentType   string    `json:"content_type"`
+	Width         int       `json:"width"`
+	Height        int       `json:"height"`
+	Size          int64     `json:"size"`
+	URL           string    `json:"url"`
+	ThumbnailURL  string    `json:"thumbnail_url"`
+	CreatedAt     time.Time `json:"created_at"`
+	ThumbnailSize int64     `json:"-"`
+	Key           string    `json:"-"`
+	ThumbnailKey  string    `json:"-"`
+	CreatedBy     string    `json:"-"`
+}
+
+// Target is the house or the flat photos are attached to, only one of the
+// IDs is set.
+type Target struct {
+	HouseID int
+	FlatID  int
+}
+
+// FlatAccess tells who may see the photos of a flat: everyone once it is
+// approved, before that only moderators and its owner.
+type FlatAccess struct {
+	Status  string
+	OwnerID *string
+}
@@ -0,0 +1,127 @@
+package photo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Polyrom/houses_api/pkg/client/postgres"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const photoColumns = `p.id, p.house_id, p.flat_id, p.kind, p.content_type, p.width, p.height, p.size, p.thumbnail_size, p.key, p.thumbnail_key, COALESCE(p.created_by::text, ''), p.created_at`
+
+type repository struct {
+	client postgres.Client
+	logger logging.Logger
+}
+
+func scanPhoto(row pgx.Row, p *Photo, extra ...any) error {
+	dest := []any{&p.ID, &p.HouseID, &p.FlatID, &p.Kind, &p.ContentType, &p.Width, &p.Height,
+		&p.Size, &p.ThumbnailSize, &p.Key, &p.ThumbnailKey, &p.CreatedBy, &p.CreatedAt}
+	err := row.Scan(append(dest, extra...)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *repository) HouseExists(ctx context.Context, id int) (bool, error) {
+	q := `SELECT EXISTS (SELECT 1 FROM houses WHERE id = $1)`
+	var exists bool
+	err := r.client.QueryRow(ctx, q, id).Scan(&exists)
+	return exists, err
+}
+
+func (r *repository) Flat(ctx context.Context, id int) (FlatAccess, error) {
+	q := `SELECT status, owner_id::text FROM flats WHERE id = $1`
+	var fa FlatAccess
+	err := r.client.QueryRow(ctx, q, id).Scan(&fa.Status, &fa.OwnerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return FlatAccess{}, ErrNotFound
+	}
+	return fa, err
+}
+
+func (r *repository) Create(ctx context.Context, p Photo) (Photo, error) {
+	q := `WITH p AS (
+					INSERT INTO photos
+						(house_id, flat_id, kind, content_type, width, height, size, thumbnail_size, key, thumbnail_key, created_by)
+					VALUES
+						($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, '')::uuid)
+					RETURNING *
+				)
+				SELECT ` + photoColumns + ` FROM p`
+	var np Photo
+	err := scanPhoto(r.client.QueryRow(ctx, q, p.HouseID, p.FlatID, p.Kind, p.ContentType, p.Width, p.Height,
+		p.Size, p.ThumbnailSize, p.Key, p.ThumbnailKey, p.CreatedBy), &np)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			r.logger.Errorf("SQL Error: %s, Detail: %s, Where: %s", pgErr.Message, pgErr.Detail, pgErr.Where)
+		}
+		return Photo{}, err
+	}
+	return np, nil
+}
+
+func (r *repository) Get(ctx context.Context, id PhotoID) (Photo, *FlatAccess, error) {
+	q := `SELECT 
+					` + photoColumns + `, f.status, f.owner_id::text
+				FROM 
+					photos AS p LEFT JOIN flats AS f ON f.id = p.flat_id
+				WHERE p.id = $1`
+	var p Photo
+	var status, ownerID *string
+	if err := scanPhoto(r.client.QueryRow(ctx, q, id), &p, &status, &ownerID); err != nil {
+		return Photo{}, nil, err
+	}
+	if status == nil {
+		return p, nil, nil
+	}
+	return p, &FlatAccess{Status: *status, OwnerID: ownerID}, nil
+}
+
+func (r *repository) List(ctx context.Context, t Target) ([]Photo, error) {
+	q := `SELECT 
+					` + photoColumns + `
+				FROM 
+					photos AS p
+				WHERE p.house_id = $1 
+				OR p.flat_id = $2
+				ORDER BY p.id`
+	rows, err := r.client.Query(ctx, q, t.HouseID, t.FlatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ps := make([]Photo, 0)
+	for rows.Next() {
+		var p Photo
+		if err = scanPhoto(rows, &p); err != nil {
+			return nil, err
+		}
+		ps = append(ps, p)
+	}
+	return ps, rows.Err()
+}
+
+func (r *repository) Delete(ctx context.Context, id PhotoID) error {
+	q := `DELETE FROM photos WHERE id = $1`
+	tag, err := r.client.Exec(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func NewRepository(c postgres.Client, l logging.Logger) Repository {
+	return &repository{
+		client: c,
+		logger: l,
+	}
+}
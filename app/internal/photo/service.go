@@ -0,0 +1,210 @@
+package photo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Polyrom/houses_api/internal/blob"
+	"github.com/Polyrom/houses_api/internal/config"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/internal/modstatus"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotFound  = errors.New("photo not found")
+	ErrForbidden = errors.New("only moderators and the owner of the flat may change its photos")
+	ErrTooLarge  = errors.New("photo is too large")
+)
+
+type Service struct {
+	repo    Repository
+	storage blob.Storage
+	cfg     config.PhotosConfig
+	// processing bounds the uploads decoded at once, each takes up to
+	// several hundred megabytes.
+	processing chan struct{}
+	logger     logging.Logger
+}
+
+// Upload checks and stores an image read from r. Moderators may upload to
+// any house or flat, clients only to flats they own.
+func (s *Service) Upload(ctx context.Context, t Target, kind Kind, r io.Reader) (Photo, error) {
+	if err := s.checkChange(ctx, t); err != nil {
+		return Photo{}, err
+	}
+	b, err := io.ReadAll(io.LimitReader(r, s.cfg.MaxBytes+1))
+	if err != nil {
+		return Photo{}, err
+	}
+	if int64(len(b)) > s.cfg.MaxBytes {
+		return Photo{}, fmt.Errorf("%w: the limit is %d bytes", ErrTooLarge, s.cfg.MaxBytes)
+	}
+	select {
+	case s.processing <- struct{}{}:
+	case <-ctx.Done():
+		return Photo{}, ctx.Err()
+	}
+	img, err := process(b, s.cfg.ThumbnailSize)
+	<-s.processing
+	if err != nil {
+		return Photo{}, err
+	}
+	p := Photo{
+		Kind:          kind,
+		ContentType:   img.contentType,
+		Width:         img.width,
+		Height:        img.height,
+		Size:          int64(len(img.image)),
+		ThumbnailSize: int64(len(img.thumbnail)),
+	}
+	p.CreatedBy, _ = middleware.CurrentUserID(ctx)
+	ext := ".jpg"
+	if img.contentType == pngType {
+		ext = ".png"
+	}
+	name := uuid.New().String()
+	if t.FlatID != 0 {
+		p.FlatID = &t.FlatID
+		p.Key = fmt.Sprintf("flats/%d/%s%s", t.FlatID, name, ext)
+	} else {
+		p.HouseID = &t.HouseID
+		p.Key = fmt.Sprintf("houses/%d/%s%s", t.HouseID, name, ext)
+	}
+	p.ThumbnailKey = p.Key[:len(p.Key)-len(ext)] + "_thumb" + ext
+	if err = s.storage.Put(ctx, p.Key, bytes.NewReader(img.image)); err != nil {
+		return Photo{}, err
+	}
+	if err = s.storage.Put(ctx, p.ThumbnailKey, bytes.NewReader(img.thumbnail)); err != nil {
+		s.deleteFiles(ctx, p)
+		return Photo{}, err
+	}
+	created, err := s.repo.Create(ctx, p)
+	if err != nil {
+		s.deleteFiles(ctx, p)
+		return Photo{}, err
+	}
+	return created, nil
+}
+
+// List returns the photos of a house or a flat the user can see.
+func (s *Service) List(ctx context.Context, t Target) ([]Photo, error) {
+	if t.FlatID != 0 {
+		fa, err := s.repo.Flat(ctx, t.FlatID)
+		if err != nil {
+			return nil, err
+		}
+		if !canSee(ctx, fa) {
+			return nil, ErrNotFound
+		}
+	} else if exists, err := s.repo.HouseExists(ctx, t.HouseID); err != nil || !exists {
+		if err == nil {
+			err = ErrNotFound
+		}
+		return nil, err
+	}
+	return s.repo.List(ctx, t)
+}
+
+// Open returns the photo and its file or thumbnail. Photos the user may
+// not see are reported as not found.
+func (s *Service) Open(ctx context.Context, id PhotoID, thumbnail bool) (Photo, io.ReadCloser, error) {
+	p, fa, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return Photo{}, nil, err
+	}
+	if fa != nil && !canSee(ctx, *fa) {
+		return Photo{}, nil, ErrNotFound
+	}
+	key := p.Key
+	if thumbnail {
+		key = p.ThumbnailKey
+	}
+	rc, err := s.storage.Open(ctx, key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return Photo{}, nil, fmt.Errorf("file %s of photo %d is missing: %w", key, p.ID, err)
+	}
+	return p, rc, err
+}
+
+func (s *Service) Delete(ctx context.Context, id PhotoID) error {
+	p, fa, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if fa != nil && !canSee(ctx, *fa) {
+		return ErrNotFound
+	}
+	t := Target{}
+	if p.FlatID != nil {
+		t.FlatID = *p.FlatID
+	} else {
+		t.HouseID = *p.HouseID
+	}
+	if err = s.checkChange(ctx, t); err != nil {
+		return err
+	}
+	if err = s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.deleteFiles(ctx, p)
+	return nil
+}
+
+// checkChange allows moderators to change photos of existing houses and
+// flats, and owners to change photos of their flats.
+func (s *Service) checkChange(ctx context.Context, t Target) error {
+	role, _ := middleware.CurrentRole(ctx)
+	if t.FlatID == 0 {
+		exists, err := s.repo.HouseExists(ctx, t.HouseID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrNotFound
+		}
+		if role != middleware.Moderator {
+			return ErrForbidden
+		}
+		return nil
+	}
+	fa, err := s.repo.Flat(ctx, t.FlatID)
+	if err != nil {
+		return err
+	}
+	if role == middleware.Moderator || isOwner(ctx, fa) {
+		return nil
+	}
+	if !canSee(ctx, fa) {
+		return ErrNotFound
+	}
+	return ErrForbidden
+}
+
+// deleteFiles removes the files of a photo, failures only leave orphaned
+// files behind and are logged.
+func (s *Service) deleteFiles(ctx context.Context, p Photo) {
+	for _, key := range []string{p.Key, p.ThumbnailKey} {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			s.logger.Errorf("failed to delete photo file %s: %v", key, err)
+		}
+	}
+}
+
+func canSee(ctx context.Context, fa FlatAccess) bool {
+	role, _ := middleware.CurrentRole(ctx)
+	return fa.Status == modstatus.Approved.String() || role == middleware.Moderator || isOwner(ctx, fa)
+}
+
+func isOwner(ctx context.Context, fa FlatAccess) bool {
+	userID, ok := middleware.CurrentUserID(ctx)
+	return ok && fa.OwnerID != nil && *fa.OwnerID == userID
+}
+
+func NewService(r Repository, st blob.Storage, cfg config.PhotosConfig, l logging.Logger) *Service {
+	return &Service{repo: r, storage: st, cfg: cfg, processing: make(chan struct{}, max(cfg.MaxProcessing, 1)), logger: l}
+}
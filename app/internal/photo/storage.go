@@ -0,0 +1,14 @@
+package photo
+
+import "context"
+
+type Repository interface {
+	HouseExists(ctx context.Context, id int) (bool, error)
+	Flat(ctx context.Context, id int) (FlatAccess, error)
+	Create(ctx context.Context, p Photo) (Photo, error)
+	// Get returns the photo along with the access of its flat, which is
+	// nil for house photos.
+	Get(ctx context.Context, id PhotoID) (Photo, *FlatAccess, error)
+	List(ctx context.Context, t Target) ([]Photo, error)
+	Delete(ctx context.Context, id PhotoID) error
+}
@@ -0,0 +1,13 @@
+package search
+
+// SearchDTO creates or replaces a saved search. A search is limited either
+// to a house or to a city and optionally one of its districts.
+type SearchDTO struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	HouseID  int    `json:"house_id" validate:"omitempty,min=1,excluded_with=City"`
+	City     string `json:"city" validate:"max=100,required_with=District"`
+	District string `json:"district" validate:"max=100"`
+	Rooms    int    `json:"rooms" validate:"omitempty,min=1"`
+	PriceMin int    `json:"price_min" validate:"min=0"`
+	PriceMax int    `json:"price_max" validate:"omitempty,gtefield=PriceMin"`
+}
@@ -0,0 +1,163 @@
+package search
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/handlers"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+const (
+	searchesURL = "/searches"
+	searchURL   = "/searches/{id}"
+)
+
+type handler struct {
+	aumw middleware.Middleware
+	s    *Service
+	l    logging.Logger
+}
+
+func NewHandler(aumw middleware.Middleware, s *Service, l logging.Logger) handlers.Handler {
+	return &handler{aumw: aumw, s: s, l: l}
+}
+
+// Register mounts the saved search routes, every user sees only their own
+// searches.
+func (h *handler) Register(r *mux.Router) {
+	r.Handle(searchesURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Create))).Methods(http.MethodPost)
+	r.Handle(searchesURL, h.aumw.DoInMiddle(http.HandlerFunc(h.List))).Methods(http.MethodGet)
+	r.Handle(searchURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Get))).Methods(http.MethodGet)
+	r.Handle(searchURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Update))).Methods(http.MethodPut)
+	r.Handle(searchURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Delete))).Methods(http.MethodDelete)
+}
+
+func (h *handler) Create(w http.ResponseWriter, r *http.Request) {
+	dto, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+	s, err := h.s.Create(r.Context(), dto)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.respond(w, r, s)
+}
+
+func (h *handler) List(w http.ResponseWriter, r *http.Request) {
+	ss, err := h.s.List(r.Context())
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.respond(w, r, ss)
+}
+
+func (h *handler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.searchID(w, r)
+	if !ok {
+		return
+	}
+	s, err := h.s.Get(r.Context(), id)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.respond(w, r, s)
+}
+
+func (h *handler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.searchID(w, r)
+	if !ok {
+		return
+	}
+	dto, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+	s, err := h.s.Update(r.Context(), id, dto)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.respond(w, r, s)
+}
+
+func (h *handler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.searchID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.s.Delete(r.Context(), id); err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) decode(w http.ResponseWriter, r *http.Request) (SearchDTO, bool) {
+	reqID := middleware.RequestID(r.Context())
+	var dto SearchDTO
+	code, err := handlers.DecodeJSON(r, &dto)
+	if err != nil {
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, code)
+		return SearchDTO{}, false
+	}
+	validate := validator.New()
+	err = validate.Struct(dto)
+	if err != nil {
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusBadRequest)
+		return SearchDTO{}, false
+	}
+	return dto, true
+}
+
+func (h *handler) searchID(w http.ResponseWriter, r *http.Request) (SearchID, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id < 1 {
+		reqID := middleware.RequestID(r.Context())
+		invalidIDErr := errors.New("invalid search id")
+		h.l.Errorf("bad request req_id=%s: %v", reqID, invalidIDErr)
+		apierror.Write(w, invalidIDErr, reqID, http.StatusBadRequest)
+		return 0, false
+	}
+	return SearchID(id), true
+}
+
+func (h *handler) fail(w http.ResponseWriter, r *http.Request, err error) {
+	reqID := middleware.RequestID(r.Context())
+	switch {
+	case errors.Is(err, ErrNotFound):
+		h.l.Errorf("not found req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusNotFound)
+	case errors.Is(err, ErrHouseNotFound):
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusBadRequest)
+	case errors.Is(err, ErrLimitReached):
+		h.l.Errorf("conflict req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusConflict)
+	case errors.Is(err, ErrNotAuthenticated):
+		h.l.Errorf("unauthorized req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusUnauthorized)
+	default:
+		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusInternalServerError)
+	}
+}
+
+func (h *handler) respond(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.l.Errorf("internal error req_id=%s: %v", middleware.RequestID(r.Context()), err)
+	}
+}
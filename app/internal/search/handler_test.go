@@ -0,0 +1,59 @@
+package search
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Polyrom/houses_api/internal/middleware/middlewaretest"
+	"github.com/gorilla/mux"
+)
+
+func TestHandler(t *testing.T) {
+	repo := &MockSearchRepo{searches: []Search{{ID: 1, UserID: "user-2", Name: "not yours"}}}
+	router := mux.NewRouter()
+	NewHandler(middlewaretest.Auth{}, NewService(repo, &recordingNotifier{}, testConfig, &MockLogger{}), &MockLogger{}).Register(router)
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		user   string
+		body   string
+		code   int
+		want   string
+	}{
+		{name: "create", method: http.MethodPost, url: "/searches", user: "user-1", body: `{"name":"arbat","city":"Moscow","district":"Arbat","rooms":2,"price_max":9000000}`, code: http.StatusOK, want: `"district":"Arbat"`},
+		{name: "district without city", method: http.MethodPost, url: "/searches", user: "user-1", body: `{"name":"x","district":"Arbat"}`, code: http.StatusBadRequest},
+		{name: "house and city", method: http.MethodPost, url: "/searches", user: "user-1", body: `{"name":"x","house_id":1,"city":"Moscow"}`, code: http.StatusBadRequest},
+		{name: "inverted price range", method: http.MethodPost, url: "/searches", user: "user-1", body: `{"name":"x","price_min":10,"price_max":5}`, code: http.StatusBadRequest},
+		{name: "missing name", method: http.MethodPost, url: "/searches", user: "user-1", body: `{"rooms":1}`, code: http.StatusBadRequest},
+		{name: "missing house", method: http.MethodPost, url: "/searches", user: "user-1", body: `{"name":"x","house_id":101}`, code: http.StatusBadRequest},
+		{name: "create second", method: http.MethodPost, url: "/searches", user: "user-1", body: `{"name":"house","house_id":1}`, code: http.StatusOK},
+		{name: "over the limit", method: http.MethodPost, url: "/searches", user: "user-1", body: `{"name":"third"}`, code: http.StatusConflict},
+		{name: "anonymous", method: http.MethodPost, url: "/searches", body: `{"name":"x"}`, code: http.StatusUnauthorized},
+		{name: "list own", method: http.MethodGet, url: "/searches", user: "user-1", code: http.StatusOK, want: `"name":"house"`},
+		{name: "get own", method: http.MethodGet, url: "/searches/2", user: "user-1", code: http.StatusOK, want: `"name":"arbat"`},
+		{name: "get foreign", method: http.MethodGet, url: "/searches/1", user: "user-1", code: http.StatusNotFound},
+		{name: "update", method: http.MethodPut, url: "/searches/2", user: "user-1", body: `{"name":"renamed"}`, code: http.StatusOK, want: `"name":"renamed"`},
+		{name: "update foreign", method: http.MethodPut, url: "/searches/1", user: "user-1", body: `{"name":"mine"}`, code: http.StatusNotFound},
+		{name: "invalid id", method: http.MethodGet, url: "/searches/x", user: "user-1", code: http.StatusBadRequest},
+		{name: "delete", method: http.MethodDelete, url: "/searches/2", user: "user-1", code: http.StatusNoContent},
+		{name: "delete again", method: http.MethodDelete, url: "/searches/2", user: "user-1", code: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-User", tt.user)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			if rr.Code != tt.code {
+				t.Fatalf("code = %d, want %d: %s", rr.Code, tt.code, rr.Body)
+			}
+			if !strings.Contains(rr.Body.String(), tt.want) {
+				t.Errorf("body = %s, want it to contain %s", rr.Body, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package search
+
+import (
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+)
+
+type SearchID int
+
+// Search is a saved search, criteria left empty match any flat. Flats
+// approved up to CheckedAt have been matched already.
+type Search struct {
+	ID        SearchID  `json:"id"`
+	UserID    string    `json:"-"`
+	Name      string    `json:"name"`
+	HouseID   int       `json:"house_id,omitempty"`
+	City      string    `json:"city,omitempty"`
+	District  string    `json:"district,omitempty"`
+	Rooms     int       `json:"rooms,omitempty"`
+	PriceMin  int       `json:"price_min,omitempty"`
+	PriceMax  int       `json:"price_max,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdateAt  time.Time `json:"update_at"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Due is a search claimed by the scheduler along with the email of its user.
+type Due struct {
+	Search
+	Email string
+}
+
+// Match is a newly approved flat found by a search.
+type Match struct {
+	flat.FlatDTO
+	Address string `json:"address"`
+}
+
+// Result holds the matches of a search. Total counts all of them, Flats
+// lists the first ones only.
+type Result struct {
+	Search Search  `json:"search"`
+	Flats  []Match `json:"flats"`
+	Total  int     `json:"total"`
+}
+
+// Digest is sent to a user with the results of the searches that found
+// flats approved up to Until.
+type Digest struct {
+	UserID  string    `json:"user_id"`
+	Email   string    `json:"email"`
+	Until   time.Time `json:"until"`
+	Results []Result  `json:"results"`
+}
@@ -0,0 +1,81 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Polyrom/houses_api/internal/config"
+	"github.com/Polyrom/houses_api/pkg/logging"
+)
+
+// Notifier sends a digest to its user. A failed digest is sent again with
+// the next run, so a notifier should not report a partially sent one as
+// failed.
+type Notifier interface {
+	Notify(ctx context.Context, d Digest) error
+}
+
+// NewNotifier returns the notifier chosen by cfg.Notifier.
+func NewNotifier(cfg config.SearchesConfig, l logging.Logger) (Notifier, error) {
+	switch cfg.Notifier {
+	case "log":
+		return NewLogNotifier(l), nil
+	case "file":
+		return NewFileNotifier(cfg.File)
+	}
+	return nil, fmt.Errorf("unknown notifier %q", cfg.Notifier)
+}
+
+// LogNotifier writes a line per digest to the log, standing in for email
+// until there is a mail service.
+type LogNotifier struct {
+	logger logging.Logger
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, d Digest) error {
+	for _, r := range d.Results {
+		n.logger.Infof("search digest to user_id=%s: %d new flats for search_id=%d", d.UserID, r.Total, r.Search.ID)
+	}
+	return nil
+}
+
+func NewLogNotifier(l logging.Logger) *LogNotifier {
+	return &LogNotifier{logger: l}
+}
+
+// FileNotifier appends digests to a file as JSON lines, for local runs and
+// tests to read.
+type FileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (n *FileNotifier) Notify(ctx context.Context, d Digest) error {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// NewFileNotifier creates the directory of path if needed.
+func NewFileNotifier(path string) (*FileNotifier, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return &FileNotifier{path: path}, nil
+}
@@ -0,0 +1,281 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/pkg/client/postgres"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const foreignKeyViolation = "23503"
+
+// searchColumns are read by scanSearch in this order, from saved_searches
+// as s.
+const searchColumns = `s.id, s.user_id::text, s.name, COALESCE(s.house_id, 0), COALESCE(s.city, ''), COALESCE(s.district, ''), COALESCE(s.rooms, 0), COALESCE(s.price_min, 0), COALESCE(s.price_max, 0), s.created_at, s.update_at, s.checked_at`
+
+type repository struct {
+	client postgres.TxClient
+	logger logging.Logger
+}
+
+func scanSearch(row pgx.Row, s *Search, extra ...any) error {
+	dest := []any{&s.ID, &s.UserID, &s.Name, &s.HouseID, &s.City, &s.District, &s.Rooms,
+		&s.PriceMin, &s.PriceMax, &s.CreatedAt, &s.UpdateAt, &s.CheckedAt}
+	err := row.Scan(append(dest, extra...)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Create locks the user while counting the searches, so that concurrent
+// requests cannot go over the limit. FOR NO KEY UPDATE leaves rows
+// referencing the user free to be written meanwhile.
+func (r *repository) Create(ctx context.Context, userID string, dto SearchDTO, limit int) (Search, error) {
+	tx, err := r.client.Begin(ctx)
+	if err != nil {
+		return Search{}, err
+	}
+	defer tx.Rollback(ctx)
+	if _, err = tx.Exec(ctx, `SELECT 1 FROM users WHERE id = $1 FOR NO KEY UPDATE`, userID); err != nil {
+		return Search{}, err
+	}
+	var n int
+	if err = tx.QueryRow(ctx, `SELECT COUNT(*) FROM saved_searches WHERE user_id = $1`, userID).Scan(&n); err != nil {
+		return Search{}, err
+	}
+	if n >= limit {
+		return Search{}, fmt.Errorf("%w: a user may have %d saved searches", ErrLimitReached, limit)
+	}
+	q := `INSERT INTO saved_searches AS s 
+					(user_id, name, house_id, city, district, rooms, price_min, price_max) 
+				VALUES 
+					($1, $2, NULLIF($3, 0), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, 0), NULLIF($7, 0), NULLIF($8, 0))
+				RETURNING 
+					` + searchColumns
+	var s Search
+	err = scanSearch(tx.QueryRow(ctx, q, userID, dto.Name, dto.HouseID, dto.City, dto.District,
+		dto.Rooms, dto.PriceMin, dto.PriceMax), &s)
+	if err != nil {
+		return Search{}, r.houseError(err)
+	}
+	return s, tx.Commit(ctx)
+}
+
+func (r *repository) Update(ctx context.Context, userID string, id SearchID, dto SearchDTO) (Search, error) {
+	q := `UPDATE saved_searches AS s 
+				SET name = $3, house_id = NULLIF($4, 0), city = NULLIF($5, ''), district = NULLIF($6, ''), 
+					rooms = NULLIF($7, 0), price_min = NULLIF($8, 0), price_max = NULLIF($9, 0), 
+					update_at = CURRENT_TIMESTAMP
+				WHERE id = $1
+				AND user_id = $2
+				RETURNING 
+					` + searchColumns
+	var s Search
+	err := scanSearch(r.client.QueryRow(ctx, q, id, userID, dto.Name, dto.HouseID, dto.City, dto.District,
+		dto.Rooms, dto.PriceMin, dto.PriceMax), &s)
+	if err != nil {
+		return Search{}, r.houseError(err)
+	}
+	return s, nil
+}
+
+func (r *repository) Delete(ctx context.Context, userID string, id SearchID) error {
+	tag, err := r.client.Exec(ctx, `DELETE FROM saved_searches WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *repository) Get(ctx context.Context, userID string, id SearchID) (Search, error) {
+	q := `SELECT 
+					` + searchColumns + ` 
+				FROM 
+					saved_searches AS s 
+				WHERE s.id = $1
+				AND s.user_id = $2`
+	var s Search
+	err := scanSearch(r.client.QueryRow(ctx, q, id, userID), &s)
+	return s, err
+}
+
+func (r *repository) List(ctx context.Context, userID string) ([]Search, error) {
+	q := `SELECT 
+					` + searchColumns + ` 
+				FROM 
+					saved_searches AS s 
+				WHERE s.user_id = $1
+				ORDER BY s.id`
+	rows, err := r.client.Query(ctx, q, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ss := make([]Search, 0)
+	for rows.Next() {
+		var s Search
+		if err = scanSearch(rows, &s); err != nil {
+			return nil, err
+		}
+		ss = append(ss, s)
+	}
+	return ss, rows.Err()
+}
+
+func (r *repository) Claim(ctx context.Context, until time.Time, users int, leaseUntil time.Time) ([]Due, error) {
+	q := `UPDATE saved_searches AS s
+				SET
+					locked_until = $3
+				FROM users AS u
+				WHERE u.id = s.user_id
+				AND s.id IN (
+					SELECT ss.id
+					FROM saved_searches ss
+					WHERE ss.user_id IN (
+						SELECT user_id
+						FROM saved_searches
+						WHERE checked_at < $1
+						AND (locked_until IS NULL OR locked_until < CURRENT_TIMESTAMP)
+						GROUP BY user_id
+						ORDER BY MIN(checked_at)
+						LIMIT $2
+					)
+					AND ss.checked_at < $1
+					AND (ss.locked_until IS NULL OR ss.locked_until < CURRENT_TIMESTAMP)
+					FOR UPDATE OF ss SKIP LOCKED
+				)
+				RETURNING
+					` + searchColumns + `, u.email`
+	rows, err := r.client.Query(ctx, q, until, users, leaseUntil)
+	if err != nil {
+		r.logError(err)
+		return nil, err
+	}
+	defer rows.Close()
+	due := make([]Due, 0)
+	for rows.Next() {
+		var d Due
+		if err = scanSearch(rows, &d.Search, &d.Email); err != nil {
+			return nil, err
+		}
+		due = append(due, d)
+	}
+	return due, rows.Err()
+}
+
+// criteriaClause appends the criteria of s to the WHERE clause of Matches
+// and their values to args.
+func criteriaClause(s Search, args []any) (string, []any) {
+	var b strings.Builder
+	cond := func(expr string, v any) {
+		args = append(args, v)
+		fmt.Fprintf(&b, "\n\t\t\t\tAND "+expr, len(args))
+	}
+	if s.HouseID != 0 {
+		cond("f.house_id = $%d", s.HouseID)
+	}
+	if s.City != "" {
+		cond("lower(h.city) = lower($%d)", s.City)
+	}
+	if s.District != "" {
+		cond("lower(h.district) = lower($%d)", s.District)
+	}
+	if s.Rooms != 0 {
+		cond("f.rooms = $%d", s.Rooms)
+	}
+	if s.PriceMin != 0 {
+		cond("f.price >= $%d", s.PriceMin)
+	}
+	if s.PriceMax != 0 {
+		cond("f.price <= $%d", s.PriceMax)
+	}
+	return b.String(), args
+}
+
+func (r *repository) Matches(ctx context.Context, s Search, until time.Time, limit int) ([]Match, int, error) {
+	where, args := criteriaClause(s, []any{s.CheckedAt, until, limit})
+	q := `SELECT 
+					` + flat.Columns + `, h.address, COUNT(*) OVER () 
+				FROM 
+					flats AS f JOIN houses AS h ON h.id = f.house_id 
+				WHERE f.status = 'approved'
+				AND f.approved_at > $1
+				AND f.approved_at <= $2` + where + `
+				ORDER BY f.approved_at, f.id
+				LIMIT $3`
+	rows, err := r.client.Query(ctx, q, args...)
+	if err != nil {
+		r.logError(err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+	ms := make([]Match, 0)
+	total := 0
+	for rows.Next() {
+		var m Match
+		if err = flat.Scan(rows, &m.FlatDTO, &m.Address, &total); err != nil {
+			return nil, 0, err
+		}
+		ms = append(ms, m)
+	}
+	return ms, total, rows.Err()
+}
+
+func (r *repository) Checked(ctx context.Context, ids []SearchID, until time.Time) error {
+	q := `UPDATE saved_searches 
+				SET checked_at = $2, locked_until = NULL
+				WHERE id = ANY($1)`
+	_, err := r.client.Exec(ctx, q, intIDs(ids), until)
+	return err
+}
+
+func (r *repository) Release(ctx context.Context, ids []SearchID, retryAt time.Time) error {
+	q := `UPDATE saved_searches 
+				SET locked_until = $2
+				WHERE id = ANY($1)`
+	_, err := r.client.Exec(ctx, q, intIDs(ids), retryAt)
+	return err
+}
+
+func intIDs(ids []SearchID) []int {
+	ns := make([]int, len(ids))
+	for i, id := range ids {
+		ns[i] = int(id)
+	}
+	return ns
+}
+
+// houseError reports a search for a missing house as ErrHouseNotFound.
+func (r *repository) houseError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return ErrHouseNotFound
+	}
+	r.logError(err)
+	return err
+}
+
+func (r *repository) logError(err error) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		r.logger.Errorf("SQL Error: %s, Detail: %s, Where: %s", pgErr.Message, pgErr.Detail, pgErr.Where)
+	}
+}
+
+func NewRepository(c postgres.TxClient, l logging.Logger) Repository {
+	return &repository{
+		client: c,
+		logger: l,
+	}
+}
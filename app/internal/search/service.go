@@ -0,0 +1,181 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/config"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/pkg/logging"
+)
+
+const (
+	// settleDelay keeps the scheduler behind the latest approvals, so that
+	// flats approved by transactions still committing are not skipped.
+	settleDelay = 30 * time.Second
+	// leaseDuration is how long claimed searches are left to one worker
+	// before others may pick them up.
+	leaseDuration = 5 * time.Minute
+)
+
+var (
+	ErrNotFound         = errors.New("saved search not found")
+	ErrHouseNotFound    = errors.New("house not found")
+	ErrLimitReached     = errors.New("too many saved searches")
+	ErrNotAuthenticated = errors.New("user not authenticated")
+)
+
+// Service keeps the saved searches of users and alerts them about newly
+// approved flats matching the searches.
+type Service struct {
+	repo     Repository
+	notifier Notifier
+	cfg      config.SearchesConfig
+	logger   logging.Logger
+	now      func() time.Time
+}
+
+// Create saves a search of the current user. It only alerts about flats
+// approved from now on.
+func (s *Service) Create(ctx context.Context, dto SearchDTO) (Search, error) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		return Search{}, ErrNotAuthenticated
+	}
+	return s.repo.Create(ctx, userID, dto, s.cfg.MaxPerUser)
+}
+
+// Update replaces the criteria of a search, flats approved before are not
+// matched again.
+func (s *Service) Update(ctx context.Context, id SearchID, dto SearchDTO) (Search, error) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		return Search{}, ErrNotAuthenticated
+	}
+	return s.repo.Update(ctx, userID, id, dto)
+}
+
+func (s *Service) Delete(ctx context.Context, id SearchID) error {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		return ErrNotAuthenticated
+	}
+	return s.repo.Delete(ctx, userID, id)
+}
+
+// Get returns a search of the current user, searches of other users are
+// not found.
+func (s *Service) Get(ctx context.Context, id SearchID) (Search, error) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		return Search{}, ErrNotAuthenticated
+	}
+	return s.repo.Get(ctx, userID, id)
+}
+
+func (s *Service) List(ctx context.Context) ([]Search, error) {
+	userID, ok := middleware.CurrentUserID(ctx)
+	if !ok {
+		return nil, ErrNotAuthenticated
+	}
+	return s.repo.List(ctx, userID)
+}
+
+// Run checks the saved searches every Interval until ctx is cancelled.
+// Several instances may run against the same database.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := s.checkDue(ctx)
+			if err != nil && ctx.Err() == nil {
+				s.logger.Errorf("check saved searches: %v", err)
+			}
+			if err != nil || n < s.cfg.BatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkDue claims the due searches of one batch of users and sends each
+// user a digest. It returns the number of users whose searches were checked,
+// users that failed are not counted so that Run does not claim them again
+// right away.
+func (s *Service) checkDue(ctx context.Context) (int, error) {
+	now := s.now()
+	until := now.Add(-settleDelay)
+	due, err := s.repo.Claim(ctx, until, s.cfg.BatchSize, now.Add(leaseDuration))
+	if err != nil {
+		return 0, err
+	}
+	var users []string
+	byUser := make(map[string][]Due)
+	for _, d := range due {
+		if _, ok := byUser[d.UserID]; !ok {
+			users = append(users, d.UserID)
+		}
+		byUser[d.UserID] = append(byUser[d.UserID], d)
+	}
+	checked := 0
+	for _, u := range users {
+		if ctx.Err() != nil {
+			break
+		}
+		if s.checkUser(ctx, byUser[u], until) {
+			checked++
+		}
+	}
+	return checked, ctx.Err()
+}
+
+// checkUser matches the searches of one user and moves them on to until
+// once the digest is sent. Searches that failed stay where they were and
+// are retried after one interval. It reports whether the searches were
+// checked.
+func (s *Service) checkUser(ctx context.Context, due []Due, until time.Time) bool {
+	d := Digest{UserID: due[0].UserID, Email: due[0].Email, Until: until}
+	ids := make([]SearchID, 0, len(due))
+	for _, sr := range due {
+		ids = append(ids, sr.ID)
+	}
+	// Record even when shutting down, the work has been done.
+	done := context.WithoutCancel(ctx)
+	release := func(err error) {
+		s.logger.Errorf("check saved searches of user %s: %v", d.UserID, err)
+		if err := s.repo.Release(done, ids, s.now().Add(s.cfg.Interval)); err != nil {
+			s.logger.Errorf("release saved searches of user %s: %v", d.UserID, err)
+		}
+	}
+	for _, sr := range due {
+		flats, total, err := s.repo.Matches(ctx, sr.Search, until, s.cfg.MaxMatches)
+		if err != nil {
+			release(err)
+			return false
+		}
+		if total > 0 {
+			d.Results = append(d.Results, Result{Search: sr.Search, Flats: flats, Total: total})
+		}
+	}
+	if len(d.Results) > 0 {
+		if err := s.notifier.Notify(ctx, d); err != nil {
+			release(err)
+			return false
+		}
+	}
+	if err := s.repo.Checked(done, ids, until); err != nil {
+		s.logger.Errorf("move saved searches of user %s on: %v", d.UserID, err)
+	}
+	return true
+}
+
+func NewService(r Repository, n Notifier, cfg config.SearchesConfig, l logging.Logger) *Service {
+	return &Service{repo: r, notifier: n, cfg: cfg, logger: l, now: time.Now}
+}
@@ -0,0 +1,310 @@
+package search
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/config"
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/middleware"
+)
+
+type MockLogger struct{}
+
+func (ml *MockLogger) Trace(args ...interface{})                   {}
+func (ml *MockLogger) Debug(args ...interface{})                   {}
+func (ml *MockLogger) Info(args ...interface{})                    {}
+func (ml *MockLogger) Warn(args ...interface{})                    {}
+func (ml *MockLogger) Warning(args ...interface{})                 {}
+func (ml *MockLogger) Error(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   {}
+func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
+func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
+func (ml *MockLogger) Infof(format string, args ...interface{})    {}
+func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
+func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
+func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
+func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
+
+// MockSearchRepo keeps searches in memory. Searches with an entry in
+// matches find that many flats, claimed searches are locked until checked
+// or, when released, until they may be retried.
+type MockSearchRepo struct {
+	searches []Search
+	matches  map[SearchID]int
+	locked   map[SearchID]time.Time
+	released []SearchID
+	claims   int
+}
+
+func (mr *MockSearchRepo) Create(ctx context.Context, userID string, dto SearchDTO, limit int) (Search, error) {
+	n := 0
+	for _, s := range mr.searches {
+		if s.UserID == userID {
+			n++
+		}
+	}
+	if n >= limit {
+		return Search{}, ErrLimitReached
+	}
+	if dto.HouseID > 100 {
+		return Search{}, ErrHouseNotFound
+	}
+	s := Search{ID: SearchID(len(mr.searches) + 1), UserID: userID, Name: dto.Name, HouseID: dto.HouseID,
+		City: dto.City, District: dto.District, Rooms: dto.Rooms, PriceMin: dto.PriceMin, PriceMax: dto.PriceMax}
+	mr.searches = append(mr.searches, s)
+	return s, nil
+}
+
+func (mr *MockSearchRepo) Update(ctx context.Context, userID string, id SearchID, dto SearchDTO) (Search, error) {
+	for i, s := range mr.searches {
+		if s.ID == id && s.UserID == userID {
+			mr.searches[i].Name = dto.Name
+			return mr.searches[i], nil
+		}
+	}
+	return Search{}, ErrNotFound
+}
+
+func (mr *MockSearchRepo) Delete(ctx context.Context, userID string, id SearchID) error {
+	for i, s := range mr.searches {
+		if s.ID == id && s.UserID == userID {
+			mr.searches = append(mr.searches[:i], mr.searches[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (mr *MockSearchRepo) Get(ctx context.Context, userID string, id SearchID) (Search, error) {
+	for _, s := range mr.searches {
+		if s.ID == id && s.UserID == userID {
+			return s, nil
+		}
+	}
+	return Search{}, ErrNotFound
+}
+
+func (mr *MockSearchRepo) List(ctx context.Context, userID string) ([]Search, error) {
+	ss := make([]Search, 0)
+	for _, s := range mr.searches {
+		if s.UserID == userID {
+			ss = append(ss, s)
+		}
+	}
+	return ss, nil
+}
+
+func (mr *MockSearchRepo) Claim(ctx context.Context, until time.Time, users int, leaseUntil time.Time) ([]Due, error) {
+	mr.claims++
+	now := leaseUntil.Add(-leaseDuration)
+	due := make([]Due, 0)
+	claimed := map[string]bool{}
+	for _, s := range mr.searches {
+		if mr.locked[s.ID].After(now) || !s.CheckedAt.Before(until) || (!claimed[s.UserID] && len(claimed) == users) {
+			continue
+		}
+		claimed[s.UserID] = true
+		mr.locked[s.ID] = leaseUntil
+		due = append(due, Due{Search: s, Email: s.UserID + "@example.com"})
+	}
+	return due, nil
+}
+
+func (mr *MockSearchRepo) Matches(ctx context.Context, s Search, until time.Time, limit int) ([]Match, int, error) {
+	total := mr.matches[s.ID]
+	ms := make([]Match, 0)
+	for i := 0; i < total && i < limit; i++ {
+		ms = append(ms, Match{FlatDTO: flat.FlatDTO{ID: i + 1}, Address: "Lenina 1"})
+	}
+	return ms, total, nil
+}
+
+func (mr *MockSearchRepo) Checked(ctx context.Context, ids []SearchID, until time.Time) error {
+	for _, id := range ids {
+		for i := range mr.searches {
+			if mr.searches[i].ID == id {
+				mr.searches[i].CheckedAt = until
+			}
+		}
+		delete(mr.locked, id)
+	}
+	return nil
+}
+
+func (mr *MockSearchRepo) Release(ctx context.Context, ids []SearchID, retryAt time.Time) error {
+	for _, id := range ids {
+		mr.locked[id] = retryAt
+	}
+	mr.released = append(mr.released, ids...)
+	return nil
+}
+
+type recordingNotifier struct {
+	digests []Digest
+	err     error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, d Digest) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.digests = append(n.digests, d)
+	return nil
+}
+
+var testConfig = config.SearchesConfig{Interval: time.Minute, MaxPerUser: 2, MaxMatches: 2, BatchSize: 10}
+
+func userCtx(userID string) context.Context {
+	return context.WithValue(context.Background(), middleware.UserID, userID)
+}
+
+func TestService_Create(t *testing.T) {
+	s := NewService(&MockSearchRepo{}, &recordingNotifier{}, testConfig, &MockLogger{})
+	for i := 0; i < testConfig.MaxPerUser; i++ {
+		if _, err := s.Create(userCtx("user-1"), SearchDTO{Name: "search"}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if _, err := s.Create(userCtx("user-1"), SearchDTO{Name: "one too many"}); !errors.Is(err, ErrLimitReached) {
+		t.Errorf("Create() over the limit error = %v, want %v", err, ErrLimitReached)
+	}
+	if _, err := s.Create(userCtx("user-2"), SearchDTO{Name: "search"}); err != nil {
+		t.Errorf("Create() for another user error = %v", err)
+	}
+	if _, err := s.Create(context.Background(), SearchDTO{Name: "search"}); !errors.Is(err, ErrNotAuthenticated) {
+		t.Errorf("Create() without user error = %v, want %v", err, ErrNotAuthenticated)
+	}
+}
+
+func TestService_CheckDue(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := created.Add(time.Hour)
+	repo := &MockSearchRepo{
+		searches: []Search{
+			{ID: 1, UserID: "user-1", Name: "two rooms", CheckedAt: created},
+			{ID: 2, UserID: "user-1", Name: "arbat", CheckedAt: created},
+			{ID: 3, UserID: "user-2", Name: "cheap", CheckedAt: created},
+		},
+		matches: map[SearchID]int{1: 3},
+		locked:  map[SearchID]time.Time{},
+	}
+	n := &recordingNotifier{err: errors.New("mail is down")}
+	s := NewService(repo, n, testConfig, &MockLogger{})
+	s.now = func() time.Time { return now }
+
+	if users, err := s.checkDue(context.Background()); err != nil || users != 1 {
+		t.Fatalf("checkDue() = %d, %v, want 1 user", users, err)
+	}
+	if len(repo.released) != 2 || repo.searches[0].CheckedAt != created {
+		t.Errorf("after a failed digest released %v, checked at %s; want searches of user-1 kept", repo.released, repo.searches[0].CheckedAt)
+	}
+	if repo.searches[2].CheckedAt == created {
+		t.Error("search without matches was not moved on")
+	}
+
+	n.err = nil
+	if users, _ := s.checkDue(context.Background()); users != 0 || len(n.digests) != 0 {
+		t.Fatalf("retry within the interval checked %d users, want the failed searches backed off", users)
+	}
+
+	now = now.Add(testConfig.Interval)
+	if _, err := s.checkDue(context.Background()); err != nil {
+		t.Fatalf("checkDue() error = %v", err)
+	}
+	if len(n.digests) != 1 {
+		t.Fatalf("digests = %d, want 1", len(n.digests))
+	}
+	d := n.digests[0]
+	if d.UserID != "user-1" || d.Email != "user-1@example.com" || !d.Until.Equal(now.Add(-settleDelay)) {
+		t.Errorf("digest = %+v", d)
+	}
+	if len(d.Results) != 1 || d.Results[0].Search.ID != 1 || d.Results[0].Total != 3 || len(d.Results[0].Flats) != 2 {
+		t.Errorf("results = %+v, want 2 of 3 flats of search 1", d.Results)
+	}
+	for _, sr := range repo.searches {
+		if !sr.CheckedAt.Equal(now.Add(-settleDelay)) || !repo.locked[sr.ID].IsZero() {
+			t.Errorf("search %d checked at %s, locked %v", sr.ID, sr.CheckedAt, repo.locked[sr.ID])
+		}
+	}
+
+	if users, _ := s.checkDue(context.Background()); users != 0 || len(n.digests) != 1 {
+		t.Errorf("second run checked %d users and sent %d digests, want nothing new", users, len(n.digests))
+	}
+}
+
+func TestService_RunBacksOffFailures(t *testing.T) {
+	repo := &MockSearchRepo{
+		searches: []Search{{ID: 1, UserID: "user-1", Name: "two rooms"}},
+		matches:  map[SearchID]int{1: 1},
+		locked:   map[SearchID]time.Time{},
+	}
+	cfg := testConfig
+	cfg.BatchSize = 1
+	s := NewService(repo, &recordingNotifier{err: errors.New("mail is down")}, cfg, &MockLogger{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if repo.claims != 1 || len(repo.released) != 1 {
+		t.Errorf("Run() claimed %d times and released %v, want one failed attempt per interval", repo.claims, repo.released)
+	}
+}
+
+func TestCriteriaClause(t *testing.T) {
+	where, args := criteriaClause(Search{City: "Moscow", Rooms: 2, PriceMax: 10}, []any{"since", "until", 50})
+	for _, want := range []string{"lower(h.city) = lower($4)", "f.rooms = $5", "f.price <= $6"} {
+		if !strings.Contains(where, want) {
+			t.Errorf("where = %q, want it to contain %q", where, want)
+		}
+	}
+	if len(args) != 6 || args[3] != "Moscow" || args[4] != 2 || args[5] != 10 {
+		t.Errorf("args = %v", args)
+	}
+	if where, args = criteriaClause(Search{}, nil); where != "" || len(args) != 0 {
+		t.Errorf("any flat = %q, %v", where, args)
+	}
+}
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "digests", "out.jsonl")
+	n, err := NewNotifier(config.SearchesConfig{Notifier: "file", File: path}, &MockLogger{})
+	if err != nil {
+		t.Fatalf("NewNotifier() error = %v", err)
+	}
+	for _, user := range []string{"user-1", "user-2"} {
+		if err = n.Notify(context.Background(), Digest{UserID: user, Results: []Result{{Total: 1}}}); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var users []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var d Digest
+		if err = json.Unmarshal(sc.Bytes(), &d); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		users = append(users, d.UserID)
+	}
+	if strings.Join(users, ",") != "user-1,user-2" {
+		t.Errorf("digests for %v, want user-1 and user-2", users)
+	}
+	if _, err = NewNotifier(config.SearchesConfig{Notifier: "mail"}, &MockLogger{}); err == nil {
+		t.Error("NewNotifier() with an unknown notifier error = nil")
+	}
+}
@@ -0,0 +1,27 @@
+package search
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	// Create saves a search unless the user has limit searches already.
+	Create(ctx context.Context, userID string, dto SearchDTO, limit int) (Search, error)
+	Update(ctx context.Context, userID string, id SearchID, dto SearchDTO) (Search, error)
+	Delete(ctx context.Context, userID string, id SearchID) error
+	Get(ctx context.Context, userID string, id SearchID) (Search, error)
+	List(ctx context.Context, userID string) ([]Search, error)
+	// Claim leases until leaseUntil the searches not checked up to until of
+	// at most users users, those waiting longest first.
+	Claim(ctx context.Context, until time.Time, users int, leaseUntil time.Time) ([]Due, error)
+	// Matches returns up to limit flats approved after the search was
+	// checked and no later than until, oldest approval first, and the
+	// number of all of them.
+	Matches(ctx context.Context, s Search, until time.Time, limit int) ([]Match, int, error)
+	// Checked moves the searches on to until and releases them.
+	Checked(ctx context.Context, ids []SearchID, until time.Time) error
+	// Release gives the searches up without moving them on. They are not
+	// claimed again before retryAt.
+	Release(ctx context.Context, ids []SearchID, retryAt time.Time) error
+}
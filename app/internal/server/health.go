@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/middleware"
+)
+
+const (
+	livenessURL  = "/healthz"
+	readinessURL = "/readyz"
+)
+
+// Liveness reports that the process is up.
+func (a *Server) Liveness(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// Readiness fails once shutdown has started or the database is unreachable,
+// so load balancers stop routing new requests to this instance.
+func (a *Server) Readiness(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestID(r.Context())
+	if !a.Lifecycle.Ready() {
+		notReadyErr := errors.New("shutting down")
+		apierror.Write(w, notReadyErr, reqID, http.StatusServiceUnavailable)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+	if err := a.DB.Ping(ctx); err != nil {
+		a.Logger.Errorf("readiness check failed req_id=%s: %v", reqID, err)
+		apierror.Write(w, errors.New("database unavailable"), reqID, http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
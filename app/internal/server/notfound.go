@@ -0,0 +1,65 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/gorilla/mux"
+)
+
+var knownMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// NotFound responds to unknown routes with a JSON error.
+func (a *Server) NotFound(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestID(r.Context())
+	notFoundErr := errors.New("route not found")
+	a.Logger.Errorf("not found req_id=%s: %s %s", reqID, r.Method, r.URL.Path)
+	apierror.Write(w, notFoundErr, reqID, http.StatusNotFound)
+}
+
+// MethodNotAllowed responds with a JSON error and lists the methods the
+// path supports in the Allow header.
+func (a *Server) MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestID(r.Context())
+	allowed := a.allowedMethods(r)
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	notAllowedErr := errors.New("method not allowed")
+	a.Logger.Errorf("method not allowed req_id=%s: %s %s", reqID, r.Method, r.URL.Path)
+	apierror.Write(w, notAllowedErr, reqID, http.StatusMethodNotAllowed)
+}
+
+// routeExists matches OPTIONS requests for paths served by some other route,
+// so preflights reach the CORS middleware while unknown paths still get 404.
+func (a *Server) routeExists(r *http.Request, _ *mux.RouteMatch) bool {
+	if r.Method != http.MethodOptions {
+		return false
+	}
+	return len(a.allowedMethods(r)) > 0
+}
+
+func (a *Server) allowedMethods(r *http.Request) []string {
+	allowed := make([]string, 0, len(knownMethods))
+	for _, method := range knownMethods {
+		probe := r.Clone(r.Context())
+		probe.Method = method
+		var match mux.RouteMatch
+		if a.Router.Match(probe, &match) && match.MatchErr == nil {
+			allowed = append(allowed, method)
+		}
+	}
+	// Preflights are answered for every existing path.
+	if len(allowed) > 0 {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	return allowed
+}
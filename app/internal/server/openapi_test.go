@@ -0,0 +1,128 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Polyrom/houses_api/internal/openapi"
+	"github.com/gorilla/mux"
+)
+
+// undocumented lists routes intentionally left out of the OpenAPI spec.
+var undocumented = map[string]bool{
+	metricsURL:      true,
+	"/openapi.json": true,
+	"/docs":         true,
+}
+
+func TestOpenAPI_MatchesRoutes(t *testing.T) {
+	s := newTestServer(t, newTestConfig(t))
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+
+	registered := make(map[string]bool)
+	err = s.Router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tpl, err := route.GetPathTemplate()
+		if err != nil || undocumented[tpl] {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		for _, m := range methods {
+			if m != http.MethodOptions {
+				registered[m+" "+tpl] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk router: %v", err)
+	}
+
+	documented := make(map[string]bool)
+	for path, item := range doc.Paths.Map() {
+		for m := range item.Operations() {
+			documented[m+" "+path] = true
+		}
+	}
+
+	var missing, stale []string
+	for op := range registered {
+		if !documented[op] {
+			missing = append(missing, op)
+		}
+	}
+	for op := range documented {
+		if !registered[op] {
+			stale = append(stale, op)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	if len(missing) > 0 {
+		t.Errorf("routes missing from openapi.yaml:\n  %s", strings.Join(missing, "\n  "))
+	}
+	if len(stale) > 0 {
+		t.Errorf("operations in openapi.yaml without a route:\n  %s", strings.Join(stale, "\n  "))
+	}
+}
+
+func TestOpenAPI_Served(t *testing.T) {
+	s := newTestServer(t, newTestConfig(t))
+	tests := []struct {
+		path            string
+		wantContentType string
+		wantBody        string
+	}{
+		{path: "/openapi.json", wantContentType: "application/json", wantBody: `"openapi"`},
+		{path: "/docs", wantContentType: "text/html; charset=utf-8", wantBody: "/api/v1/flat/create"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			s.Router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rr.Code != http.StatusOK {
+				t.Fatalf("code = %d, want %d", rr.Code, http.StatusOK)
+			}
+			if got := rr.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			if body := rr.Body.String(); !strings.Contains(body, tt.wantBody) || strings.Contains(body, "<script") {
+				t.Errorf("body does not contain %q or loads scripts", tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestOpenAPI_ValidatesAfterAuth(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.OpenAPI.Validate = true
+	s := newTestServer(t, cfg)
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		wantCode    int
+	}{
+		{name: "anonymous request to a protected route", path: "/api/v1/flat/create", contentType: "text/plain", wantCode: http.StatusUnauthorized},
+		{name: "public route", path: "/api/v1/register", contentType: "text/plain", wantCode: http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader("not json"))
+			req.Header.Set("Content-Type", tt.contentType)
+			rr := httptest.NewRecorder()
+			s.Router.ServeHTTP(rr, req)
+			if rr.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", rr.Code, tt.wantCode)
+			}
+		})
+	}
+}
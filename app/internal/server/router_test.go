@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Polyrom/houses_api/internal/config"
+	"github.com/gorilla/mux"
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+type MockLogger struct{}
+
+func (ml *MockLogger) Trace(args ...interface{})                   {}
+func (ml *MockLogger) Debug(args ...interface{})                   {}
+func (ml *MockLogger) Info(args ...interface{})                    {}
+func (ml *MockLogger) Warn(args ...interface{})                    {}
+func (ml *MockLogger) Warning(args ...interface{})                 {}
+func (ml *MockLogger) Error(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   { panic(fmt.Sprint(args...)) }
+func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
+func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
+func (ml *MockLogger) Infof(format string, args ...interface{})    {}
+func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
+func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
+func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   { panic(fmt.Sprintf(format, args...)) }
+func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
+
+// newTestConfig returns the default config with photos kept in a temporary
+// directory.
+func newTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg := &config.Config{}
+	if err := cleanenv.ReadEnv(cfg); err != nil {
+		t.Fatalf("read default config: %v", err)
+	}
+	cfg.Photos.Dir = t.TempDir()
+	return cfg
+}
+
+// newTestServer builds the real router without a database; only routes that
+// do not reach the database may be exercised. Startup failures panic through
+// the logger and fail the test.
+func newTestServer(t *testing.T, cfg *config.Config) *Server {
+	t.Helper()
+	s := New(cfg, &MockLogger{}, mux.NewRouter(), nil)
+	s.ConfigureRouter()
+	return s
+}
+
+func TestRouter_ErrorHandlers(t *testing.T) {
+	s := newTestServer(t, newTestConfig(t))
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		wantCode  int
+		wantAllow string
+	}{
+		{name: "unknown route", method: http.MethodGet, path: "/nope", wantCode: http.StatusNotFound},
+		{name: "options unknown route", method: http.MethodOptions, path: "/nope", wantCode: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodDelete, path: "/healthz", wantCode: http.StatusMethodNotAllowed, wantAllow: "GET, OPTIONS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-Request-ID", "test-id")
+			rr := httptest.NewRecorder()
+			s.Router.ServeHTTP(rr, req)
+			if rr.Code != tt.wantCode {
+				t.Errorf("code = %d, want %d", rr.Code, tt.wantCode)
+			}
+			if got := rr.Header().Get("Allow"); got != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", got, tt.wantAllow)
+			}
+			var body struct {
+				ReqID   string `json:"req_id"`
+				ErrCode int    `json:"err_code"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode error body: %v", err)
+			}
+			if body.ReqID != "test-id" || rr.Header().Get("X-Request-ID") != "test-id" {
+				t.Errorf("req_id = %q, header %q, want test-id", body.ReqID, rr.Header().Get("X-Request-ID"))
+			}
+			if body.ErrCode != tt.wantCode {
+				t.Errorf("err_code = %d, want %d", body.ErrCode, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRouter_LegacyRoutesDeprecated(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.API = config.APIConfig{LegacyDeprecatedAt: "2026-10-19", LegacySunset: "2027-04-19"}
+	s := newTestServer(t, cfg)
+	tests := []struct {
+		name           string
+		path           string
+		wantDeprecated bool
+	}{
+		{name: "versioned", path: "/api/v1/dummyLogin", wantDeprecated: false},
+		{name: "legacy alias", path: "/dummyLogin", wantDeprecated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			s.Router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if !strings.Contains(rr.Body.String(), "user type not specified") {
+				t.Fatalf("route %s not served by the handler: %d %s", tt.path, rr.Code, rr.Body.String())
+			}
+			gotDeprecated := rr.Header().Get("Deprecation") != ""
+			if gotDeprecated != tt.wantDeprecated {
+				t.Errorf("Deprecation header present = %v, want %v", gotDeprecated, tt.wantDeprecated)
+			}
+			if tt.wantDeprecated && rr.Header().Get("Sunset") != "Mon, 19 Apr 2027 00:00:00 GMT" {
+				t.Errorf("Sunset = %q", rr.Header().Get("Sunset"))
+			}
+		})
+	}
+}
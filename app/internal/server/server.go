@@ -2,82 +2,201 @@ package server
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/Polyrom/houses_api/internal/config"
+	"github.com/Polyrom/houses_api/internal/developer"
+	"github.com/Polyrom/houses_api/internal/events"
+	"github.com/Polyrom/houses_api/internal/export"
+	"github.com/Polyrom/houses_api/internal/favorite"
 	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/grpcapi"
+	"github.com/Polyrom/houses_api/internal/handlers"
 	"github.com/Polyrom/houses_api/internal/house"
+	"github.com/Polyrom/houses_api/internal/importer"
+	"github.com/Polyrom/houses_api/internal/lifecycle"
+	"github.com/Polyrom/houses_api/internal/metrics"
 	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/internal/openapi"
+	"github.com/Polyrom/houses_api/internal/photo"
+	"github.com/Polyrom/houses_api/internal/search"
+	"github.com/Polyrom/houses_api/internal/stats"
 	"github.com/Polyrom/houses_api/internal/user"
+	"github.com/Polyrom/houses_api/internal/webhook"
 	"github.com/Polyrom/houses_api/pkg/logging"
 	"github.com/gorilla/mux"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/grpc"
+)
+
+const (
+	apiV1Prefix       = "/api/v1"
+	metricsURL        = "/debug/vars"
+	multipartOverhead = 64 << 10
 )
 
 type Server struct {
-	Cfg    *config.Config
-	Logger logging.Logger
-	Router *mux.Router
-	DB     *pgxpool.Pool
+	Cfg       *config.Config
+	Logger    logging.Logger
+	Router    *mux.Router
+	DB        *pgxpool.Pool
+	Lifecycle *lifecycle.Manager
+	GRPC      *grpc.Server
+	svc       *services
 }
 
 func (a *Server) ConfigureRouter() {
 	ridmw := middleware.NewReqIDMiddleware(a.Logger)
-	a.Router.Use(ridmw.DoInMiddle)
-	authMwRepo := middleware.NewRepository(a.DB, a.Logger)
-	authMwService := middleware.NewService(authMwRepo, a.Logger)
-	isAuthMw := middleware.NewAuthMiddleware(authMwService, a.Logger)
-	isModerMw := middleware.NewIsModerMiddleware(authMwService, a.Logger)
-	urepo := user.NewRepository(a.DB, a.Logger)
-	us := user.NewService(urepo, a.Logger)
-	ur := user.NewHandler(us, a.Logger)
-	ur.Register(a.Router)
-	hrepo := house.NewRepository(a.DB, a.Logger)
-	hs := house.NewService(hrepo, a.Logger)
-	hr := house.NewHandler(isAuthMw, isModerMw, hs, a.Logger)
-	hr.Register(a.Router)
-	frepo := flat.NewRepository(a.DB, a.Logger)
-	fs := flat.NewService(frepo, a.Logger)
-	fr := flat.NewHandler(isAuthMw, isModerMw, fs, a.Logger)
-	fr.Register(a.Router)
+	recmw := middleware.NewRecoveryMiddleware(a.Logger)
+	corsmw := middleware.NewCORSMiddleware(a.Cfg.CORS, a.Logger)
+	// Photo uploads are larger than JSON bodies; the form around the file
+	// gets some room on top of the photo limit.
+	uploadLimits := make(map[string]int64)
+	for _, u := range photo.UploadURLs() {
+		uploadLimits[apiV1Prefix+u] = a.Cfg.Photos.MaxBytes + multipartOverhead
+	}
+	blmw := middleware.NewBodyLimitMiddleware(a.Cfg.Listen.MaxBodyBytes, uploadLimits, a.Logger)
+	a.Router.Use(ridmw.DoInMiddle, recmw.DoInMiddle, corsmw.DoInMiddle, blmw.DoInMiddle)
+	doc, err := openapi.Load()
+	if err != nil {
+		a.Logger.Fatalf("load OpenAPI spec: %v", err)
+	}
+	// Requests are validated after authentication, so that anonymous
+	// callers get a 401 rather than learn the schema from a 400.
+	valmw := middleware.Chain()
+	if a.Cfg.OpenAPI.Validate {
+		valmw, err = openapi.NewValidationMiddleware(doc, a.Cfg.OpenAPI.ValidateResponses, a.Logger)
+		if err != nil {
+			a.Logger.Fatalf("build OpenAPI validator: %v", err)
+		}
+	}
+	// Router middlewares do not run for unmatched routes, so the error
+	// handlers get the request ID and CORS headers explicitly.
+	errmw := middleware.Chain(ridmw, recmw, corsmw)
+	a.Router.NotFoundHandler = errmw.DoInMiddle(http.HandlerFunc(a.NotFound))
+	a.Router.MethodNotAllowedHandler = errmw.DoInMiddle(http.HandlerFunc(a.MethodNotAllowed))
+	a.Router.Methods(http.MethodOptions).MatcherFunc(a.routeExists).HandlerFunc(middleware.PreflightHandler)
+	a.Router.HandleFunc(livenessURL, a.Liveness).Methods(http.MethodGet)
+	a.Router.HandleFunc(readinessURL, a.Readiness).Methods(http.MethodGet)
+	oh, err := openapi.NewHandler(doc, a.Logger)
+	if err != nil {
+		a.Logger.Fatalf("build OpenAPI handler: %v", err)
+	}
+	oh.Register(a.Router)
+	svc := a.services()
+	rlmw := middleware.NewRateLimitMiddleware(svc.limiter, a.Logger)
+	armw := middleware.NewAuthRateLimitMiddleware(svc.limiter, a.Logger)
+	publicMw := middleware.Chain(rlmw, valmw)
+	isAuthMw := middleware.Chain(armw, middleware.NewAuthMiddleware(svc.auth, a.Logger), rlmw, valmw)
+	isModerMw := middleware.Chain(armw, middleware.NewIsModerMiddleware(svc.auth, a.Logger), rlmw, valmw)
+	// Metrics include the command line and memory stats of the process.
+	a.Router.Handle(metricsURL, isModerMw.DoInMiddle(metrics.Handler())).Methods(http.MethodGet)
+	ur := user.NewHandler(publicMw, svc.users, a.Logger)
+	hr := house.NewHandler(isAuthMw, isModerMw, svc.houses, a.Logger)
+	dr := developer.NewHandler(isAuthMw, isModerMw, svc.developers, a.Logger)
+	fr := flat.NewHandler(isAuthMw, isModerMw, svc.flats, a.Logger)
+	er := events.NewHandler(isAuthMw, svc.events, svc.houses, svc.favorites, a.Cfg.Events.Heartbeat, a.Logger)
+	wr := webhook.NewHandler(isModerMw, svc.webhooks, a.Logger)
+	ir := importer.NewHandler(isModerMw, svc.importer, a.Logger)
+	xr := export.NewHandler(isModerMw, svc.exporter, a.Logger)
+	pr := photo.NewHandler(isAuthMw, isModerMw, svc.photos, a.Logger)
+	sr := stats.NewHandler(isAuthMw, svc.stats, a.Logger)
+	fvr := favorite.NewHandler(isAuthMw, svc.favorites, a.Logger)
+	swr := search.NewHandler(isAuthMw, svc.searches, a.Logger)
+
+	v1 := a.Router.PathPrefix(apiV1Prefix).Subrouter()
+	deprecatedAt, sunset, err := a.Cfg.API.LegacyDates()
+	if err != nil {
+		a.Logger.Warnf("legacy route dates not configured: %v", err)
+	}
+	legacy := a.Router.NewRoute().Subrouter()
+	legacy.Use(middleware.NewDeprecationMiddleware(deprecatedAt, sunset, apiV1Prefix, flat.LegacyURLs(), a.Logger).DoInMiddle)
+	for _, h := range []handlers.Handler{ur, hr, dr, fr, er, wr, ir, xr, pr, sr, fvr, swr} {
+		h.Register(v1)
+		if dh, ok := h.(handlers.DeprecatedHandler); ok {
+			dh.RegisterDeprecated(legacy)
+		}
+	}
 }
 
-func (a *Server) Run() {
+// ConfigureGRPC builds the gRPC server on top of the same services as the
+// HTTP routes.
+func (a *Server) ConfigureGRPC() {
+	svc := a.services()
+	a.GRPC = grpcapi.NewServer(svc.auth, svc.limiter, svc.users, svc.houses, svc.flats, a.Logger)
+}
+
+// Run serves HTTP (and gRPC if configured) until SIGINT or SIGTERM, then
+// drains in-flight requests, stops background workers and closes the
+// database pool.
+func (a *Server) Run() error {
 	a.Logger.Info("start application")
+	addr := net.JoinHostPort(a.Cfg.Listen.Host, a.Cfg.Listen.Port)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen %s: %w", addr, err)
+	}
 	srv := &http.Server{
-		Addr:         net.JoinHostPort(a.Cfg.Listen.Host, a.Cfg.Listen.Port),
 		WriteTimeout: time.Second * 15,
 		ReadTimeout:  time.Second * 15,
 		IdleTimeout:  time.Second * 60,
 		Handler:      a.Router,
 	}
+	if a.svc != nil {
+		// Event streams never finish on their own, end them when draining starts.
+		srv.RegisterOnShutdown(a.svc.broker.Close)
+	}
+	a.Lifecycle.AddServer("http", func() error {
+		err := srv.Serve(ln)
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}, srv.Shutdown)
+	a.Logger.Infof("server started at %s", addr)
+	if a.GRPC != nil {
+		grpcAddr := net.JoinHostPort(a.Cfg.Listen.Host, a.Cfg.GRPC.Port)
+		grpcLn, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			_ = ln.Close()
+			return fmt.Errorf("listen %s: %w", grpcAddr, err)
+		}
+		a.Lifecycle.AddServer("grpc", func() error {
+			return a.GRPC.Serve(grpcLn)
+		}, a.shutdownGRPC)
+		a.Logger.Infof("grpc server started at %s", grpcAddr)
+	}
+	a.Lifecycle.AddCloser("postgres", a.DB.Close)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return a.Lifecycle.Run(ctx)
+}
 
+// shutdownGRPC waits for in-flight calls and cancels them when ctx expires.
+func (a *Server) shutdownGRPC(ctx context.Context) error {
+	done := make(chan struct{})
 	go func() {
-		if err := srv.ListenAndServe(); err != nil {
-			a.Logger.Error(err)
-		}
+		a.GRPC.GracefulStop()
+		close(done)
 	}()
-	a.Logger.Infof("server started at :%s", a.Cfg.Listen.Port)
-
-	// Graceful shutdown
-	shutdown := make(chan os.Signal, 1)
-	signal.Notify(shutdown, os.Interrupt)
-	<-shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	a.Logger.Info("shutting down")
-	err := srv.Shutdown(ctx)
-	if err != nil {
-		log.Fatalf("shutdown server error: %v", err)
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		a.GRPC.Stop()
+		return ctx.Err()
 	}
-	os.Exit(0)
 }
 
 func New(cfg *config.Config, logger logging.Logger, router *mux.Router, db *pgxpool.Pool) *Server {
-	return &Server{Cfg: cfg, Logger: logger, Router: router, DB: db}
+	lc := lifecycle.New(logger, cfg.Shutdown.Timeout, cfg.Shutdown.ReadinessDelay)
+	return &Server{Cfg: cfg, Logger: logger, Router: router, DB: db, Lifecycle: lc}
 }
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+
+	"github.com/Polyrom/houses_api/internal/blob"
+	"github.com/Polyrom/houses_api/internal/developer"
+	"github.com/Polyrom/houses_api/internal/events"
+	"github.com/Polyrom/houses_api/internal/export"
+	"github.com/Polyrom/houses_api/internal/favorite"
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/house"
+	"github.com/Polyrom/houses_api/internal/importer"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/internal/photo"
+	"github.com/Polyrom/houses_api/internal/search"
+	"github.com/Polyrom/houses_api/internal/stats"
+	"github.com/Polyrom/houses_api/internal/user"
+	"github.com/Polyrom/houses_api/internal/webhook"
+)
+
+// services are shared by the HTTP and gRPC APIs, so observers registered on
+// them see changes made through either.
+type services struct {
+	auth       middleware.Service
+	limiter    *middleware.RateLimiter
+	users      *user.Service
+	houses     *house.Service
+	developers *developer.Service
+	flats      *flat.Service
+	events     *events.Service
+	broker     *events.Broker
+	webhooks   *webhook.Service
+	importer   *importer.Service
+	exporter   *export.Service
+	photos     *photo.Service
+	stats      *stats.Service
+	favorites  *favorite.Service
+	searches   *search.Service
+}
+
+// services builds the domain services on first use and registers their
+// background workers.
+func (a *Server) services() *services {
+	if a.svc != nil {
+		return a.svc
+	}
+	svc := &services{
+		auth:       middleware.NewService(middleware.NewRepository(a.DB, a.Logger), a.Logger),
+		limiter:    middleware.NewRateLimiter(a.Cfg.RateLimit),
+		users:      user.NewService(user.NewRepository(a.DB, a.Logger), a.Logger),
+		houses:     house.NewService(house.NewRepository(a.DB, a.Logger), a.Logger),
+		developers: developer.NewService(developer.NewRepository(a.DB, a.Logger), a.Logger),
+		flats:      flat.NewService(flat.NewRepository(a.DB, a.Logger), a.Logger),
+		broker:     events.NewBroker(a.Cfg.Events.BufferSize),
+		importer:   importer.NewService(importer.NewRepository(a.DB, a.Logger), a.Logger),
+		exporter:   export.NewService(export.NewRepository(a.DB, a.Logger), a.Logger),
+		stats:      stats.NewService(stats.NewRepository(a.DB, a.Logger), a.Cfg.Stats.CacheTTL, a.Logger),
+		favorites:  favorite.NewService(favorite.NewRepository(a.DB, a.Logger), a.Logger),
+	}
+	photoStorage, err := blob.NewLocal(a.Cfg.Photos.Dir)
+	if err != nil {
+		a.Logger.Fatalf("open photo storage: %v", err)
+	}
+	svc.photos = photo.NewService(photo.NewRepository(a.DB, a.Logger), photoStorage, a.Cfg.Photos, a.Logger)
+	notifier, err := search.NewNotifier(a.Cfg.Searches, a.Logger)
+	if err != nil {
+		a.Logger.Fatalf("create search notifier: %v", err)
+	}
+	svc.searches = search.NewService(search.NewRepository(a.DB, a.Logger), notifier, a.Cfg.Searches, a.Logger)
+	svc.events = events.NewService(events.NewRepository(a.DB, a.Logger), svc.broker, a.Logger)
+	svc.webhooks = webhook.NewService(webhook.NewRepository(a.DB, a.Logger), a.Cfg.Webhooks, a.Logger)
+	svc.flats.AddObserver(svc.events)
+	svc.flats.AddObserver(svc.webhooks)
+	svc.flats.AddObserver(svc.stats)
+	svc.importer.AddObserver(svc.stats)
+	a.Lifecycle.AddWorker("events", svc.events.Run)
+	a.Lifecycle.AddWorker("events retention", func(ctx context.Context) error {
+		return svc.events.RunRetention(ctx, a.Cfg.Events.Retention)
+	})
+	a.Lifecycle.AddWorker("webhook delivery", svc.webhooks.Run)
+	a.Lifecycle.AddWorker("saved searches", svc.searches.Run)
+	a.svc = svc
+	return svc
+}
@@ -0,0 +1,95 @@
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/handlers"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/gorilla/mux"
+)
+
+const (
+	catalogURL   = "/stats"
+	houseURL     = "/house/{id}/stats"
+	developerURL = "/developers/{id}/stats"
+)
+
+type handler struct {
+	aumw middleware.Middleware
+	s    *Service
+	l    logging.Logger
+}
+
+func NewHandler(aumw middleware.Middleware, s *Service, l logging.Logger) handlers.Handler {
+	return &handler{aumw: aumw, s: s, l: l}
+}
+
+func (h *handler) Register(r *mux.Router) {
+	r.Handle(catalogURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Catalog))).Methods(http.MethodGet)
+	r.Handle(houseURL, h.aumw.DoInMiddle(http.HandlerFunc(h.House))).Methods(http.MethodGet)
+	r.Handle(developerURL, h.aumw.DoInMiddle(http.HandlerFunc(h.Developer))).Methods(http.MethodGet)
+}
+
+// Catalog covers every flat or, with the city and district parameters,
+// the flats of a city or one of its districts.
+func (h *handler) Catalog(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	sc := Scope{City: q.Get("city"), District: q.Get("district")}
+	if sc.District != "" && sc.City == "" {
+		reqID := middleware.RequestID(r.Context())
+		noCityErr := errors.New("district requires city")
+		h.l.Errorf("bad request req_id=%s: %v", reqID, noCityErr)
+		apierror.Write(w, noCityErr, reqID, http.StatusBadRequest)
+		return
+	}
+	h.get(w, r, sc)
+}
+
+func (h *handler) House(w http.ResponseWriter, r *http.Request) {
+	if id, ok := h.id(w, r, "house"); ok {
+		h.get(w, r, Scope{HouseID: id})
+	}
+}
+
+func (h *handler) Developer(w http.ResponseWriter, r *http.Request) {
+	if id, ok := h.id(w, r, "developer"); ok {
+		h.get(w, r, Scope{DeveloperID: id})
+	}
+}
+
+func (h *handler) get(w http.ResponseWriter, r *http.Request, sc Scope) {
+	reqID := middleware.RequestID(r.Context())
+	st, err := h.s.Get(r.Context(), sc)
+	if errors.Is(err, ErrNotFound) {
+		h.l.Errorf("not found req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(st); err != nil {
+		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+	}
+}
+
+func (h *handler) id(w http.ResponseWriter, r *http.Request, target string) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id < 1 {
+		reqID := middleware.RequestID(r.Context())
+		invalidIDErr := fmt.Errorf("invalid %s id", target)
+		h.l.Errorf("bad request req_id=%s: %v", reqID, invalidIDErr)
+		apierror.Write(w, invalidIDErr, reqID, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,31 @@
+package stats
+
+// Scope selects the flats statistics are computed over. Zero values match
+// every flat, so the zero Scope is the whole catalog.
+type Scope struct {
+	HouseID     int
+	DeveloperID int
+	City        string
+	District    string
+}
+
+// Summary aggregates a value over the flats it is known for.
+type Summary struct {
+	Min    *float64 `json:"min,omitempty"`
+	Max    *float64 `json:"max,omitempty"`
+	Avg    *float64 `json:"avg,omitempty"`
+	Median *float64 `json:"median,omitempty"`
+}
+
+// Stats describes the approved flats of a scope. PricePerRoom leaves out
+// flats without rooms and PricePerM2 flats without a total area. Rooms
+// maps a room count to the number of flats. Statuses counts flats of every
+// status and is only shown to moderators.
+type Stats struct {
+	Flats        int            `json:"flats"`
+	Price        Summary        `json:"price"`
+	PricePerRoom Summary        `json:"price_per_room"`
+	PricePerM2   Summary        `json:"price_per_m2"`
+	Rooms        map[int]int    `json:"rooms"`
+	Statuses     map[string]int `json:"statuses,omitempty"`
+}
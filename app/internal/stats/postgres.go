@@ -0,0 +1,142 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Polyrom/houses_api/pkg/client/postgres"
+	"github.com/Polyrom/houses_api/pkg/logging"
+)
+
+type repository struct {
+	client postgres.Client
+	logger logging.Logger
+}
+
+// scopeClause returns the conditions selecting the flats of s, joined with
+// their houses as f and h, and the values of its parameters.
+func scopeClause(s Scope) (string, []any) {
+	var b strings.Builder
+	b.WriteString("TRUE")
+	var args []any
+	cond := func(expr string, v any) {
+		args = append(args, v)
+		fmt.Fprintf(&b, "\n\t\t\t\tAND "+expr, len(args))
+	}
+	if s.HouseID != 0 {
+		cond("f.house_id = $%d", s.HouseID)
+	}
+	if s.DeveloperID != 0 {
+		cond("h.developer_id = $%d", s.DeveloperID)
+	}
+	if s.City != "" {
+		cond("lower(h.city) = lower($%d)", s.City)
+	}
+	if s.District != "" {
+		cond("lower(h.district) = lower($%d)", s.District)
+	}
+	return b.String(), args
+}
+
+func (r *repository) Exists(ctx context.Context, s Scope) (bool, error) {
+	q := `SELECT EXISTS (SELECT 1 FROM houses WHERE id = $1)`
+	id := s.HouseID
+	if s.DeveloperID != 0 {
+		q = `SELECT EXISTS (SELECT 1 FROM developers WHERE id = $1)`
+		id = s.DeveloperID
+	}
+	var exists bool
+	err := r.client.QueryRow(ctx, q, id).Scan(&exists)
+	return exists, err
+}
+
+// Compute aggregates in three queries: prices of approved flats, approved
+// flats by rooms and all flats by status.
+func (r *repository) Compute(ctx context.Context, s Scope) (Stats, error) {
+	where, args := scopeClause(s)
+	from := `
+				FROM 
+					flats f 
+					JOIN houses h ON h.id = f.house_id 
+				WHERE ` + where
+	q := `WITH v AS (
+					SELECT 
+						f.price::float8 AS price, 
+						f.price::float8 / NULLIF(f.rooms, 0) AS per_room, 
+						f.price::float8 / f.total_area::float8 AS per_m2` + from + `
+					AND f.status = 'approved'
+				)
+				SELECT 
+					COUNT(*),
+					MIN(price), MAX(price), AVG(price), percentile_cont(0.5) WITHIN GROUP (ORDER BY price),
+					MIN(per_room), MAX(per_room), AVG(per_room), percentile_cont(0.5) WITHIN GROUP (ORDER BY per_room),
+					MIN(per_m2), MAX(per_m2), AVG(per_m2), percentile_cont(0.5) WITHIN GROUP (ORDER BY per_m2)
+				FROM 
+					v`
+	var st Stats
+	pr, room, m2 := &st.Price, &st.PricePerRoom, &st.PricePerM2
+	err := r.client.QueryRow(ctx, q, args...).Scan(&st.Flats,
+		&pr.Min, &pr.Max, &pr.Avg, &pr.Median,
+		&room.Min, &room.Max, &room.Avg, &room.Median,
+		&m2.Min, &m2.Max, &m2.Avg, &m2.Median)
+	if err != nil {
+		return Stats{}, err
+	}
+	if st.Rooms, err = r.rooms(ctx, from, args); err != nil {
+		return Stats{}, err
+	}
+	if st.Statuses, err = r.statuses(ctx, from, args); err != nil {
+		return Stats{}, err
+	}
+	return st, nil
+}
+
+func (r *repository) rooms(ctx context.Context, from string, args []any) (map[int]int, error) {
+	q := `SELECT 
+					f.rooms, COUNT(*)` + from + `
+				AND f.status = 'approved'
+				GROUP BY f.rooms`
+	rows, err := r.client.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := make(map[int]int)
+	for rows.Next() {
+		var rooms, n int
+		if err = rows.Scan(&rooms, &n); err != nil {
+			return nil, err
+		}
+		counts[rooms] = n
+	}
+	return counts, rows.Err()
+}
+
+func (r *repository) statuses(ctx context.Context, from string, args []any) (map[string]int, error) {
+	q := `SELECT 
+					f.status, COUNT(*)` + from + `
+				GROUP BY f.status`
+	rows, err := r.client.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var n int
+		if err = rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
+
+func NewRepository(c postgres.Client, l logging.Logger) Repository {
+	return &repository{
+		client: c,
+		logger: l,
+	}
+}
@@ -0,0 +1,123 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/importer"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/pkg/logging"
+)
+
+var ErrNotFound = errors.New("house or developer not found")
+
+// maxEntries bounds the cache, cities and districts come from users.
+const maxEntries = 1000
+
+type entry struct {
+	stats     Stats
+	expiresAt time.Time
+}
+
+// Service computes statistics and caches them for ttl. Any change of a
+// flat made through flat.Service, and any import, drops the whole cache,
+// since it affects the catalog and the house, developer and district of
+// the flat alike.
+type Service struct {
+	repo   Repository
+	ttl    time.Duration
+	logger logging.Logger
+
+	mu         sync.Mutex
+	cache      map[Scope]entry
+	generation uint64
+	now        func() time.Time
+}
+
+// Get returns the statistics of s. Status counts are left out for users
+// other than moderators.
+func (s *Service) Get(ctx context.Context, sc Scope) (Stats, error) {
+	st, err := s.get(ctx, sc)
+	if err != nil {
+		return Stats{}, err
+	}
+	if role, _ := middleware.CurrentRole(ctx); role != middleware.Moderator {
+		st.Statuses = nil
+	}
+	return st, nil
+}
+
+func (s *Service) get(ctx context.Context, sc Scope) (Stats, error) {
+	sc.City = strings.ToLower(strings.TrimSpace(sc.City))
+	sc.District = strings.ToLower(strings.TrimSpace(sc.District))
+	s.mu.Lock()
+	e, ok := s.cache[sc]
+	gen := s.generation
+	s.mu.Unlock()
+	if ok && s.now().Before(e.expiresAt) {
+		return e.stats, nil
+	}
+	if sc.HouseID != 0 || sc.DeveloperID != 0 {
+		exists, err := s.repo.Exists(ctx, sc)
+		if err != nil {
+			return Stats{}, err
+		}
+		if !exists {
+			return Stats{}, ErrNotFound
+		}
+	}
+	st, err := s.repo.Compute(ctx, sc)
+	if err != nil {
+		return Stats{}, err
+	}
+	s.mu.Lock()
+	// statistics computed while a flat changed may miss the change
+	if s.ttl > 0 && gen == s.generation {
+		s.evict()
+		s.cache[sc] = entry{stats: st, expiresAt: s.now().Add(s.ttl)}
+	}
+	s.mu.Unlock()
+	return st, nil
+}
+
+// evict makes room for an entry, dropping expired ones or, if none has
+// expired, everything. s.mu must be held.
+func (s *Service) evict() {
+	if len(s.cache) < maxEntries {
+		return
+	}
+	now := s.now()
+	for sc, e := range s.cache {
+		if !now.Before(e.expiresAt) {
+			delete(s.cache, sc)
+		}
+	}
+	if len(s.cache) >= maxEntries {
+		clear(s.cache)
+	}
+}
+
+// OnFlatEvent drops the cache.
+func (s *Service) OnFlatEvent(ctx context.Context, e flat.Event) {
+	s.invalidate()
+}
+
+// OnImport drops the cache.
+func (s *Service) OnImport(ctx context.Context, kind importer.Kind, inserted int) {
+	s.invalidate()
+}
+
+func (s *Service) invalidate() {
+	s.mu.Lock()
+	s.generation++
+	clear(s.cache)
+	s.mu.Unlock()
+}
+
+func NewService(r Repository, ttl time.Duration, l logging.Logger) *Service {
+	return &Service{repo: r, ttl: ttl, logger: l, cache: make(map[Scope]entry), now: time.Now}
+}
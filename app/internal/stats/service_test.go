@@ -0,0 +1,170 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/importer"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/internal/middleware/middlewaretest"
+	"github.com/gorilla/mux"
+)
+
+type MockLogger struct{}
+
+func (ml *MockLogger) Trace(args ...interface{})                   {}
+func (ml *MockLogger) Debug(args ...interface{})                   {}
+func (ml *MockLogger) Info(args ...interface{})                    {}
+func (ml *MockLogger) Warn(args ...interface{})                    {}
+func (ml *MockLogger) Warning(args ...interface{})                 {}
+func (ml *MockLogger) Error(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   {}
+func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
+func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
+func (ml *MockLogger) Infof(format string, args ...interface{})    {}
+func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
+func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
+func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
+func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
+
+// MockStatsRepo knows house 1 and developer 1 and counts the computations.
+type MockStatsRepo struct {
+	computed []Scope
+}
+
+func (mr *MockStatsRepo) Exists(ctx context.Context, s Scope) (bool, error) {
+	return s.HouseID == 1 || s.DeveloperID == 1, nil
+}
+
+func (mr *MockStatsRepo) Compute(ctx context.Context, s Scope) (Stats, error) {
+	mr.computed = append(mr.computed, s)
+	price := float64(len(mr.computed))
+	return Stats{
+		Flats:    1,
+		Price:    Summary{Min: &price, Max: &price, Avg: &price, Median: &price},
+		Rooms:    map[int]int{2: 1},
+		Statuses: map[string]int{"approved": 1, "created": 3},
+	}, nil
+}
+
+func roleCtx(role middleware.Role) context.Context {
+	return context.WithValue(context.Background(), middleware.UserRole, role)
+}
+
+func TestService_Cache(t *testing.T) {
+	repo := &MockStatsRepo{}
+	s := NewService(repo, time.Minute, &MockLogger{})
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	ctx := roleCtx(middleware.Client)
+	get := func(sc Scope) float64 {
+		t.Helper()
+		st, err := s.Get(ctx, sc)
+		if err != nil {
+			t.Fatalf("Service.Get() error = %v", err)
+		}
+		return *st.Price.Avg
+	}
+
+	if got := get(Scope{HouseID: 1}); got != 1 {
+		t.Fatalf("first computation = %v, want 1", got)
+	}
+	if got := get(Scope{HouseID: 1}); got != 1 || len(repo.computed) != 1 {
+		t.Errorf("cached = %v after %d computations, want 1 after 1", got, len(repo.computed))
+	}
+	get(Scope{City: " Moscow ", District: "Arbat"})
+	get(Scope{City: "moscow", District: "arbat"})
+	if len(repo.computed) != 2 {
+		t.Errorf("computations = %d, want spellings of a district to share an entry", len(repo.computed))
+	}
+
+	s.OnFlatEvent(ctx, flat.Event{Type: flat.EventPriceChanged})
+	if got := get(Scope{HouseID: 1}); got != 3 {
+		t.Errorf("after a flat event = %v, want a new computation", got)
+	}
+
+	s.OnImport(ctx, importer.KindFlats, 10)
+	if got := get(Scope{HouseID: 1}); got != 4 {
+		t.Errorf("after an import = %v, want a new computation", got)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if got := get(Scope{HouseID: 1}); got != 5 {
+		t.Errorf("after expiry = %v, want a new computation", got)
+	}
+}
+
+func TestService_Get(t *testing.T) {
+	s := NewService(&MockStatsRepo{}, 0, &MockLogger{})
+	if _, err := s.Get(roleCtx(middleware.Client), Scope{DeveloperID: 2}); err != ErrNotFound {
+		t.Errorf("missing developer error = %v, want %v", err, ErrNotFound)
+	}
+	st, err := s.Get(roleCtx(middleware.Client), Scope{})
+	if err != nil || st.Statuses != nil {
+		t.Errorf("client got statuses %v, %v", st.Statuses, err)
+	}
+	st, err = s.Get(roleCtx(middleware.Moderator), Scope{})
+	if err != nil || st.Statuses["created"] != 3 {
+		t.Errorf("moderator got statuses %v, %v", st.Statuses, err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	router := mux.NewRouter()
+	NewHandler(middlewaretest.Auth{}, NewService(&MockStatsRepo{}, time.Minute, &MockLogger{}), &MockLogger{}).Register(router)
+	tests := []struct {
+		name string
+		url  string
+		role middleware.Role
+		code int
+		body string
+	}{
+		{name: "catalog", url: "/stats", role: middleware.Client, code: http.StatusOK, body: `"rooms":{"2":1}`},
+		{name: "district", url: "/stats?city=Moscow&district=Arbat", role: middleware.Client, code: http.StatusOK},
+		{name: "district without city", url: "/stats?district=Arbat", role: middleware.Client, code: http.StatusBadRequest},
+		{name: "house for moderator", url: "/house/1/stats", role: middleware.Moderator, code: http.StatusOK, body: `"statuses":{"approved":1,"created":3}`},
+		{name: "missing house", url: "/house/2/stats", role: middleware.Client, code: http.StatusNotFound},
+		{name: "developer", url: "/developers/1/stats", role: middleware.Client, code: http.StatusOK},
+		{name: "invalid developer", url: "/developers/x/stats", role: middleware.Client, code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			req.Header.Set("X-Role", string(tt.role))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			if rr.Code != tt.code {
+				t.Fatalf("code = %d, want %d: %s", rr.Code, tt.code, rr.Body)
+			}
+			if !strings.Contains(rr.Body.String(), tt.body) {
+				t.Errorf("body = %s, want it to contain %s", rr.Body, tt.body)
+			}
+			if tt.code == http.StatusOK && tt.role == middleware.Client {
+				var st Stats
+				if err := json.Unmarshal(rr.Body.Bytes(), &st); err != nil || st.Statuses != nil {
+					t.Errorf("client response %s has statuses or is invalid: %v", rr.Body, err)
+				}
+			}
+		})
+	}
+}
+
+func TestScopeClause(t *testing.T) {
+	where, args := scopeClause(Scope{DeveloperID: 3, City: "moscow"})
+	if !strings.Contains(where, "h.developer_id = $1") || !strings.Contains(where, "lower(h.city) = lower($2)") {
+		t.Errorf("where = %q", where)
+	}
+	if len(args) != 2 || args[0] != 3 || args[1] != "moscow" {
+		t.Errorf("args = %v", args)
+	}
+	if where, args = scopeClause(Scope{}); where != "TRUE" || len(args) != 0 {
+		t.Errorf("catalog = %q, %v", where, args)
+	}
+}
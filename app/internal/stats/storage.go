@@ -0,0 +1,9 @@
+package stats
+
+import "context"
+
+type Repository interface {
+	// Exists reports whether the house or the developer of the scope exists.
+	Exists(ctx context.Context, s Scope) (bool, error)
+	Compute(ctx context.Context, s Scope) (Stats, error)
+}
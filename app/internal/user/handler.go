@@ -22,27 +22,32 @@ const (
 )
 
 type handler struct {
-	s *Service
-	l logging.Logger
+	rlmw middleware.Middleware
+	s    *Service
+	l    logging.Logger
 }
 
-func NewHandler(s *Service, l logging.Logger) handlers.Handler {
-	return &handler{s, l}
+func NewHandler(rlmw middleware.Middleware, s *Service, l logging.Logger) handlers.Handler {
+	return &handler{rlmw: rlmw, s: s, l: l}
 }
 
 func (h *handler) Register(r *mux.Router) {
-	r.HandleFunc(loginURL, h.UserLogin).Methods(http.MethodPost)
-	r.HandleFunc(registerURL, h.UserRegister).Methods(http.MethodPost)
-	r.HandleFunc(dummyLoginURL, h.UserDummyLogin).Methods(http.MethodGet)
+	r.Handle(loginURL, h.rlmw.DoInMiddle(http.HandlerFunc(h.UserLogin))).Methods(http.MethodPost)
+	r.Handle(registerURL, h.rlmw.DoInMiddle(http.HandlerFunc(h.UserRegister))).Methods(http.MethodPost)
+	r.Handle(dummyLoginURL, h.rlmw.DoInMiddle(http.HandlerFunc(h.UserDummyLogin))).Methods(http.MethodGet)
+}
+
+func (h *handler) RegisterDeprecated(r *mux.Router) {
+	h.Register(r)
 }
 
 func (h *handler) UserRegister(w http.ResponseWriter, r *http.Request) {
-	reqID := r.Context().Value(middleware.ContextKeyRequestID).(string)
+	reqID := middleware.RequestID(r.Context())
 	var userdto UserRegisterDTO
-	err := json.NewDecoder(r.Body).Decode(&userdto)
+	code, err := handlers.DecodeJSON(r, &userdto)
 	if err != nil {
 		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
-		apierror.Write(w, err, reqID, http.StatusBadRequest)
+		apierror.Write(w, err, reqID, code)
 		return
 	}
 	validate := validator.New()
@@ -75,31 +80,25 @@ func (h *handler) UserRegister(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *handler) UserLogin(w http.ResponseWriter, r *http.Request) {
-	reqID := r.Context().Value(middleware.ContextKeyRequestID).(string)
+	reqID := middleware.RequestID(r.Context())
 	var uldto UserLoginDTO
-	err := json.NewDecoder(r.Body).Decode(&uldto)
+	code, err := handlers.DecodeJSON(r, &uldto)
 	if err != nil {
 		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
-		apierror.Write(w, err, reqID, http.StatusBadRequest)
+		apierror.Write(w, err, reqID, code)
 		return
 	}
-	storedUser, err := h.s.GetByID(r.Context(), uldto.UserID)
-	if err != nil {
-		notFoundErr := errors.New("user not found")
+	token, err := h.s.Login(r.Context(), uldto.UserID, uldto.Password)
+	switch {
+	case errors.Is(err, ErrNotFound):
 		h.l.Errorf("user not found req_id=%s: %v", reqID, err)
-		apierror.Write(w, notFoundErr, reqID, http.StatusNotFound)
+		apierror.Write(w, ErrNotFound, reqID, http.StatusNotFound)
 		return
-	}
-	err = storedUser.VerifyPassword(uldto.Password)
-	if err != nil {
-		passwErr := errors.New("wrong password")
+	case errors.Is(err, ErrWrongPassword):
 		h.l.Errorf("wrong password req_id=%s: %v", reqID, err)
-		apierror.Write(w, passwErr, reqID, http.StatusUnauthorized)
+		apierror.Write(w, ErrWrongPassword, reqID, http.StatusUnauthorized)
 		return
-	}
-	token := storedUser.GenerateToken()
-	err = h.s.AddToken(r.Context(), storedUser.ID, token)
-	if err != nil {
+	case err != nil:
 		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
 		apierror.Write(w, err, reqID, http.StatusInternalServerError)
 		return
@@ -116,7 +115,7 @@ func (h *handler) UserLogin(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *handler) UserDummyLogin(w http.ResponseWriter, r *http.Request) {
-	reqID := r.Context().Value(middleware.ContextKeyRequestID).(string)
+	reqID := middleware.RequestID(r.Context())
 	params := r.URL.Query()
 	userType := middleware.Role(params.Get("user_type"))
 	if userType == middleware.Role("") {
@@ -131,20 +130,7 @@ func (h *handler) UserDummyLogin(w http.ResponseWriter, r *http.Request) {
 		apierror.Write(w, unknownUserTypeErr, reqID, http.StatusNotFound)
 		return
 	}
-	dummyEmailPrefix := h.s.GenerateRandomEmailPrefix(r.Context(), 20)
-	dummyUser := User{
-		Email:    dummyEmailPrefix + dummyEmailSuffix,
-		Password: dummyUserPassword,
-		Role:     string(userType),
-	}
-	dummyUserID, err := h.s.Register(r.Context(), dummyUser)
-	if err != nil {
-		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
-		apierror.Write(w, err, reqID, http.StatusInternalServerError)
-		return
-	}
-	token := dummyUser.GenerateToken()
-	err = h.s.AddToken(r.Context(), dummyUserID, token)
+	token, err := h.s.DummyLogin(r.Context(), string(userType))
 	if err != nil {
 		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
 		apierror.Write(w, err, reqID, http.StatusInternalServerError)
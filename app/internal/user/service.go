@@ -2,6 +2,8 @@ package user
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/rand"
 	"strings"
 	"time"
@@ -11,6 +13,11 @@ import (
 
 const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
+var (
+	ErrNotFound      = errors.New("user not found")
+	ErrWrongPassword = errors.New("wrong password")
+)
+
 type Service struct {
 	repo   Repository
 	logger logging.Logger
@@ -32,6 +39,41 @@ func (s *Service) AddToken(ctx context.Context, uid UserID, token Token) error {
 	return s.repo.AddToken(ctx, uid, token)
 }
 
+// Login checks the password and issues a new token.
+func (s *Service) Login(ctx context.Context, uid UserID, password string) (Token, error) {
+	storedUser, err := s.repo.GetByID(ctx, uid)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+	if err = storedUser.VerifyPassword(password); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrWrongPassword, err)
+	}
+	token := storedUser.GenerateToken()
+	if err = s.repo.AddToken(ctx, storedUser.ID, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// DummyLogin registers a throwaway user with the given role and issues a
+// token for it.
+func (s *Service) DummyLogin(ctx context.Context, role string) (Token, error) {
+	dummyUser := User{
+		Email:    s.GenerateRandomEmailPrefix(ctx, 20) + dummyEmailSuffix,
+		Password: dummyUserPassword,
+		Role:     role,
+	}
+	dummyUserID, err := s.Register(ctx, dummyUser)
+	if err != nil {
+		return "", err
+	}
+	token := dummyUser.GenerateToken()
+	if err = s.repo.AddToken(ctx, dummyUserID, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
 func (s *Service) GenerateRandomEmailPrefix(ctx context.Context, length int) string {
 	var builder strings.Builder
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
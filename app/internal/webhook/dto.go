@@ -0,0 +1,13 @@
+package webhook
+
+// WebhookDTO creates or replaces a webhook. An empty Secret is generated on
+// creation and kept on update; a missing Active means true on creation and
+// unchanged on update.
+type WebhookDTO struct {
+	URL      string   `json:"url" validate:"required,http_url,max=2048"`
+	Secret   string   `json:"secret" validate:"omitempty,min=16,max=128"`
+	Events   []string `json:"events" validate:"dive,oneof=flat.created flat.status_changed flat.price_changed"`
+	HouseIDs []int    `json:"house_ids" validate:"dive,min=1"`
+	Statuses []string `json:"statuses" validate:"dive,oneof=created approved declined 'on moderation'"`
+	Active   *bool    `json:"active"`
+}
@@ -0,0 +1,246 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/handlers"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+)
+
+const (
+	webhooksURL   = "/webhooks"
+	webhookURL    = "/webhooks/{id}"
+	deliveriesURL = "/webhooks/{id}/deliveries"
+	redeliverURL  = "/webhooks/{id}/deliveries/{delivery_id}/redeliver"
+	testURL       = "/webhooks/{id}/test"
+
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+type handler struct {
+	modmw middleware.Middleware
+	s     *Service
+	l     logging.Logger
+}
+
+func NewHandler(modmw middleware.Middleware, s *Service, l logging.Logger) handlers.Handler {
+	return &handler{modmw: modmw, s: s, l: l}
+}
+
+// Register mounts the webhook routes, all of them for moderators only.
+func (h *handler) Register(r *mux.Router) {
+	r.Handle(webhooksURL, h.modmw.DoInMiddle(http.HandlerFunc(h.Create))).Methods(http.MethodPost)
+	r.Handle(webhooksURL, h.modmw.DoInMiddle(http.HandlerFunc(h.List))).Methods(http.MethodGet)
+	r.Handle(webhookURL, h.modmw.DoInMiddle(http.HandlerFunc(h.Get))).Methods(http.MethodGet)
+	r.Handle(webhookURL, h.modmw.DoInMiddle(http.HandlerFunc(h.Update))).Methods(http.MethodPut)
+	r.Handle(webhookURL, h.modmw.DoInMiddle(http.HandlerFunc(h.Delete))).Methods(http.MethodDelete)
+	r.Handle(deliveriesURL, h.modmw.DoInMiddle(http.HandlerFunc(h.Deliveries))).Methods(http.MethodGet)
+	r.Handle(redeliverURL, h.modmw.DoInMiddle(http.HandlerFunc(h.Redeliver))).Methods(http.MethodPost)
+	r.Handle(testURL, h.modmw.DoInMiddle(http.HandlerFunc(h.SendTest))).Methods(http.MethodPost)
+}
+
+func (h *handler) Create(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestID(r.Context())
+	dto, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+	nw, err := h.s.Create(r.Context(), dto)
+	if err != nil {
+		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, r, nw)
+}
+
+func (h *handler) List(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestID(r.Context())
+	ws, err := h.s.List(r.Context())
+	if err != nil {
+		h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusInternalServerError)
+		return
+	}
+	h.respond(w, r, ws)
+}
+
+func (h *handler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.webhookID(w, r)
+	if !ok {
+		return
+	}
+	wh, err := h.s.Get(r.Context(), id)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.respond(w, r, wh)
+}
+
+func (h *handler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.webhookID(w, r)
+	if !ok {
+		return
+	}
+	dto, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+	wh, err := h.s.Update(r.Context(), id, dto)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.respond(w, r, wh)
+}
+
+func (h *handler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.webhookID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.s.Delete(r.Context(), id); err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries is the delivery log of a webhook, filtered by the status query
+// parameter; status=dead lists the dead letters.
+func (h *handler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestID(r.Context())
+	id, ok := h.webhookID(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	status := DeliveryStatus(q.Get("status"))
+	switch status {
+	case "", StatusPending, StatusDelivered, StatusDead:
+	default:
+		err := errors.New("status must be one of pending, delivered, dead")
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusBadRequest)
+		return
+	}
+	limit := defaultDeliveriesLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxDeliveriesLimit {
+			limitErr := errors.New("limit must be between 1 and " + strconv.Itoa(maxDeliveriesLimit))
+			h.l.Errorf("bad request req_id=%s: %v", reqID, limitErr)
+			apierror.Write(w, limitErr, reqID, http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	ds, err := h.s.Deliveries(r.Context(), id, status, limit)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	h.respond(w, r, ds)
+}
+
+func (h *handler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	reqID := middleware.RequestID(r.Context())
+	id, ok := h.webhookID(w, r)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseInt(mux.Vars(r)["delivery_id"], 10, 64)
+	if err != nil || deliveryID < 1 {
+		invalidIDErr := errors.New("invalid delivery id")
+		h.l.Errorf("bad request req_id=%s: %v", reqID, invalidIDErr)
+		apierror.Write(w, invalidIDErr, reqID, http.StatusBadRequest)
+		return
+	}
+	d, err := h.s.Redeliver(r.Context(), id, deliveryID)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	h.encode(w, r, d)
+}
+
+// SendTest queues a webhook.test event, its outcome shows up in the
+// delivery log.
+func (h *handler) SendTest(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.webhookID(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.s.SendTest(r.Context(), id)
+	if err != nil {
+		h.fail(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	h.encode(w, r, d)
+}
+
+func (h *handler) decode(w http.ResponseWriter, r *http.Request) (WebhookDTO, bool) {
+	reqID := middleware.RequestID(r.Context())
+	var dto WebhookDTO
+	code, err := handlers.DecodeJSON(r, &dto)
+	if err != nil {
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, code)
+		return WebhookDTO{}, false
+	}
+	validate := validator.New()
+	err = validate.Struct(dto)
+	if err != nil {
+		h.l.Errorf("bad request req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusBadRequest)
+		return WebhookDTO{}, false
+	}
+	return dto, true
+}
+
+func (h *handler) webhookID(w http.ResponseWriter, r *http.Request) (WebhookID, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil || id < 1 {
+		reqID := middleware.RequestID(r.Context())
+		invalidIDErr := errors.New("invalid webhook id")
+		h.l.Errorf("bad request req_id=%s: %v", reqID, invalidIDErr)
+		apierror.Write(w, invalidIDErr, reqID, http.StatusBadRequest)
+		return 0, false
+	}
+	return WebhookID(id), true
+}
+
+func (h *handler) fail(w http.ResponseWriter, r *http.Request, err error) {
+	reqID := middleware.RequestID(r.Context())
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrDeliveryNotFound) {
+		h.l.Errorf("not found req_id=%s: %v", reqID, err)
+		apierror.Write(w, err, reqID, http.StatusNotFound)
+		return
+	}
+	h.l.Errorf("internal error req_id=%s: %v", reqID, err)
+	apierror.Write(w, err, reqID, http.StatusInternalServerError)
+}
+
+func (h *handler) respond(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	h.encode(w, r, v)
+}
+
+func (h *handler) encode(w http.ResponseWriter, r *http.Request, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.l.Errorf("internal error req_id=%s: %v", middleware.RequestID(r.Context()), err)
+	}
+}
@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/flat"
+)
+
+type WebhookID int
+
+// Webhook is a partner endpoint notified about flat events. Empty Events,
+// HouseIDs and Statuses match everything.
+type Webhook struct {
+	ID        WebhookID `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events"`
+	HouseIDs  []int     `json:"house_ids"`
+	Statuses  []string  `json:"statuses"`
+	Active    bool      `json:"active"`
+	CreatedBy string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdateAt  time.Time `json:"update_at"`
+}
+
+// CreatedWebhook is returned once on creation, the only time the secret is
+// shown.
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// Matches reports whether e should be delivered to w.
+func (w Webhook) Matches(e flat.Event) bool {
+	if !w.Active {
+		return false
+	}
+	if len(w.Events) > 0 && !slices.Contains(w.Events, string(e.Type)) {
+		return false
+	}
+	if len(w.HouseIDs) > 0 && !slices.Contains(w.HouseIDs, e.Flat.HouseID) {
+		return false
+	}
+	return len(w.Statuses) == 0 || slices.Contains(w.Statuses, e.Flat.Status)
+}
+
+type DeliveryStatus string
+
+const (
+	StatusPending   DeliveryStatus = "pending"
+	StatusDelivered DeliveryStatus = "delivered"
+	// StatusDead marks a delivery that ran out of attempts.
+	StatusDead DeliveryStatus = "dead"
+)
+
+type Delivery struct {
+	ID            int64           `json:"id"`
+	WebhookID     WebhookID       `json:"webhook_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        DeliveryStatus  `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     string          `json:"last_error,omitempty"`
+	ResponseCode  int             `json:"response_code,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// Job is a claimed delivery together with where and how to send it.
+type Job struct {
+	Delivery
+	URL    string
+	Secret string
+}
+
+// Payload is the body POSTed to webhooks. ID is the same for every attempt
+// of a delivery, so receivers can drop duplicates.
+type Payload struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
@@ -0,0 +1,244 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Polyrom/houses_api/pkg/client/postgres"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	webhookColumns  = `id, url, secret, events, house_ids, statuses, active, created_at, update_at`
+	deliveryColumns = `id, webhook_id, event_type, payload, status, attempts, next_attempt_at, last_error, response_code, created_at, delivered_at`
+)
+
+type repository struct {
+	client postgres.Client
+	logger logging.Logger
+}
+
+func scanWebhook(row pgx.Row) (Webhook, error) {
+	var w Webhook
+	err := row.Scan(&w.ID, &w.URL, &w.Secret, &w.Events, &w.HouseIDs, &w.Statuses, &w.Active, &w.CreatedAt, &w.UpdateAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Webhook{}, ErrNotFound
+	}
+	return w, err
+}
+
+func scanDelivery(row pgx.Row) (Delivery, error) {
+	var d Delivery
+	err := row.Scan(&d.ID, &d.WebhookID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastError, &d.ResponseCode, &d.CreatedAt, &d.DeliveredAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Delivery{}, ErrNotFound
+	}
+	return d, err
+}
+
+func (r *repository) logError(err error) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		r.logger.Errorf("SQL Error: %s, Detail: %s, Where: %s", pgErr.Message, pgErr.Detail, pgErr.Where)
+	}
+}
+
+func (r *repository) Create(ctx context.Context, w Webhook) (Webhook, error) {
+	q := `INSERT INTO webhooks
+					(url, secret, events, house_ids, statuses, active, created_by)
+				VALUES
+					($1, $2, $3, $4, $5, $6, NULLIF($7, '')::uuid)
+				RETURNING ` + webhookColumns
+	nw, err := scanWebhook(r.client.QueryRow(ctx, q, w.URL, w.Secret, w.Events, w.HouseIDs, w.Statuses, w.Active, w.CreatedBy))
+	if err != nil {
+		r.logError(err)
+		return Webhook{}, err
+	}
+	return nw, nil
+}
+
+func (r *repository) Update(ctx context.Context, id WebhookID, w WebhookDTO) (Webhook, error) {
+	q := `UPDATE webhooks
+				SET
+					url = $2,
+					secret = COALESCE(NULLIF($3, ''), secret),
+					events = $4,
+					house_ids = $5,
+					statuses = $6,
+					active = COALESCE($7, active),
+					update_at = CURRENT_TIMESTAMP
+				WHERE id = $1
+				RETURNING ` + webhookColumns
+	nw, err := scanWebhook(r.client.QueryRow(ctx, q, id, w.URL, w.Secret, w.Events, w.HouseIDs, w.Statuses, w.Active))
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		r.logError(err)
+	}
+	return nw, err
+}
+
+func (r *repository) Delete(ctx context.Context, id WebhookID) error {
+	tag, err := r.client.Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *repository) Get(ctx context.Context, id WebhookID) (Webhook, error) {
+	q := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1`
+	return scanWebhook(r.client.QueryRow(ctx, q, id))
+}
+
+func (r *repository) List(ctx context.Context) ([]Webhook, error) {
+	q := `SELECT ` + webhookColumns + ` FROM webhooks ORDER BY id`
+	rows, err := r.client.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ws := make([]Webhook, 0)
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		ws = append(ws, w)
+	}
+	return ws, rows.Err()
+}
+
+func (r *repository) Enqueue(ctx context.Context, d Delivery) (Delivery, error) {
+	q := `INSERT INTO webhook_deliveries
+					(webhook_id, event_type, payload)
+				VALUES
+					($1, $2, $3)
+				RETURNING ` + deliveryColumns
+	nd, err := scanDelivery(r.client.QueryRow(ctx, q, d.WebhookID, d.EventType, d.Payload))
+	if err != nil {
+		r.logError(err)
+		return Delivery{}, err
+	}
+	return nd, nil
+}
+
+func (r *repository) Claim(ctx context.Context, limit int, leaseUntil time.Time) ([]Job, error) {
+	q := `UPDATE webhook_deliveries d
+				SET
+					next_attempt_at = $2
+				FROM webhooks w
+				WHERE w.id = d.webhook_id
+				AND d.id IN (
+					SELECT dd.id
+					FROM webhook_deliveries dd
+					JOIN webhooks ww ON ww.id = dd.webhook_id
+					WHERE dd.status = 'pending'
+					AND dd.next_attempt_at <= CURRENT_TIMESTAMP
+					AND ww.active
+					ORDER BY dd.next_attempt_at
+					LIMIT $1
+					FOR UPDATE OF dd SKIP LOCKED
+				)
+				RETURNING
+					d.id, d.webhook_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+					d.last_error, d.response_code, d.created_at, d.delivered_at, w.url, w.secret`
+	rows, err := r.client.Query(ctx, q, limit, leaseUntil)
+	if err != nil {
+		r.logError(err)
+		return nil, err
+	}
+	defer rows.Close()
+	jobs := make([]Job, 0)
+	for rows.Next() {
+		var j Job
+		err = rows.Scan(&j.ID, &j.WebhookID, &j.EventType, &j.Payload, &j.Status, &j.Attempts, &j.NextAttemptAt,
+			&j.LastError, &j.ResponseCode, &j.CreatedAt, &j.DeliveredAt, &j.URL, &j.Secret)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+func (r *repository) RecordAttempt(ctx context.Context, d Delivery) error {
+	q := `UPDATE webhook_deliveries
+				SET
+					status = $2,
+					attempts = $3,
+					next_attempt_at = $4,
+					last_error = $5,
+					response_code = $6,
+					delivered_at = $7
+				WHERE id = $1`
+	_, err := r.client.Exec(ctx, q, d.ID, d.Status, d.Attempts, d.NextAttemptAt, d.LastError, d.ResponseCode, d.DeliveredAt)
+	if err != nil {
+		r.logError(err)
+	}
+	return err
+}
+
+func (r *repository) Release(ctx context.Context, ids []int64) error {
+	q := `UPDATE webhook_deliveries
+				SET next_attempt_at = CURRENT_TIMESTAMP
+				WHERE id = ANY($1)
+				AND status = 'pending'`
+	_, err := r.client.Exec(ctx, q, ids)
+	if err != nil {
+		r.logError(err)
+	}
+	return err
+}
+
+func (r *repository) ListDeliveries(ctx context.Context, id WebhookID, status DeliveryStatus, limit int) ([]Delivery, error) {
+	q := `SELECT ` + deliveryColumns + `
+				FROM webhook_deliveries
+				WHERE webhook_id = $1
+				AND ($2::text = '' OR status = $2)
+				ORDER BY id DESC
+				LIMIT $3`
+	rows, err := r.client.Query(ctx, q, id, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ds := make([]Delivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+	return ds, rows.Err()
+}
+
+func (r *repository) Requeue(ctx context.Context, id WebhookID, deliveryID int64) (Delivery, error) {
+	q := `UPDATE webhook_deliveries
+				SET
+					status = 'pending',
+					attempts = 0,
+					next_attempt_at = CURRENT_TIMESTAMP
+				WHERE id = $2
+				AND webhook_id = $1
+				AND status <> 'pending'
+				RETURNING ` + deliveryColumns
+	d, err := scanDelivery(r.client.QueryRow(ctx, q, id, deliveryID))
+	if errors.Is(err, ErrNotFound) {
+		return Delivery{}, ErrDeliveryNotFound
+	}
+	return d, err
+}
+
+func NewRepository(c postgres.Client, l logging.Logger) Repository {
+	return &repository{
+		client: c,
+		logger: l,
+	}
+}
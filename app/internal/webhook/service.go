@@ -0,0 +1,375 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/config"
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/metrics"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/pkg/logging"
+	"github.com/google/uuid"
+)
+
+const (
+	// TestEventType is sent by SendTest.
+	TestEventType = "webhook.test"
+
+	secretBytes   = 24
+	secretPrefix  = "whsec_"
+	userAgent     = "houses-api-webhooks/1"
+	maxErrorBytes = 512
+	// leaseMargin is added to the attempt timeout so that a claimed delivery
+	// is not picked up by another worker while it is still being sent.
+	leaseMargin = 30 * time.Second
+)
+
+var (
+	ErrNotFound         = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("delivery not found or still pending")
+	ErrNotPublic        = errors.New("webhook address is not public")
+)
+
+// nonPublicPrefixes are the ranges not covered by the netip predicates:
+// "this network", carrier-grade NAT and benchmarking.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+type Service struct {
+	repo   Repository
+	cfg    config.WebhooksConfig
+	client *http.Client
+	wake   chan struct{}
+	logger logging.Logger
+}
+
+func (s *Service) Create(ctx context.Context, dto WebhookDTO) (CreatedWebhook, error) {
+	secret := dto.Secret
+	if secret == "" {
+		b := make([]byte, secretBytes)
+		if _, err := rand.Read(b); err != nil {
+			return CreatedWebhook{}, err
+		}
+		secret = secretPrefix + hex.EncodeToString(b)
+	}
+	createdBy, _ := middleware.CurrentUserID(ctx)
+	w := Webhook{
+		URL:       dto.URL,
+		Secret:    secret,
+		Events:    nonNil(dto.Events),
+		HouseIDs:  nonNil(dto.HouseIDs),
+		Statuses:  nonNil(dto.Statuses),
+		Active:    dto.Active == nil || *dto.Active,
+		CreatedBy: createdBy,
+	}
+	nw, err := s.repo.Create(ctx, w)
+	if err != nil {
+		return CreatedWebhook{}, err
+	}
+	return CreatedWebhook{Webhook: nw, Secret: nw.Secret}, nil
+}
+
+func (s *Service) Update(ctx context.Context, id WebhookID, dto WebhookDTO) (Webhook, error) {
+	dto.Events = nonNil(dto.Events)
+	dto.HouseIDs = nonNil(dto.HouseIDs)
+	dto.Statuses = nonNil(dto.Statuses)
+	return s.repo.Update(ctx, id, dto)
+}
+
+func (s *Service) Delete(ctx context.Context, id WebhookID) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *Service) Get(ctx context.Context, id WebhookID) (Webhook, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *Service) List(ctx context.Context) ([]Webhook, error) {
+	return s.repo.List(ctx)
+}
+
+// Deliveries returns the latest deliveries of a webhook, newest first,
+// optionally only those with the given status.
+func (s *Service) Deliveries(ctx context.Context, id WebhookID, status DeliveryStatus, limit int) ([]Delivery, error) {
+	if _, err := s.repo.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, id, status, limit)
+}
+
+// SendTest queues a test event for the webhook, even if it is inactive
+// (it is only sent once the webhook is activated).
+func (s *Service) SendTest(ctx context.Context, id WebhookID) (Delivery, error) {
+	if _, err := s.repo.Get(ctx, id); err != nil {
+		return Delivery{}, err
+	}
+	payload := Payload{
+		ID:        uuid.New().String(),
+		Type:      TestEventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      map[string]WebhookID{"webhook_id": id},
+	}
+	d, err := s.enqueue(ctx, id, payload)
+	if err != nil {
+		return Delivery{}, err
+	}
+	s.notifyWorker()
+	return d, nil
+}
+
+// Redeliver sends a delivered or dead-lettered delivery again.
+func (s *Service) Redeliver(ctx context.Context, id WebhookID, deliveryID int64) (Delivery, error) {
+	d, err := s.repo.Requeue(ctx, id, deliveryID)
+	if err != nil {
+		return Delivery{}, err
+	}
+	s.notifyWorker()
+	return d, nil
+}
+
+// OnFlatEvent queues a delivery for every webhook matching the event.
+// Failures are logged only, the change itself is already committed.
+func (s *Service) OnFlatEvent(ctx context.Context, e flat.Event) {
+	ctx = context.WithoutCancel(ctx)
+	ws, err := s.repo.List(ctx)
+	if err != nil {
+		s.logger.Errorf("list webhooks for %s: %v", e.Type, err)
+		return
+	}
+	payload := Payload{
+		ID:        uuid.New().String(),
+		Type:      string(e.Type),
+		CreatedAt: time.Now().UTC(),
+		Data:      e.Data(),
+	}
+	queued := false
+	for _, w := range ws {
+		if !w.Matches(e) {
+			continue
+		}
+		if _, err = s.enqueue(ctx, w.ID, payload); err != nil {
+			s.logger.Errorf("queue %s for webhook %d: %v", e.Type, w.ID, err)
+			continue
+		}
+		queued = true
+	}
+	if queued {
+		s.notifyWorker()
+	}
+}
+
+func (s *Service) enqueue(ctx context.Context, id WebhookID, p Payload) (Delivery, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return Delivery{}, err
+	}
+	return s.repo.Enqueue(ctx, Delivery{WebhookID: id, EventType: p.Type, Payload: body})
+}
+
+func (s *Service) notifyWorker() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run sends due deliveries until ctx is cancelled. It polls every
+// PollInterval and right after new deliveries are queued by this process.
+// Several instances may run against the same database.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := s.deliverDue(ctx)
+			if err != nil && ctx.Err() == nil {
+				s.logger.Errorf("deliver webhooks: %v", err)
+			}
+			if err != nil || n < s.cfg.BatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// deliverDue claims one batch of due deliveries, sends them concurrently and
+// records the outcomes. It returns the number of claimed deliveries.
+func (s *Service) deliverDue(ctx context.Context) (int, error) {
+	jobs, err := s.repo.Claim(ctx, s.cfg.BatchSize, time.Now().Add(s.cfg.Timeout+leaseMargin))
+	if err != nil {
+		return 0, err
+	}
+	var (
+		wg          sync.WaitGroup
+		mu          sync.Mutex
+		interrupted []int64
+	)
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j Job) {
+			defer wg.Done()
+			d := s.attempt(ctx, j)
+			// An attempt cut short by shutdown says nothing about the
+			// receiver, so it is not counted and the delivery is retried
+			// as soon as a worker runs again.
+			if ctx.Err() != nil && d.Status != StatusDelivered {
+				mu.Lock()
+				interrupted = append(interrupted, d.ID)
+				mu.Unlock()
+				return
+			}
+			if err := s.repo.RecordAttempt(context.WithoutCancel(ctx), d); err != nil {
+				s.logger.Errorf("record webhook delivery %d: %v", d.ID, err)
+			}
+		}(j)
+	}
+	wg.Wait()
+	if len(interrupted) > 0 {
+		if err := s.repo.Release(context.WithoutCancel(ctx), interrupted); err != nil {
+			s.logger.Errorf("release %d interrupted webhook deliveries: %v", len(interrupted), err)
+		}
+	}
+	return len(jobs), nil
+}
+
+// attempt sends the delivery once and returns it updated with the outcome.
+func (s *Service) attempt(ctx context.Context, j Job) Delivery {
+	d := j.Delivery
+	d.Attempts++
+	code, err := s.post(ctx, j)
+	d.ResponseCode = code
+	if err == nil {
+		now := time.Now()
+		d.Status = StatusDelivered
+		d.LastError = ""
+		d.DeliveredAt = &now
+		metrics.WebhookAttempts.Add(string(StatusDelivered), 1)
+		return d
+	}
+	d.LastError = err.Error()
+	if d.Attempts >= s.cfg.MaxAttempts {
+		d.Status = StatusDead
+		metrics.WebhookAttempts.Add(string(StatusDead), 1)
+		s.logger.Warnf("webhook %d delivery %d dead after %d attempts: %v", d.WebhookID, d.ID, d.Attempts, err)
+		return d
+	}
+	d.Status = StatusPending
+	d.NextAttemptAt = time.Now().Add(s.backoff(d.Attempts))
+	metrics.WebhookAttempts.Add("failed", 1)
+	return d
+}
+
+// post sends the signed payload. Any response but 2xx is an error, redirects
+// are not followed.
+func (s *Service) post(ctx context.Context, j Job) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, j.URL, bytes.NewReader(j.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(EventHeader, j.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(j.ID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(j.Secret, timestamp, j.Payload))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBytes))
+		return resp.StatusCode, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBytes))
+	return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (s *Service) backoff(attempts int) time.Duration {
+	d := s.cfg.BaseBackoff
+	for i := 1; i < attempts && d < s.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, s.cfg.MaxBackoff)
+}
+
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// publicOnly is the dialer Control hook refusing loopback, link-local,
+// private and other non-public addresses. It sees the address actually
+// dialed, so host names resolving to such addresses are refused as well.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return fmt.Errorf("%w: %s", ErrNotPublic, ip)
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(ip) {
+			return fmt.Errorf("%w: %s", ErrNotPublic, ip)
+		}
+	}
+	return nil
+}
+
+// newClient builds the delivery client. Redirects are not followed and no
+// proxy is used, so that control checks the receiver itself.
+func newClient(control func(network, address string, c syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func NewService(r Repository, cfg config.WebhooksConfig, l logging.Logger) *Service {
+	control := publicOnly
+	if cfg.AllowPrivate {
+		control = nil
+	}
+	return &Service{repo: r, cfg: cfg, client: newClient(control), wake: make(chan struct{}, 1), logger: l}
+}
@@ -0,0 +1,434 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/config"
+	"github.com/Polyrom/houses_api/internal/flat"
+	"github.com/Polyrom/houses_api/internal/modstatus"
+)
+
+type MockLogger struct{}
+
+func (ml *MockLogger) Trace(args ...interface{})                   {}
+func (ml *MockLogger) Debug(args ...interface{})                   {}
+func (ml *MockLogger) Info(args ...interface{})                    {}
+func (ml *MockLogger) Warn(args ...interface{})                    {}
+func (ml *MockLogger) Warning(args ...interface{})                 {}
+func (ml *MockLogger) Error(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   {}
+func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
+func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
+func (ml *MockLogger) Infof(format string, args ...interface{})    {}
+func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
+func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
+func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
+func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
+
+type MockRepo struct {
+	mu         sync.Mutex
+	webhooks   []Webhook
+	deliveries []Delivery
+}
+
+func (mr *MockRepo) Create(ctx context.Context, w Webhook) (Webhook, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	w.ID = WebhookID(len(mr.webhooks) + 1)
+	mr.webhooks = append(mr.webhooks, w)
+	return w, nil
+}
+
+func (mr *MockRepo) Update(ctx context.Context, id WebhookID, w WebhookDTO) (Webhook, error) {
+	return Webhook{}, ErrNotFound
+}
+
+func (mr *MockRepo) Delete(ctx context.Context, id WebhookID) error {
+	return ErrNotFound
+}
+
+func (mr *MockRepo) Get(ctx context.Context, id WebhookID) (Webhook, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	for _, w := range mr.webhooks {
+		if w.ID == id {
+			return w, nil
+		}
+	}
+	return Webhook{}, ErrNotFound
+}
+
+func (mr *MockRepo) List(ctx context.Context) ([]Webhook, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	return append([]Webhook(nil), mr.webhooks...), nil
+}
+
+func (mr *MockRepo) Enqueue(ctx context.Context, d Delivery) (Delivery, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	d.ID = int64(len(mr.deliveries) + 1)
+	d.Status = StatusPending
+	d.NextAttemptAt = time.Now()
+	mr.deliveries = append(mr.deliveries, d)
+	return d, nil
+}
+
+func (mr *MockRepo) Claim(ctx context.Context, limit int, leaseUntil time.Time) ([]Job, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	jobs := make([]Job, 0)
+	for i, d := range mr.deliveries {
+		w := mr.webhooks[d.WebhookID-1]
+		if len(jobs) == limit || d.Status != StatusPending || d.NextAttemptAt.After(time.Now()) || !w.Active {
+			continue
+		}
+		mr.deliveries[i].NextAttemptAt = leaseUntil
+		jobs = append(jobs, Job{Delivery: d, URL: w.URL, Secret: w.Secret})
+	}
+	return jobs, nil
+}
+
+func (mr *MockRepo) RecordAttempt(ctx context.Context, d Delivery) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	mr.deliveries[d.ID-1] = d
+	return nil
+}
+
+func (mr *MockRepo) Release(ctx context.Context, ids []int64) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	for _, id := range ids {
+		if mr.deliveries[id-1].Status == StatusPending {
+			mr.deliveries[id-1].NextAttemptAt = time.Now()
+		}
+	}
+	return nil
+}
+
+func (mr *MockRepo) ListDeliveries(ctx context.Context, id WebhookID, status DeliveryStatus, limit int) ([]Delivery, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	return append([]Delivery(nil), mr.deliveries...), nil
+}
+
+func (mr *MockRepo) Requeue(ctx context.Context, id WebhookID, deliveryID int64) (Delivery, error) {
+	return Delivery{}, ErrDeliveryNotFound
+}
+
+// makeDue skips the backoff of pending deliveries.
+func (mr *MockRepo) makeDue() {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	for i := range mr.deliveries {
+		mr.deliveries[i].NextAttemptAt = time.Now()
+	}
+}
+
+func (mr *MockRepo) delivery(id int64) Delivery {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	return mr.deliveries[id-1]
+}
+
+var testConfig = config.WebhooksConfig{
+	Timeout:      time.Second,
+	MaxAttempts:  3,
+	BaseBackoff:  time.Second,
+	MaxBackoff:   4 * time.Second,
+	PollInterval: time.Second,
+	BatchSize:    10,
+	// The test receivers listen on loopback.
+	AllowPrivate: true,
+}
+
+func newTestService(t *testing.T, receiver http.HandlerFunc, dto WebhookDTO) (*Service, *MockRepo, CreatedWebhook) {
+	t.Helper()
+	srv := httptest.NewServer(receiver)
+	t.Cleanup(srv.Close)
+	repo := &MockRepo{}
+	s := NewService(repo, testConfig, &MockLogger{})
+	dto.URL = srv.URL
+	wh, err := s.Create(context.Background(), dto)
+	if err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	return s, repo, wh
+}
+
+func approvedFlat(houseID int) flat.Event {
+	return flat.Event{
+		Type:       flat.EventStatusChanged,
+		Flat:       flat.FlatDTO{ID: 7, HouseID: houseID, Price: 100, Rooms: 2, Status: modstatus.Approved.String()},
+		PrevStatus: modstatus.OnModeration.String(),
+	}
+}
+
+func TestDeliver_SignedPayload(t *testing.T) {
+	var received atomic.Pointer[http.Request]
+	var body []byte
+	s, repo, wh := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		received.Store(r)
+		w.WriteHeader(http.StatusNoContent)
+	}, WebhookDTO{})
+
+	s.OnFlatEvent(context.Background(), approvedFlat(1))
+	if n, err := s.deliverDue(context.Background()); n != 1 || err != nil {
+		t.Fatalf("deliverDue = %d, %v, want 1 delivery", n, err)
+	}
+	r := received.Load()
+	if r == nil {
+		t.Fatal("receiver was not called")
+	}
+	if err := Verify(wh.Secret, r.Header, body, time.Minute); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+	if got := r.Header.Get(EventHeader); got != string(flat.EventStatusChanged) {
+		t.Errorf("%s = %q", EventHeader, got)
+	}
+	var p struct {
+		ID   string         `json:"id"`
+		Type string         `json:"type"`
+		Data flat.EventData `json:"data"`
+	}
+	if err := json.Unmarshal(body, &p); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if p.ID == "" || p.Data.ID != 7 || p.Data.PrevStatus != modstatus.OnModeration.String() {
+		t.Errorf("unexpected payload %s", body)
+	}
+	d := repo.delivery(1)
+	if d.Status != StatusDelivered || d.Attempts != 1 || d.ResponseCode != http.StatusNoContent || d.DeliveredAt == nil {
+		t.Errorf("delivery = %+v, want delivered after 1 attempt", d)
+	}
+}
+
+func TestDeliver_RetriesThenDeadLetters(t *testing.T) {
+	var calls atomic.Int32
+	s, repo, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "partner is down", http.StatusBadGateway)
+	}, WebhookDTO{})
+
+	s.OnFlatEvent(context.Background(), approvedFlat(1))
+	for attempt := 1; attempt <= testConfig.MaxAttempts; attempt++ {
+		before := time.Now()
+		if n, _ := s.deliverDue(context.Background()); n != 1 {
+			t.Fatalf("attempt %d: claimed %d deliveries, want 1", attempt, n)
+		}
+		d := repo.delivery(1)
+		if d.Attempts != attempt || d.ResponseCode != http.StatusBadGateway || d.LastError == "" {
+			t.Fatalf("attempt %d: delivery = %+v", attempt, d)
+		}
+		if attempt < testConfig.MaxAttempts {
+			wait := d.NextAttemptAt.Sub(before)
+			if d.Status != StatusPending || wait < s.backoff(attempt) || wait > s.backoff(attempt)+time.Second {
+				t.Fatalf("attempt %d: status %s, next attempt in %v, want pending in %v", attempt, d.Status, wait, s.backoff(attempt))
+			}
+			if n, _ := s.deliverDue(context.Background()); n != 0 {
+				t.Fatalf("attempt %d: delivery retried before its backoff", attempt)
+			}
+			repo.makeDue()
+		} else if d.Status != StatusDead {
+			t.Fatalf("status after %d attempts = %s, want %s", attempt, d.Status, StatusDead)
+		}
+	}
+	repo.makeDue()
+	if n, _ := s.deliverDue(context.Background()); n != 0 {
+		t.Errorf("dead delivery was claimed again")
+	}
+	if got := calls.Load(); got != int32(testConfig.MaxAttempts) {
+		t.Errorf("receiver called %d times, want %d", got, testConfig.MaxAttempts)
+	}
+}
+
+func TestDeliver_RedirectIsFailure(t *testing.T) {
+	s, repo, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	}, WebhookDTO{})
+	s.OnFlatEvent(context.Background(), approvedFlat(1))
+	s.deliverDue(context.Background())
+	if d := repo.delivery(1); d.Status != StatusPending || d.ResponseCode != http.StatusFound {
+		t.Errorf("delivery = %+v, want pending with 302", d)
+	}
+}
+
+func TestDeliver_InterruptedNotRecorded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s, repo, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		cancel()
+		<-r.Context().Done()
+	}, WebhookDTO{})
+	s.OnFlatEvent(context.Background(), approvedFlat(1))
+	if n, _ := s.deliverDue(ctx); n != 1 {
+		t.Fatalf("claimed %d deliveries, want 1", n)
+	}
+	d := repo.delivery(1)
+	if d.Status != StatusPending || d.Attempts != 0 || d.LastError != "" || d.NextAttemptAt.After(time.Now()) {
+		t.Errorf("delivery = %+v, want pending, unattempted and due", d)
+	}
+}
+
+func TestPublicOnly(t *testing.T) {
+	tests := []struct {
+		address string
+		public  bool
+	}{
+		{address: "93.184.216.34:443", public: true},
+		{address: "[2606:2800:220:1::1]:443", public: true},
+		{address: "127.0.0.1:80"},
+		{address: "[::1]:80"},
+		{address: "10.0.0.5:80"},
+		{address: "172.16.1.1:80"},
+		{address: "192.168.1.1:80"},
+		{address: "169.254.169.254:80"},
+		{address: "[fe80::1]:80"},
+		{address: "[fd00::1]:80"},
+		{address: "[::ffff:127.0.0.1]:80"},
+		{address: "0.0.0.0:80"},
+		{address: "100.64.0.1:80"},
+	}
+	for _, tt := range tests {
+		err := publicOnly("tcp", tt.address, nil)
+		if got := err == nil; got != tt.public {
+			t.Errorf("publicOnly(%s) = %v, want public %v", tt.address, err, tt.public)
+		}
+		if err != nil && !errors.Is(err, ErrNotPublic) {
+			t.Errorf("publicOnly(%s) = %v, want %v", tt.address, err, ErrNotPublic)
+		}
+	}
+}
+
+func TestDeliver_LoopbackRefused(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+	repo := &MockRepo{}
+	cfg := testConfig
+	cfg.AllowPrivate = false
+	s := NewService(repo, cfg, &MockLogger{})
+	if _, err := s.Create(context.Background(), WebhookDTO{URL: srv.URL}); err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	s.OnFlatEvent(context.Background(), approvedFlat(1))
+	s.deliverDue(context.Background())
+	if d := repo.delivery(1); d.Status != StatusPending || !strings.Contains(d.LastError, ErrNotPublic.Error()) || calls.Load() != 0 {
+		t.Errorf("delivery = %+v, receiver called %d times; want refused", d, calls.Load())
+	}
+}
+
+func TestOnFlatEvent_Filters(t *testing.T) {
+	inactive := false
+	tests := []struct {
+		name  string
+		dto   WebhookDTO
+		event flat.Event
+		want  int
+	}{
+		{name: "no filters", dto: WebhookDTO{}, event: approvedFlat(1), want: 1},
+		{name: "managed house", dto: WebhookDTO{HouseIDs: []int{1, 2}}, event: approvedFlat(2), want: 1},
+		{name: "other house", dto: WebhookDTO{HouseIDs: []int{1}}, event: approvedFlat(3), want: 0},
+		{name: "approved only", dto: WebhookDTO{Statuses: []string{"approved"}}, event: approvedFlat(1), want: 1},
+		{name: "declined only", dto: WebhookDTO{Statuses: []string{"declined"}}, event: approvedFlat(1), want: 0},
+		{name: "created events only", dto: WebhookDTO{Events: []string{string(flat.EventCreated)}}, event: approvedFlat(1), want: 0},
+		{name: "inactive", dto: WebhookDTO{Active: &inactive}, event: approvedFlat(1), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {}, tt.dto)
+			s.OnFlatEvent(context.Background(), tt.event)
+			ds, _ := repo.ListDeliveries(context.Background(), 1, "", 10)
+			if len(ds) != tt.want {
+				t.Errorf("queued %d deliveries, want %d", len(ds), tt.want)
+			}
+		})
+	}
+}
+
+func TestSendTest(t *testing.T) {
+	received := make(chan string, 1)
+	s, _, _ := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get(EventHeader)
+	}, WebhookDTO{Secret: "0123456789abcdef"})
+	if _, err := s.SendTest(context.Background(), 2); err != ErrNotFound {
+		t.Errorf("SendTest of a missing webhook: %v, want %v", err, ErrNotFound)
+	}
+	if _, err := s.SendTest(context.Background(), 1); err != nil {
+		t.Fatalf("SendTest: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	select {
+	case got := <-received:
+		if got != TestEventType {
+			t.Errorf("event = %q, want %q", got, TestEventType)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("test event was not delivered")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	s := NewService(&MockRepo{}, testConfig, &MockLogger{})
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second}
+	for i, w := range want {
+		if got := s.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"type":"webhook.test"}`)
+	now := time.Now().Unix()
+	header := func(secret string, ts int64, b []byte) http.Header {
+		h := http.Header{}
+		h.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+		h.Set(SignatureHeader, Sign(secret, ts, b))
+		return h
+	}
+	tests := []struct {
+		name string
+		h    http.Header
+		ok   bool
+	}{
+		{name: "valid", h: header("secret", now, body), ok: true},
+		{name: "wrong secret", h: header("other", now, body)},
+		{name: "tampered body", h: header("secret", now, []byte(`{}`))},
+		{name: "stale", h: header("secret", now-3600, body)},
+		{name: "no timestamp", h: http.Header{SignatureHeader: {Sign("secret", now, body)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify("secret", tt.h, body, 5*time.Minute)
+			if (err == nil) != tt.ok {
+				t.Errorf("Verify() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, w Webhook) (Webhook, error)
+	// Update replaces the settings of a webhook, keeping the secret and
+	// the active flag when they are not given.
+	Update(ctx context.Context, id WebhookID, w WebhookDTO) (Webhook, error)
+	Delete(ctx context.Context, id WebhookID) error
+	Get(ctx context.Context, id WebhookID) (Webhook, error)
+	List(ctx context.Context) ([]Webhook, error)
+	Enqueue(ctx context.Context, d Delivery) (Delivery, error)
+	// Claim leases up to limit due deliveries of active webhooks until
+	// leaseUntil, so that other workers skip them meanwhile.
+	Claim(ctx context.Context, limit int, leaseUntil time.Time) ([]Job, error)
+	// RecordAttempt stores the outcome of an attempt: status, attempts,
+	// next attempt time, last error and response code.
+	RecordAttempt(ctx context.Context, d Delivery) error
+	// Release ends the lease of pending deliveries, making them due now.
+	Release(ctx context.Context, ids []int64) error
+	ListDeliveries(ctx context.Context, id WebhookID, status DeliveryStatus, limit int) ([]Delivery, error)
+	// Requeue makes a delivery of the webhook due now with a fresh attempt
+	// budget.
+	Requeue(ctx context.Context, id WebhookID, deliveryID int64) (Delivery, error)
+}
@@ -0,0 +1,8 @@
+-- drop trigger and function keeping houses update_at in sync
+DROP TRIGGER IF EXISTS update_house_updated_at_trigger ON flats;
+DROP FUNCTION IF EXISTS update_house_updated_at();
+-- drop tables in reverse dependency order
+DROP TABLE IF EXISTS flats;
+DROP TABLE IF EXISTS houses;
+DROP TABLE IF EXISTS tokens;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,19 @@
+-- create house subscriptions table
+CREATE TABLE IF NOT EXISTS house_subscriptions (
+  house_id INTEGER NOT NULL REFERENCES houses(id) ON DELETE CASCADE,
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (house_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS house_subscriptions_user_id_idx ON house_subscriptions (user_id);
+-- create events table, the log replayed to streams resumed with Last-Event-ID
+CREATE TABLE IF NOT EXISTS events (
+  id BIGSERIAL PRIMARY KEY,
+  type VARCHAR(50) NOT NULL,
+  house_id INTEGER NOT NULL,
+  flat_id INTEGER NOT NULL,
+  status VARCHAR(50) NOT NULL,
+  data JSONB NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS events_created_at_idx ON events (created_at);
@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS set_flat_number_trigger ON flats;
+DROP FUNCTION IF EXISTS set_flat_number();
+ALTER TABLE flats DROP CONSTRAINT IF EXISTS flats_house_id_number_key,
+  DROP COLUMN IF EXISTS number,
+  DROP COLUMN IF EXISTS floor,
+  DROP COLUMN IF EXISTS total_area,
+  DROP COLUMN IF EXISTS living_area,
+  DROP COLUMN IF EXISTS kitchen_area,
+  DROP COLUMN IF EXISTS balcony,
+  DROP COLUMN IF EXISTS layout;
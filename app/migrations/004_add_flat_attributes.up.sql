@@ -0,0 +1,43 @@
+-- add flat attributes, everything but the number is optional for flats created before
+ALTER TABLE flats
+  ADD COLUMN number INTEGER CHECK (number >= 1),
+  ADD COLUMN floor INTEGER,
+  ADD COLUMN total_area NUMERIC(7, 2) CHECK (total_area > 0),
+  ADD COLUMN living_area NUMERIC(7, 2) CHECK (living_area > 0),
+  ADD COLUMN kitchen_area NUMERIC(7, 2) CHECK (kitchen_area > 0),
+  ADD COLUMN balcony BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN layout VARCHAR(50) CHECK(
+    layout IN ('studio', 'isolated', 'adjacent', 'open_plan')
+  );
+-- number existing flats in the order they were created
+UPDATE flats
+SET number = numbered.n
+FROM (
+    SELECT id,
+      ROW_NUMBER() OVER (
+        PARTITION BY house_id
+        ORDER BY id
+      ) AS n
+    FROM flats
+  ) AS numbered
+WHERE flats.id = numbered.id;
+ALTER TABLE flats
+ALTER COLUMN number
+SET NOT NULL,
+  ADD CONSTRAINT flats_house_id_number_key UNIQUE (house_id, number);
+-- create function giving a flat inserted without a number the next free one in its house,
+-- the house row is locked so that concurrent inserts do not pick the same number
+CREATE OR REPLACE FUNCTION set_flat_number() RETURNS TRIGGER AS $$ BEGIN IF NEW.number IS NULL THEN PERFORM 1
+FROM houses
+WHERE id = NEW.house_id FOR
+UPDATE;
+SELECT COALESCE(MAX(number), 0) + 1 INTO NEW.number
+FROM flats
+WHERE house_id = NEW.house_id;
+END IF;
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- attach the trigger to the flats table
+CREATE TRIGGER set_flat_number_trigger BEFORE
+INSERT ON flats FOR EACH ROW EXECUTE PROCEDURE set_flat_number();
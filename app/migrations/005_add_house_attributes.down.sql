@@ -0,0 +1,12 @@
+-- the extensions are left installed, other database objects may use them
+DROP INDEX IF EXISTS houses_earth_idx;
+DROP INDEX IF EXISTS houses_location_idx;
+ALTER TABLE houses DROP CONSTRAINT IF EXISTS houses_location_check,
+  DROP COLUMN IF EXISTS floors,
+  DROP COLUMN IF EXISTS material,
+  DROP COLUMN IF EXISTS latitude,
+  DROP COLUMN IF EXISTS longitude,
+  DROP COLUMN IF EXISTS city,
+  DROP COLUMN IF EXISTS district,
+  DROP COLUMN IF EXISTS parking,
+  DROP COLUMN IF EXISTS elevator;
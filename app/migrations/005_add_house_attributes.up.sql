@@ -0,0 +1,34 @@
+-- add house attributes, all of them are optional for houses created before
+ALTER TABLE houses
+  ADD COLUMN floors INTEGER CHECK (floors >= 1),
+  ADD COLUMN material VARCHAR(50) CHECK(
+    material IN (
+      'brick',
+      'panel',
+      'monolith',
+      'monolith_brick',
+      'block',
+      'wood'
+    )
+  ),
+  ADD COLUMN latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+  ADD COLUMN longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+  ADD COLUMN city VARCHAR(200),
+  ADD COLUMN district VARCHAR(200),
+  ADD COLUMN parking BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN elevator BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD CONSTRAINT houses_location_check CHECK ((latitude IS NULL) = (longitude IS NULL));
+-- index for the bounding box of the haversine search
+CREATE INDEX IF NOT EXISTS houses_location_idx ON houses (latitude, longitude)
+WHERE latitude IS NOT NULL;
+-- use earthdistance for the nearby search where it can be installed, the
+-- application checks for the extension and falls back to haversine otherwise
+DO $$ BEGIN CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
+CREATE INDEX IF NOT EXISTS houses_earth_idx ON houses USING gist (ll_to_earth(latitude, longitude))
+WHERE latitude IS NOT NULL;
+EXCEPTION
+WHEN OTHERS THEN RAISE NOTICE 'earthdistance is not available: %',
+SQLERRM;
+END;
+$$;
@@ -0,0 +1,9 @@
+ALTER TABLE houses
+ADD COLUMN developer VARCHAR(200);
+UPDATE houses
+SET developer = developers.name
+FROM developers
+WHERE developers.id = houses.developer_id;
+ALTER TABLE houses DROP COLUMN developer_id;
+DROP TABLE IF EXISTS developers;
+DROP FUNCTION IF EXISTS developer_key(TEXT);
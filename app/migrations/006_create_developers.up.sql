@@ -0,0 +1,58 @@
+-- create function deriving the key that tells spellings of one developer apart from other developers:
+-- lower case, Cyrillic transliterated, quotes, punctuation and legal forms dropped, so that
+-- 'PIK', 'ПИК', 'ГК "ПИК"' and 'pik group' share the key 'pik'
+CREATE OR REPLACE FUNCTION developer_key(name TEXT) RETURNS TEXT AS $$
+SELECT COALESCE(
+    NULLIF(
+      btrim(
+        regexp_replace(
+          regexp_replace(
+            translate(
+              replace(replace(replace(replace(replace(replace(replace(lower(name), 'щ', 'sch'), 'ж', 'zh'), 'ч', 'ch'), 'ш', 'sh'), 'ю', 'yu'), 'я', 'ya'), 'х', 'kh'),
+              'абвгдеёзийклмнопрстуфцыэьъ',
+              'abvgdeeziyklmnoprstufcye'
+            ),
+            '[^[:alnum:]]+|\m(ooo|oao|zao|pao|ao|gk|group|gruppa|grupp|kompaniya|llc|inc|ltd)\M',
+            ' ',
+            'g'
+          ),
+          '\s+',
+          ' ',
+          'g'
+        )
+      ),
+      ''
+    ),
+    lower(btrim(name))
+  ) $$ LANGUAGE SQL IMMUTABLE;
+-- create developers table
+CREATE TABLE IF NOT EXISTS developers (
+  id SERIAL PRIMARY KEY CHECK (id >= 1),
+  name VARCHAR(200) NOT NULL CHECK (btrim(name) <> ''),
+  key TEXT GENERATED ALWAYS AS (developer_key(name)) STORED,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  update_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT developers_key_key UNIQUE (key)
+);
+-- move the developers of existing houses into the table, the most frequent spelling names the developer
+INSERT INTO developers (name)
+SELECT DISTINCT ON (developer_key(name)) name
+FROM (
+    SELECT btrim(developer) AS name,
+      COUNT(*) AS houses
+    FROM houses
+    WHERE btrim(COALESCE(developer, '')) <> ''
+    GROUP BY btrim(developer)
+  ) AS spellings
+ORDER BY developer_key(name),
+  houses DESC,
+  name;
+ALTER TABLE houses
+ADD COLUMN developer_id INTEGER REFERENCES developers(id);
+UPDATE houses
+SET developer_id = developers.id
+FROM developers
+WHERE btrim(COALESCE(houses.developer, '')) <> ''
+  AND developers.key = developer_key(btrim(houses.developer));
+CREATE INDEX IF NOT EXISTS houses_developer_id_idx ON houses (developer_id);
+ALTER TABLE houses DROP COLUMN developer;
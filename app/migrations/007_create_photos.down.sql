@@ -0,0 +1,3 @@
+-- files of the photos are left in the blob storage
+DROP TABLE IF EXISTS photos;
+ALTER TABLE flats DROP COLUMN IF EXISTS owner_id;
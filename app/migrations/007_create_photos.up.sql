@@ -0,0 +1,24 @@
+-- the user who created a flat owns it and sees its photos before approval
+ALTER TABLE flats
+ADD COLUMN owner_id UUID REFERENCES users(id);
+-- create photos table, a photo belongs to exactly one house or flat
+CREATE TABLE IF NOT EXISTS photos (
+  id SERIAL PRIMARY KEY CHECK (id >= 1),
+  house_id INTEGER REFERENCES houses(id) ON DELETE CASCADE,
+  flat_id INTEGER REFERENCES flats(id) ON DELETE CASCADE,
+  kind VARCHAR(20) NOT NULL CHECK (kind IN ('photo', 'floor_plan')),
+  content_type VARCHAR(50) NOT NULL,
+  width INTEGER NOT NULL CHECK (width >= 1),
+  height INTEGER NOT NULL CHECK (height >= 1),
+  size BIGINT NOT NULL,
+  thumbnail_size BIGINT NOT NULL,
+  key VARCHAR(200) NOT NULL UNIQUE,
+  thumbnail_key VARCHAR(200) NOT NULL UNIQUE,
+  created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT photos_target_check CHECK (num_nonnulls(house_id, flat_id) = 1)
+);
+CREATE INDEX IF NOT EXISTS photos_house_id_idx ON photos (house_id)
+WHERE house_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS photos_flat_id_idx ON photos (flat_id)
+WHERE flat_id IS NOT NULL;
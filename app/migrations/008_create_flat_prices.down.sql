@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS flat_prices;
+ALTER TABLE flats DROP COLUMN IF EXISTS prev_price;
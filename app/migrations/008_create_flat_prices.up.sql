@@ -0,0 +1,13 @@
+-- the price before the last change, listings flag flats that got cheaper
+ALTER TABLE flats
+ADD COLUMN prev_price INTEGER;
+-- create flat price history table, one row per price change
+CREATE TABLE IF NOT EXISTS flat_prices (
+  id BIGSERIAL PRIMARY KEY,
+  flat_id INTEGER NOT NULL REFERENCES flats(id) ON DELETE CASCADE,
+  old_price INTEGER NOT NULL,
+  price INTEGER NOT NULL,
+  changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS flat_prices_flat_id_idx ON flat_prices (flat_id, id);
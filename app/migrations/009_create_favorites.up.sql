@@ -0,0 +1,8 @@
+-- create favorites table, a user bookmarks a flat at most once
+CREATE TABLE IF NOT EXISTS favorites (
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  flat_id INTEGER NOT NULL REFERENCES flats(id) ON DELETE CASCADE,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (user_id, flat_id)
+);
+CREATE INDEX IF NOT EXISTS favorites_flat_id_idx ON favorites (flat_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS saved_searches;
+DROP TRIGGER IF EXISTS set_flat_approved_at_trigger ON flats;
+DROP FUNCTION IF EXISTS set_flat_approved_at();
+ALTER TABLE flats DROP COLUMN IF EXISTS approved_at;
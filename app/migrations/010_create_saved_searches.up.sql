@@ -0,0 +1,39 @@
+-- the time a flat was first approved, saved searches alert about flats approved since their last check
+ALTER TABLE flats
+ADD COLUMN approved_at TIMESTAMPTZ;
+UPDATE flats
+SET approved_at = CURRENT_TIMESTAMP
+WHERE status = 'approved';
+CREATE INDEX IF NOT EXISTS flats_approved_at_idx ON flats (approved_at)
+WHERE approved_at IS NOT NULL;
+-- create function setting approved_at when a flat is approved for the first time
+CREATE OR REPLACE FUNCTION set_flat_approved_at() RETURNS TRIGGER AS $$ BEGIN IF NEW.status = 'approved'
+AND NEW.approved_at IS NULL THEN NEW.approved_at = CURRENT_TIMESTAMP;
+END IF;
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- attach the trigger to the flats table
+CREATE TRIGGER set_flat_approved_at_trigger BEFORE
+INSERT
+  OR
+UPDATE OF status ON flats FOR EACH ROW EXECUTE PROCEDURE set_flat_approved_at();
+-- create saved searches table, checked_at is the approval time up to which flats were matched;
+-- the times are TIMESTAMPTZ as the worker compares them with its own clock
+CREATE TABLE IF NOT EXISTS saved_searches (
+  id SERIAL PRIMARY KEY CHECK (id >= 1),
+  user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  house_id INTEGER REFERENCES houses(id) ON DELETE CASCADE,
+  city VARCHAR(100),
+  district VARCHAR(100),
+  rooms INTEGER CHECK (rooms >= 1),
+  price_min INTEGER CHECK (price_min >= 0),
+  price_max INTEGER CHECK (price_max >= 0),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  update_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  checked_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  locked_until TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS saved_searches_user_id_idx ON saved_searches (user_id);
+CREATE INDEX IF NOT EXISTS saved_searches_checked_at_idx ON saved_searches (checked_at);
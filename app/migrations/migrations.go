@@ -0,0 +1,9 @@
+package migrations
+
+import "embed"
+
+// FS holds the SQL migrations compiled into the binary. Files follow the
+// golang-migrate naming scheme: <version>_<name>.(up|down).sql.
+//
+//go:embed *.sql
+var FS embed.FS
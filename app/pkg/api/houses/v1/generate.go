@@ -0,0 +1,5 @@
+// Package housesv1 holds the generated gRPC API. Regenerate it with
+// go generate, which needs protoc, protoc-gen-go and protoc-gen-go-grpc in PATH.
+package housesv1
+
+//go:generate protoc -I ../../../../api/proto --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative houses/v1/houses.proto
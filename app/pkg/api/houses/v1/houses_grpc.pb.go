@@ -0,0 +1,477 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: houses/v1/houses.proto
+
+package housesv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Users_Register_FullMethodName   = "/houses.v1.Users/Register"
+	Users_Login_FullMethodName      = "/houses.v1.Users/Login"
+	Users_DummyLogin_FullMethodName = "/houses.v1.Users/DummyLogin"
+)
+
+// UsersClient is the client API for Users service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type UsersClient interface {
+	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
+	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*TokenResponse, error)
+	DummyLogin(ctx context.Context, in *DummyLoginRequest, opts ...grpc.CallOption) (*TokenResponse, error)
+}
+
+type usersClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUsersClient(cc grpc.ClientConnInterface) UsersClient {
+	return &usersClient{cc}
+}
+
+func (c *usersClient) Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterResponse)
+	err := c.cc.Invoke(ctx, Users_Register_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*TokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TokenResponse)
+	err := c.cc.Invoke(ctx, Users_Login_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) DummyLogin(ctx context.Context, in *DummyLoginRequest, opts ...grpc.CallOption) (*TokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TokenResponse)
+	err := c.cc.Invoke(ctx, Users_DummyLogin_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UsersServer is the server API for Users service.
+// All implementations must embed UnimplementedUsersServer
+// for forward compatibility.
+type UsersServer interface {
+	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
+	Login(context.Context, *LoginRequest) (*TokenResponse, error)
+	DummyLogin(context.Context, *DummyLoginRequest) (*TokenResponse, error)
+	mustEmbedUnimplementedUsersServer()
+}
+
+// UnimplementedUsersServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedUsersServer struct{}
+
+func (UnimplementedUsersServer) Register(context.Context, *RegisterRequest) (*RegisterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (UnimplementedUsersServer) Login(context.Context, *LoginRequest) (*TokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
+}
+func (UnimplementedUsersServer) DummyLogin(context.Context, *DummyLoginRequest) (*TokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DummyLogin not implemented")
+}
+func (UnimplementedUsersServer) mustEmbedUnimplementedUsersServer() {}
+func (UnimplementedUsersServer) testEmbeddedByValue()               {}
+
+// UnsafeUsersServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UsersServer will
+// result in compilation errors.
+type UnsafeUsersServer interface {
+	mustEmbedUnimplementedUsersServer()
+}
+
+func RegisterUsersServer(s grpc.ServiceRegistrar, srv UsersServer) {
+	// If the following call pancis, it indicates UnimplementedUsersServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Users_ServiceDesc, srv)
+}
+
+func _Users_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_Register_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).Register(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_Login_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).Login(ctx, req.(*LoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_DummyLogin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DummyLoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).DummyLogin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_DummyLogin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).DummyLogin(ctx, req.(*DummyLoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Users_ServiceDesc is the grpc.ServiceDesc for Users service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Users_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "houses.v1.Users",
+	HandlerType: (*UsersServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Register",
+			Handler:    _Users_Register_Handler,
+		},
+		{
+			MethodName: "Login",
+			Handler:    _Users_Login_Handler,
+		},
+		{
+			MethodName: "DummyLogin",
+			Handler:    _Users_DummyLogin_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "houses/v1/houses.proto",
+}
+
+const (
+	Houses_CreateHouse_FullMethodName = "/houses.v1.Houses/CreateHouse"
+)
+
+// HousesClient is the client API for Houses service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type HousesClient interface {
+	CreateHouse(ctx context.Context, in *CreateHouseRequest, opts ...grpc.CallOption) (*House, error)
+}
+
+type housesClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHousesClient(cc grpc.ClientConnInterface) HousesClient {
+	return &housesClient{cc}
+}
+
+func (c *housesClient) CreateHouse(ctx context.Context, in *CreateHouseRequest, opts ...grpc.CallOption) (*House, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(House)
+	err := c.cc.Invoke(ctx, Houses_CreateHouse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HousesServer is the server API for Houses service.
+// All implementations must embed UnimplementedHousesServer
+// for forward compatibility.
+type HousesServer interface {
+	CreateHouse(context.Context, *CreateHouseRequest) (*House, error)
+	mustEmbedUnimplementedHousesServer()
+}
+
+// UnimplementedHousesServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedHousesServer struct{}
+
+func (UnimplementedHousesServer) CreateHouse(context.Context, *CreateHouseRequest) (*House, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateHouse not implemented")
+}
+func (UnimplementedHousesServer) mustEmbedUnimplementedHousesServer() {}
+func (UnimplementedHousesServer) testEmbeddedByValue()                {}
+
+// UnsafeHousesServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HousesServer will
+// result in compilation errors.
+type UnsafeHousesServer interface {
+	mustEmbedUnimplementedHousesServer()
+}
+
+func RegisterHousesServer(s grpc.ServiceRegistrar, srv HousesServer) {
+	// If the following call pancis, it indicates UnimplementedHousesServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Houses_ServiceDesc, srv)
+}
+
+func _Houses_CreateHouse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateHouseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HousesServer).CreateHouse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Houses_CreateHouse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HousesServer).CreateHouse(ctx, req.(*CreateHouseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Houses_ServiceDesc is the grpc.ServiceDesc for Houses service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Houses_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "houses.v1.Houses",
+	HandlerType: (*HousesServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateHouse",
+			Handler:    _Houses_CreateHouse_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "houses/v1/houses.proto",
+}
+
+const (
+	Flats_ListHouseFlats_FullMethodName = "/houses.v1.Flats/ListHouseFlats"
+	Flats_CreateFlat_FullMethodName     = "/houses.v1.Flats/CreateFlat"
+	Flats_UpdateFlat_FullMethodName     = "/houses.v1.Flats/UpdateFlat"
+)
+
+// FlatsClient is the client API for Flats service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FlatsClient interface {
+	ListHouseFlats(ctx context.Context, in *ListHouseFlatsRequest, opts ...grpc.CallOption) (*ListHouseFlatsResponse, error)
+	CreateFlat(ctx context.Context, in *CreateFlatRequest, opts ...grpc.CallOption) (*Flat, error)
+	UpdateFlat(ctx context.Context, in *UpdateFlatRequest, opts ...grpc.CallOption) (*Flat, error)
+}
+
+type flatsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFlatsClient(cc grpc.ClientConnInterface) FlatsClient {
+	return &flatsClient{cc}
+}
+
+func (c *flatsClient) ListHouseFlats(ctx context.Context, in *ListHouseFlatsRequest, opts ...grpc.CallOption) (*ListHouseFlatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListHouseFlatsResponse)
+	err := c.cc.Invoke(ctx, Flats_ListHouseFlats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flatsClient) CreateFlat(ctx context.Context, in *CreateFlatRequest, opts ...grpc.CallOption) (*Flat, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Flat)
+	err := c.cc.Invoke(ctx, Flats_CreateFlat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flatsClient) UpdateFlat(ctx context.Context, in *UpdateFlatRequest, opts ...grpc.CallOption) (*Flat, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Flat)
+	err := c.cc.Invoke(ctx, Flats_UpdateFlat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FlatsServer is the server API for Flats service.
+// All implementations must embed UnimplementedFlatsServer
+// for forward compatibility.
+type FlatsServer interface {
+	ListHouseFlats(context.Context, *ListHouseFlatsRequest) (*ListHouseFlatsResponse, error)
+	CreateFlat(context.Context, *CreateFlatRequest) (*Flat, error)
+	UpdateFlat(context.Context, *UpdateFlatRequest) (*Flat, error)
+	mustEmbedUnimplementedFlatsServer()
+}
+
+// UnimplementedFlatsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFlatsServer struct{}
+
+func (UnimplementedFlatsServer) ListHouseFlats(context.Context, *ListHouseFlatsRequest) (*ListHouseFlatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListHouseFlats not implemented")
+}
+func (UnimplementedFlatsServer) CreateFlat(context.Context, *CreateFlatRequest) (*Flat, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFlat not implemented")
+}
+func (UnimplementedFlatsServer) UpdateFlat(context.Context, *UpdateFlatRequest) (*Flat, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateFlat not implemented")
+}
+func (UnimplementedFlatsServer) mustEmbedUnimplementedFlatsServer() {}
+func (UnimplementedFlatsServer) testEmbeddedByValue()               {}
+
+// UnsafeFlatsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FlatsServer will
+// result in compilation errors.
+type UnsafeFlatsServer interface {
+	mustEmbedUnimplementedFlatsServer()
+}
+
+func RegisterFlatsServer(s grpc.ServiceRegistrar, srv FlatsServer) {
+	// If the following call pancis, it indicates UnimplementedFlatsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Flats_ServiceDesc, srv)
+}
+
+func _Flats_ListHouseFlats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListHouseFlatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlatsServer).ListHouseFlats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flats_ListHouseFlats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlatsServer).ListHouseFlats(ctx, req.(*ListHouseFlatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flats_CreateFlat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateFlatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlatsServer).CreateFlat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flats_CreateFlat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlatsServer).CreateFlat(ctx, req.(*CreateFlatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Flats_UpdateFlat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateFlatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlatsServer).UpdateFlat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Flats_UpdateFlat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlatsServer).UpdateFlat(ctx, req.(*UpdateFlatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Flats_ServiceDesc is the grpc.ServiceDesc for Flats service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Flats_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "houses.v1.Flats",
+	HandlerType: (*FlatsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListHouseFlats",
+			Handler:    _Flats_ListHouseFlats_Handler,
+		},
+		{
+			MethodName: "CreateFlat",
+			Handler:    _Flats_CreateFlat_Handler,
+		},
+		{
+			MethodName: "UpdateFlat",
+			Handler:    _Flats_UpdateFlat_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "houses/v1/houses.proto",
+}
@@ -0,0 +1,277 @@
+package houseapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Register creates a user and returns its ID.
+func (c *Client) Register(ctx context.Context, req RegisterRequest) (string, error) {
+	var resp userIDResponse
+	if err := c.do(ctx, http.MethodPost, "/register", nil, req, &resp); err != nil {
+		return "", err
+	}
+	return resp.UserID, nil
+}
+
+// Login returns a token to pass to WithToken.
+func (c *Client) Login(ctx context.Context, userID, password string) (string, error) {
+	var resp tokenResponse
+	req := loginRequest{UserID: userID, Password: password}
+	if err := c.do(ctx, http.MethodPost, "/login", nil, req, &resp); err != nil {
+		return "", err
+	}
+	return resp.Token, nil
+}
+
+// DummyLogin returns a token of a new throwaway user of the given type.
+func (c *Client) DummyLogin(ctx context.Context, userType UserType) (string, error) {
+	var resp tokenResponse
+	q := url.Values{"user_type": {string(userType)}}
+	if err := c.do(ctx, http.MethodGet, "/dummyLogin", q, nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.Token, nil
+}
+
+// CreateHouse requires a moderator token.
+func (c *Client) CreateHouse(ctx context.Context, req CreateHouseRequest) (House, error) {
+	var h House
+	err := c.do(ctx, http.MethodPost, "/house/create", nil, req, &h)
+	return h, err
+}
+
+// NearbyHouses lists houses within radius meters of the point, closest
+// first. A limit of zero uses the server default.
+func (c *Client) NearbyHouses(ctx context.Context, lat, lon, radius float64, limit int) ([]NearbyHouse, error) {
+	var houses []NearbyHouse
+	q := url.Values{
+		"lat":    {strconv.FormatFloat(lat, 'f', -1, 64)},
+		"lon":    {strconv.FormatFloat(lon, 'f', -1, 64)},
+		"radius": {strconv.FormatFloat(radius, 'f', -1, 64)},
+	}
+	if limit != 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	err := c.do(ctx, http.MethodGet, "/houses/nearby", q, nil, &houses)
+	return houses, err
+}
+
+// CreateDeveloper requires a moderator token and fails with a conflict if
+// a developer with the same name in another spelling exists.
+func (c *Client) CreateDeveloper(ctx context.Context, name string) (Developer, error) {
+	var d Developer
+	err := c.do(ctx, http.MethodPost, "/developers", nil, developerRequest{Name: name}, &d)
+	return d, err
+}
+
+// Developers finds developers by any spelling of name, an empty name lists
+// all of them.
+func (c *Client) Developers(ctx context.Context, name string) ([]Developer, error) {
+	var ds []Developer
+	var q url.Values
+	if name != "" {
+		q = url.Values{"name": {name}}
+	}
+	err := c.do(ctx, http.MethodGet, "/developers", q, nil, &ds)
+	return ds, err
+}
+
+func (c *Client) Developer(ctx context.Context, id int) (Developer, error) {
+	var d Developer
+	err := c.do(ctx, http.MethodGet, "/developers/"+strconv.Itoa(id), nil, nil, &d)
+	return d, err
+}
+
+// RenameDeveloper requires a moderator token.
+func (c *Client) RenameDeveloper(ctx context.Context, id int, name string) (Developer, error) {
+	var d Developer
+	err := c.do(ctx, http.MethodPut, "/developers/"+strconv.Itoa(id), nil, developerRequest{Name: name}, &d)
+	return d, err
+}
+
+// DeleteDeveloper requires a moderator token and fails with a conflict
+// while the developer has houses.
+func (c *Client) DeleteDeveloper(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, "/developers/"+strconv.Itoa(id), nil, nil, nil)
+}
+
+func (c *Client) DeveloperHouses(ctx context.Context, id int) ([]DeveloperHouse, error) {
+	var hs []DeveloperHouse
+	err := c.do(ctx, http.MethodGet, "/developers/"+strconv.Itoa(id)+"/houses", nil, nil, &hs)
+	return hs, err
+}
+
+// HouseFlats lists flats of a house. Clients only see approved flats.
+func (c *Client) HouseFlats(ctx context.Context, houseID int) ([]Flat, error) {
+	return c.FilterHouseFlats(ctx, houseID, FlatFilter{})
+}
+
+// FilterHouseFlats lists flats of a house matching f, ordered by number.
+func (c *Client) FilterHouseFlats(ctx context.Context, houseID int, f FlatFilter) ([]Flat, error) {
+	var flats []Flat
+	err := c.do(ctx, http.MethodGet, "/house/"+strconv.Itoa(houseID)+"/flats", f.values(), nil, &flats)
+	return flats, err
+}
+
+func (c *Client) CreateFlat(ctx context.Context, req CreateFlatRequest) (Flat, error) {
+	var f Flat
+	err := c.do(ctx, http.MethodPost, "/flat/create", nil, req, &f)
+	return f, err
+}
+
+// UpdateFlat changes the moderation status and requires a moderator token.
+func (c *Client) UpdateFlat(ctx context.Context, req UpdateFlatRequest) (Flat, error) {
+	var f Flat
+	err := c.do(ctx, http.MethodPost, "/flat/update", nil, req, &f)
+	return f, err
+}
+
+// UpdateFlatPrice requires a moderator token or the token of the user who
+// created the flat.
+func (c *Client) UpdateFlatPrice(ctx context.Context, flatID, price int) (Flat, error) {
+	var f Flat
+	err := c.do(ctx, http.MethodPut, "/flat/"+strconv.Itoa(flatID)+"/price", nil, updatePriceRequest{Price: price}, &f)
+	return f, err
+}
+
+// FlatPriceHistory lists the price changes of a flat, oldest first.
+func (c *Client) FlatPriceHistory(ctx context.Context, flatID int) ([]PriceChange, error) {
+	var changes []PriceChange
+	err := c.do(ctx, http.MethodGet, "/flat/"+strconv.Itoa(flatID)+"/price-history", nil, nil, &changes)
+	return changes, err
+}
+
+// AddFavorite bookmarks an approved flat, price changes of favorite flats
+// are sent to the event stream of the user.
+func (c *Client) AddFavorite(ctx context.Context, flatID int) error {
+	return c.do(ctx, http.MethodPost, "/flat/"+strconv.Itoa(flatID)+"/favorite", nil, nil, nil)
+}
+
+func (c *Client) RemoveFavorite(ctx context.Context, flatID int) error {
+	return c.do(ctx, http.MethodDelete, "/flat/"+strconv.Itoa(flatID)+"/favorite", nil, nil, nil)
+}
+
+// Favorites lists the approved favorite flats of the user, latest first.
+func (c *Client) Favorites(ctx context.Context) ([]Favorite, error) {
+	var favs []Favorite
+	err := c.do(ctx, http.MethodGet, "/my/favorites", nil, nil, &favs)
+	return favs, err
+}
+
+// CatalogStats covers all flats, flats of a city or, with district, of a
+// district of the city.
+func (c *Client) CatalogStats(ctx context.Context, city, district string) (Stats, error) {
+	q := url.Values{}
+	if city != "" {
+		q.Set("city", city)
+	}
+	if district != "" {
+		q.Set("district", district)
+	}
+	var st Stats
+	err := c.do(ctx, http.MethodGet, "/stats", q, nil, &st)
+	return st, err
+}
+
+func (c *Client) HouseStats(ctx context.Context, houseID int) (Stats, error) {
+	var st Stats
+	err := c.do(ctx, http.MethodGet, "/house/"+strconv.Itoa(houseID)+"/stats", nil, nil, &st)
+	return st, err
+}
+
+func (c *Client) DeveloperStats(ctx context.Context, developerID int) (Stats, error) {
+	var st Stats
+	err := c.do(ctx, http.MethodGet, "/developers/"+strconv.Itoa(developerID)+"/stats", nil, nil, &st)
+	return st, err
+}
+
+// UploadHousePhoto requires a moderator token. The image must be a JPEG or
+// PNG file, kind defaults to a photo when empty.
+func (c *Client) UploadHousePhoto(ctx context.Context, houseID int, kind PhotoKind, image io.Reader) (Photo, error) {
+	return c.uploadPhoto(ctx, "/house/"+strconv.Itoa(houseID)+"/photos", kind, image)
+}
+
+// UploadFlatPhoto requires a moderator token or the token of the user who
+// created the flat.
+func (c *Client) UploadFlatPhoto(ctx context.Context, flatID int, kind PhotoKind, image io.Reader) (Photo, error) {
+	return c.uploadPhoto(ctx, "/flat/"+strconv.Itoa(flatID)+"/photos", kind, image)
+}
+
+func (c *Client) uploadPhoto(ctx context.Context, path string, kind PhotoKind, image io.Reader) (Photo, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if kind != "" {
+		if err := mw.WriteField("kind", string(kind)); err != nil {
+			return Photo{}, fmt.Errorf("houseapi: encode request: %w", err)
+		}
+	}
+	fw, err := mw.CreateFormFile("file", "image")
+	if err == nil {
+		_, err = io.Copy(fw, image)
+	}
+	if err == nil {
+		err = mw.Close()
+	}
+	if err != nil {
+		return Photo{}, fmt.Errorf("houseapi: encode request: %w", err)
+	}
+	var p Photo
+	err = c.doBody(ctx, http.MethodPost, path, nil, mw.FormDataContentType(), body.Bytes(), &p)
+	return p, err
+}
+
+func (c *Client) HousePhotos(ctx context.Context, houseID int) ([]Photo, error) {
+	var ps []Photo
+	err := c.do(ctx, http.MethodGet, "/house/"+strconv.Itoa(houseID)+"/photos", nil, nil, &ps)
+	return ps, err
+}
+
+// FlatPhotos fails with not found for flats that are not approved yet,
+// unless the token is a moderator's or the owner's.
+func (c *Client) FlatPhotos(ctx context.Context, flatID int) ([]Photo, error) {
+	var ps []Photo
+	err := c.do(ctx, http.MethodGet, "/flat/"+strconv.Itoa(flatID)+"/photos", nil, nil, &ps)
+	return ps, err
+}
+
+func (c *Client) DeletePhoto(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, "/photos/"+strconv.Itoa(id), nil, nil, nil)
+}
+
+// CreateSearch saves a search, the user is alerted about matching flats
+// approved from then on.
+func (c *Client) CreateSearch(ctx context.Context, req SearchRequest) (SavedSearch, error) {
+	var s SavedSearch
+	err := c.do(ctx, http.MethodPost, "/searches", nil, req, &s)
+	return s, err
+}
+
+func (c *Client) UpdateSearch(ctx context.Context, id int, req SearchRequest) (SavedSearch, error) {
+	var s SavedSearch
+	err := c.do(ctx, http.MethodPut, "/searches/"+strconv.Itoa(id), nil, req, &s)
+	return s, err
+}
+
+func (c *Client) DeleteSearch(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, "/searches/"+strconv.Itoa(id), nil, nil, nil)
+}
+
+func (c *Client) Search(ctx context.Context, id int) (SavedSearch, error) {
+	var s SavedSearch
+	err := c.do(ctx, http.MethodGet, "/searches/"+strconv.Itoa(id), nil, nil, &s)
+	return s, err
+}
+
+// Searches lists the saved searches of the user.
+func (c *Client) Searches(ctx context.Context) ([]SavedSearch, error) {
+	var ss []SavedSearch
+	err := c.do(ctx, http.MethodGet, "/searches", nil, nil, &ss)
+	return ss, err
+}
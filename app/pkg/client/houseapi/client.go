@@ -0,0 +1,199 @@
+// Package houseapi is a Go client for the houses API.
+package houseapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPrefix       = "/api/v1"
+	defaultMaxRetries   = 3
+	defaultMaxRetryWait = 10 * time.Second
+	// baseRetryWait is the first backoff when the server sends no
+	// Retry-After, doubled with every attempt.
+	baseRetryWait  = 200 * time.Millisecond
+	defaultTimeout = 30 * time.Second
+)
+
+type Client struct {
+	baseURL      *url.URL
+	httpClient   *http.Client
+	token        string
+	maxRetries   int
+	maxRetryWait time.Duration
+}
+
+type Option func(c *Client)
+
+// WithHTTPClient replaces the default client with a 30s timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithRetries sets how many times an idempotent request failed with 5xx is
+// repeated and the longest the client is willing to wait between attempts.
+func WithRetries(maxRetries int, maxWait time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.maxRetryWait = maxWait
+	}
+}
+
+// WithToken returns a copy of the client that sends token in the Authorization
+// header, so one client can be shared by several users.
+func (c *Client) WithToken(token string) *Client {
+	cp := *c
+	cp.token = token
+	return &cp
+}
+
+// do sends the request and decodes a 2xx JSON response into out. GET, PUT
+// and DELETE requests failed with 5xx are retried; POSTs are not, since a
+// response lost after the server committed would create duplicates.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("houseapi: encode request: %w", err)
+		}
+	}
+	return c.doBody(ctx, method, path, query, "application/json", body, out)
+}
+
+// doBody is do with a body already encoded as contentType.
+func (c *Client) doBody(ctx context.Context, method, path string, query url.Values, contentType string, body []byte, out any) error {
+	u := c.baseURL.JoinPath(defaultPrefix, path)
+	u.RawQuery = query.Encode()
+	for attempt := 0; ; attempt++ {
+		err := c.send(ctx, method, u.String(), contentType, body, out)
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode < http.StatusInternalServerError ||
+			!idempotent(method) || attempt >= c.maxRetries {
+			return err
+		}
+		timer := time.NewTimer(c.retryWait(attempt, apiErr.RetryAfter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryWait honours Retry-After and otherwise backs off exponentially with
+// full jitter, so that clients failed together do not retry together.
+func (c *Client) retryWait(attempt int, retryAfter time.Duration) time.Duration {
+	wait := retryAfter
+	if wait <= 0 {
+		backoff := c.maxRetryWait
+		if attempt < 30 && baseRetryWait<<attempt < backoff {
+			backoff = baseRetryWait << attempt
+		}
+		if backoff > 0 {
+			wait = rand.N(backoff) + 1
+		}
+	}
+	return min(wait, c.maxRetryWait)
+}
+
+func (c *Client) send(ctx context.Context, method, u, contentType string, body []byte, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return fmt.Errorf("houseapi: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", c.token)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("houseapi: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return decodeError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("houseapi: decode response: %w", err)
+	}
+	return nil
+}
+
+func decodeError(resp *http.Response) error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+		apiErr.RetryAfter = time.Duration(s) * time.Second
+	}
+	var errBody struct {
+		Message string `json:"message"`
+		ReqID   string `json:"req_id"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if json.Unmarshal(data, &errBody) == nil && errBody.Message != "" {
+		apiErr.Message = errBody.Message
+		apiErr.RequestID = errBody.ReqID
+	} else {
+		apiErr.Message = strings.TrimSpace(string(data))
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+	}
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = resp.Header.Get("X-Request-ID")
+	}
+	return apiErr
+}
+
+// New creates a client for the API served at baseURL, e.g. http://localhost:8080.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("houseapi: parse base URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("houseapi: base URL %q must be absolute", baseURL)
+	}
+	c := &Client{
+		baseURL:      u,
+		httpClient:   &http.Client{Timeout: defaultTimeout},
+		maxRetries:   defaultMaxRetries,
+		maxRetryWait: defaultMaxRetryWait,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
@@ -0,0 +1,56 @@
+package houseapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_RetriesIdempotentOnly(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	c, err := New(srv.URL, WithRetries(2, 10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		call  func() error
+		calls int32
+	}{
+		{name: "GET", call: func() error { _, err := c.Developer(context.Background(), 1); return err }, calls: 3},
+		{name: "DELETE", call: func() error { return c.DeletePhoto(context.Background(), 1) }, calls: 3},
+		{name: "POST", call: func() error { _, err := c.CreateFlat(context.Background(), CreateFlatRequest{}); return err }, calls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			if err := tt.call(); err == nil {
+				t.Fatal("error = nil, want 503")
+			}
+			if got := calls.Load(); got != tt.calls {
+				t.Errorf("calls = %d, want %d", got, tt.calls)
+			}
+		})
+	}
+}
+
+func TestClient_RetryWait(t *testing.T) {
+	c := &Client{maxRetryWait: time.Second}
+	if got := c.retryWait(0, 3*time.Second); got != time.Second {
+		t.Errorf("Retry-After above the limit waits %s, want 1s", got)
+	}
+	for attempt := 0; attempt < 40; attempt++ {
+		limit := min(baseRetryWait<<min(attempt, 30), time.Second)
+		if got := c.retryWait(attempt, 0); got <= 0 || got > limit {
+			t.Errorf("attempt %d waits %s, want (0, %s]", attempt, got, limit)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package houseapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Error is a non-2xx response decoded from the API error body.
+type Error struct {
+	StatusCode int
+	Message    string
+	RequestID  string
+	// RetryAfter is the delay suggested by the server, zero if none was sent.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.RequestID == "" {
+		return fmt.Sprintf("houseapi: %d %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("houseapi: %d %s (req_id=%s)", e.StatusCode, e.Message, e.RequestID)
+}
+
+// Temporary reports whether repeating the request may succeed.
+func (e *Error) Temporary() bool {
+	return e.StatusCode >= http.StatusInternalServerError || e.StatusCode == http.StatusTooManyRequests
+}
+
+// StatusCode returns the HTTP status of an API error, or 0 for other errors.
+func StatusCode(err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+func IsUnauthorized(err error) bool {
+	return StatusCode(err) == http.StatusUnauthorized
+}
+
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+func IsBadRequest(err error) bool {
+	return StatusCode(err) == http.StatusBadRequest
+}
+
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
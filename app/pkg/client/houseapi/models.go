@@ -0,0 +1,299 @@
+package houseapi
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type UserType string
+
+const (
+	UserTypeClient    UserType = "client"
+	UserTypeModerator UserType = "moderator"
+)
+
+type Status string
+
+const (
+	StatusCreated      Status = "created"
+	StatusApproved     Status = "approved"
+	StatusDeclined     Status = "declined"
+	StatusOnModeration Status = "on moderation"
+)
+
+type Layout string
+
+const (
+	LayoutStudio   Layout = "studio"
+	LayoutIsolated Layout = "isolated"
+	LayoutAdjacent Layout = "adjacent"
+	LayoutOpenPlan Layout = "open_plan"
+)
+
+type RegisterRequest struct {
+	Email    string   `json:"email"`
+	Password string   `json:"password"`
+	UserType UserType `json:"user_type"`
+}
+
+type Material string
+
+const (
+	MaterialBrick         Material = "brick"
+	MaterialPanel         Material = "panel"
+	MaterialMonolith      Material = "monolith"
+	MaterialMonolithBrick Material = "monolith_brick"
+	MaterialBlock         Material = "block"
+	MaterialWood          Material = "wood"
+)
+
+// CreateHouseRequest leaves the optional attributes out when they are nil,
+// the coordinates are given both or not at all. The developer is given by
+// DeveloperID or, not both, by Developer, a name the server resolves.
+type CreateHouseRequest struct {
+	Address     string   `json:"address"`
+	Year        int      `json:"year"`
+	DeveloperID int      `json:"developer_id,omitempty"`
+	Developer   string   `json:"developer,omitempty"`
+	Floors      *int     `json:"floors,omitempty"`
+	Material    Material `json:"material,omitempty"`
+	Latitude    *float64 `json:"latitude,omitempty"`
+	Longitude   *float64 `json:"longitude,omitempty"`
+	City        string   `json:"city,omitempty"`
+	District    string   `json:"district,omitempty"`
+	Parking     bool     `json:"parking,omitempty"`
+	Elevator    bool     `json:"elevator,omitempty"`
+}
+
+type House struct {
+	ID          int       `json:"id"`
+	Address     string    `json:"address"`
+	Year        int       `json:"year"`
+	DeveloperID int       `json:"developer_id,omitempty"`
+	Developer   string    `json:"developer,omitempty"`
+	Floors      *int      `json:"floors,omitempty"`
+	Material    Material  `json:"material,omitempty"`
+	Latitude    *float64  `json:"latitude,omitempty"`
+	Longitude   *float64  `json:"longitude,omitempty"`
+	City        string    `json:"city,omitempty"`
+	District    string    `json:"district,omitempty"`
+	Parking     bool      `json:"parking"`
+	Elevator    bool      `json:"elevator"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdateAt    time.Time `json:"update_at"`
+}
+
+type Developer struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdateAt  time.Time `json:"update_at"`
+}
+
+// DeveloperHouse is a house with the number of its flats, clients only get
+// approved flats counted.
+type DeveloperHouse struct {
+	House
+	Flats int `json:"flats"`
+}
+
+type PhotoKind string
+
+const (
+	PhotoKindPhoto     PhotoKind = "photo"
+	PhotoKindFloorPlan PhotoKind = "floor_plan"
+)
+
+// Photo is an image of a house or a flat. URL and ThumbnailURL are paths
+// on the API server.
+type Photo struct {
+	ID           int       `json:"id"`
+	HouseID      int       `json:"house_id,omitempty"`
+	FlatID       int       `json:"flat_id,omitempty"`
+	Kind         PhotoKind `json:"kind"`
+	ContentType  string    `json:"content_type"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	Size         int64     `json:"size"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type developerRequest struct {
+	Name string `json:"name"`
+}
+
+// NearbyHouse is a house with its distance from the searched point in
+// meters.
+type NearbyHouse struct {
+	House
+	Distance float64 `json:"distance"`
+}
+
+// CreateFlatRequest leaves the optional attributes out when they are nil.
+// Without a number the server takes the next free one in the house.
+type CreateFlatRequest struct {
+	HouseID     int      `json:"house_id"`
+	Number      int      `json:"number,omitempty"`
+	Price       int      `json:"price"`
+	Rooms       int      `json:"rooms"`
+	Floor       *int     `json:"floor,omitempty"`
+	TotalArea   *float64 `json:"total_area,omitempty"`
+	LivingArea  *float64 `json:"living_area,omitempty"`
+	KitchenArea *float64 `json:"kitchen_area,omitempty"`
+	Balcony     bool     `json:"balcony,omitempty"`
+	Layout      Layout   `json:"layout,omitempty"`
+}
+
+// UpdateFlatRequest identifies the flat by ID or, with ID zero, by its
+// number in the house.
+type UpdateFlatRequest struct {
+	ID      int    `json:"id,omitempty"`
+	HouseID int    `json:"house_id"`
+	Number  int    `json:"number,omitempty"`
+	Status  Status `json:"status"`
+}
+
+type Flat struct {
+	ID          int      `json:"id"`
+	HouseID     int      `json:"house_id"`
+	Number      int      `json:"number"`
+	Price       int      `json:"price"`
+	Rooms       int      `json:"rooms"`
+	Floor       *int     `json:"floor,omitempty"`
+	TotalArea   *float64 `json:"total_area,omitempty"`
+	LivingArea  *float64 `json:"living_area,omitempty"`
+	KitchenArea *float64 `json:"kitchen_area,omitempty"`
+	Balcony     bool     `json:"balcony"`
+	Layout      Layout   `json:"layout,omitempty"`
+	// PrevPrice is the price before the last change, PriceDropped and
+	// PriceDropPercent tell whether and how much that change lowered it.
+	PrevPrice        *int    `json:"prev_price,omitempty"`
+	PriceDropped     bool    `json:"price_dropped"`
+	PriceDropPercent float64 `json:"price_drop_percent,omitempty"`
+	Status           Status  `json:"status"`
+}
+
+// PriceChange is an entry of the price history of a flat.
+type PriceChange struct {
+	OldPrice  int       `json:"old_price"`
+	Price     int       `json:"price"`
+	ChangedBy string    `json:"changed_by,omitempty"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// Favorite is a favorite flat as it is now, with its house.
+type Favorite struct {
+	Flat
+	House   House     `json:"house"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// Summary aggregates a value over flats, fields are nil without flats.
+type Summary struct {
+	Min    *float64 `json:"min,omitempty"`
+	Max    *float64 `json:"max,omitempty"`
+	Avg    *float64 `json:"avg,omitempty"`
+	Median *float64 `json:"median,omitempty"`
+}
+
+// Stats describes approved flats. Rooms maps a room count to the number of
+// flats, Statuses is only filled in for moderators.
+type Stats struct {
+	Flats        int            `json:"flats"`
+	Price        Summary        `json:"price"`
+	PricePerRoom Summary        `json:"price_per_room"`
+	PricePerM2   Summary        `json:"price_per_m2"`
+	Rooms        map[int]int    `json:"rooms"`
+	Statuses     map[Status]int `json:"statuses,omitempty"`
+}
+
+type updatePriceRequest struct {
+	Price int `json:"price"`
+}
+
+// FlatFilter narrows HouseFlats, zero values match everything.
+type FlatFilter struct {
+	Rooms    int
+	PriceMin int
+	PriceMax int
+	FloorMin *int
+	FloorMax *int
+	AreaMin  float64
+	AreaMax  float64
+	Balcony  *bool
+	Layout   Layout
+}
+
+func (f FlatFilter) values() url.Values {
+	q := url.Values{}
+	setInt := func(name string, v int) {
+		if v != 0 {
+			q.Set(name, strconv.Itoa(v))
+		}
+	}
+	setInt("rooms", f.Rooms)
+	setInt("price_min", f.PriceMin)
+	setInt("price_max", f.PriceMax)
+	if f.FloorMin != nil {
+		q.Set("floor_min", strconv.Itoa(*f.FloorMin))
+	}
+	if f.FloorMax != nil {
+		q.Set("floor_max", strconv.Itoa(*f.FloorMax))
+	}
+	if f.AreaMin != 0 {
+		q.Set("area_min", strconv.FormatFloat(f.AreaMin, 'f', -1, 64))
+	}
+	if f.AreaMax != 0 {
+		q.Set("area_max", strconv.FormatFloat(f.AreaMax, 'f', -1, 64))
+	}
+	if f.Balcony != nil {
+		q.Set("balcony", strconv.FormatBool(*f.Balcony))
+	}
+	if f.Layout != "" {
+		q.Set("layout", string(f.Layout))
+	}
+	return q
+}
+
+type userIDResponse struct {
+	UserID string `json:"user_id"`
+}
+
+type tokenResponse struct {
+	Token string `json:"token"`
+}
+
+type loginRequest struct {
+	UserID   string `json:"user_id"`
+	Password string `json:"password"`
+}
+
+// SearchRequest creates or replaces a saved search. Zero criteria match any
+// flat; a search is limited either to a house or to a city and optionally
+// one of its districts.
+type SearchRequest struct {
+	Name     string `json:"name"`
+	HouseID  int    `json:"house_id,omitempty"`
+	City     string `json:"city,omitempty"`
+	District string `json:"district,omitempty"`
+	Rooms    int    `json:"rooms,omitempty"`
+	PriceMin int    `json:"price_min,omitempty"`
+	PriceMax int    `json:"price_max,omitempty"`
+}
+
+type SavedSearch struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	HouseID   int       `json:"house_id,omitempty"`
+	City      string    `json:"city,omitempty"`
+	District  string    `json:"district,omitempty"`
+	Rooms     int       `json:"rooms,omitempty"`
+	PriceMin  int       `json:"price_min,omitempty"`
+	PriceMax  int       `json:"price_max,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdateAt  time.Time `json:"update_at"`
+	CheckedAt time.Time `json:"checked_at"`
+}
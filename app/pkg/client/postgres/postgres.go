@@ -2,8 +2,9 @@ package postgres
 
 import (
 	"context"
-	"fmt"
 	"log"
+	"net"
+	"net/url"
 	"time"
 
 	"github.com/Polyrom/houses_api/internal/config"
@@ -19,15 +20,31 @@ type Client interface {
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
+// TxClient is a Client that can also start transactions, e.g. *pgxpool.Pool.
+type TxClient interface {
+	Client
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
 func NewClient(ctx context.Context, sc config.StorageConfig) (*pgxpool.Pool, error) {
 	var pool *pgxpool.Pool
 	var err error
 
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", sc.Username, sc.Password, sc.Host, sc.Port, sc.Database)
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(sc.Username, sc.Password),
+		Host:     net.JoinHostPort(sc.Host, sc.Port),
+		Path:     sc.Database,
+		RawQuery: url.Values{"sslmode": {"disable"}, "application_name": {applicationName}}.Encode(),
+	}
+	poolCfg, err := pgxpool.ParseConfig(dsn.String())
+	if err != nil {
+		return nil, err
+	}
 	err = utils.Repeat(func() error {
 		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 		defer cancel()
-		pool, err = pgxpool.New(ctx, dsn)
+		pool, err = pgxpool.NewWithConfig(ctx, poolCfg)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// applicationName is reported in pg_stat_activity and server logs.
+// Transactions tagged with a request ID report "houses_api req_id=<id>".
+const applicationName = "houses_api"
+
+// maxApplicationNameLen is NAMEDATALEN-1; longer names are truncated by
+// PostgreSQL anyway.
+const maxApplicationNameLen = 63
+
+type requestIDKey struct{}
+
+// WithRequestID makes transactions tagged with Tag under ctx carry the ID.
+func WithRequestID(ctx context.Context, reqID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, reqID)
+}
+
+// Tag sets application_name to the request ID of ctx until tx ends, so
+// slow-query logs of long transactions can be correlated with request logs.
+// It costs a round trip, so only transactions worth tracing call it; the
+// session name is restored on commit or rollback. Without an ID it does
+// nothing.
+func Tag(ctx context.Context, tx pgx.Tx) error {
+	reqID, _ := ctx.Value(requestIDKey{}).(string)
+	if reqID == "" {
+		return nil
+	}
+	name := applicationName + " req_id=" + reqID
+	if len(name) > maxApplicationNameLen {
+		name = name[:maxApplicationNameLen]
+	}
+	_, err := tx.Exec(ctx, `SELECT set_config('application_name', $1, true)`, name)
+	return err
+}
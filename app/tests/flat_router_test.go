@@ -26,7 +26,7 @@ func executeRequest(r *mux.Router, req *http.Request) *httptest.ResponseRecorder
 
 func TestCreateFlat(t *testing.T) {
 	ctx := &testContext{
-		Server:         newTestServer(),
+		Server:         newTestServer(t),
 		ModeratorToken: middleware.Token(""),
 		ClientToken:    middleware.Token(""),
 		Houses:         map[int]house.House{},
@@ -46,6 +46,7 @@ func TestCreateFlat(t *testing.T) {
 	expectedRespOne := flat.FlatDTO{
 		ID:      1,
 		HouseID: hs.ID,
+		Number:  1,
 		Price:   priceOne,
 		Rooms:   roomsOne,
 		Status:  modstatus.Created.String(),
@@ -59,6 +60,7 @@ func TestCreateFlat(t *testing.T) {
 	expectedRespTwo := flat.FlatDTO{
 		ID:      2,
 		HouseID: hs.ID,
+		Number:  2,
 		Price:   priceTwo,
 		Rooms:   roomsTwo,
 		Status:  modstatus.Created.String(),
@@ -114,6 +116,7 @@ func TestCreateFlat(t *testing.T) {
 			if err != nil {
 				t.Errorf("failed to create Create Flat request: %v", err)
 			}
+			req.Header.Set("Content-Type", "application/json")
 			req.Header.Set("Authorization", string(tt.args.authToken))
 			resp := executeRequest(ctx.Server.Router, req)
 			if resp.Code != tt.want.code {
@@ -137,7 +140,7 @@ func TestCreateFlat(t *testing.T) {
 
 func TestGetHouse(t *testing.T) {
 	ctx := &testContext{
-		Server:         newTestServer(),
+		Server:         newTestServer(t),
 		ModeratorToken: middleware.Token(""),
 		ClientToken:    middleware.Token(""),
 		Houses:         map[int]house.House{},
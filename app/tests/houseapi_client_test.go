@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Polyrom/houses_api/internal/apierror"
+	"github.com/Polyrom/houses_api/internal/house"
+	"github.com/Polyrom/houses_api/internal/middleware"
+	"github.com/Polyrom/houses_api/pkg/client/houseapi"
+)
+
+func newTestClient(t *testing.T, h http.Handler) *houseapi.Client {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	c, err := houseapi.New(srv.URL, houseapi.WithRetries(2, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return c
+}
+
+func TestHouseAPIClient(t *testing.T) {
+	ctx := &testContext{
+		Server:         newTestServer(t),
+		ModeratorToken: middleware.Token(""),
+		ClientToken:    middleware.Token(""),
+		Houses:         map[int]house.House{},
+	}
+	ctx.setup()
+	t.Cleanup(func() {
+		ctx.cleanup()
+	})
+	c := newTestClient(t, ctx.Server.Router)
+	bg := context.Background()
+	moder := c.WithToken(string(ctx.ModeratorToken))
+	client := c.WithToken(string(ctx.ClientToken))
+
+	h, err := moder.CreateHouse(bg, houseapi.CreateHouseRequest{Address: "Lenina 1", Year: 2001})
+	if err != nil {
+		t.Fatalf("create house: %v", err)
+	}
+	if h.ID == 0 || h.Address != "Lenina 1" || h.Year != 2001 {
+		t.Errorf("create house = %+v", h)
+	}
+	_, err = client.CreateHouse(bg, houseapi.CreateHouseRequest{Address: "Lenina 2", Year: 2002})
+	if !houseapi.IsUnauthorized(err) {
+		t.Errorf("create house as client: err = %v, want 401", err)
+	}
+
+	f, err := client.CreateFlat(bg, houseapi.CreateFlatRequest{HouseID: h.ID, Price: 5_000_000, Rooms: 2})
+	if err != nil {
+		t.Fatalf("create flat: %v", err)
+	}
+	wantFlat := houseapi.Flat{ID: f.ID, HouseID: h.ID, Number: 1, Price: 5_000_000, Rooms: 2, Status: houseapi.StatusCreated}
+	if f != wantFlat {
+		t.Errorf("create flat = %+v, want %+v", f, wantFlat)
+	}
+	_, err = client.CreateFlat(bg, houseapi.CreateFlatRequest{HouseID: h.ID, Price: -1, Rooms: 2})
+	var apiErr *houseapi.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message == "" || apiErr.RequestID == "" {
+		t.Errorf("create invalid flat: err = %#v, want decoded 400", err)
+	}
+	_, err = c.CreateFlat(bg, houseapi.CreateFlatRequest{HouseID: h.ID, Price: 1, Rooms: 1})
+	if !houseapi.IsUnauthorized(err) {
+		t.Errorf("create flat without token: err = %v, want 401", err)
+	}
+
+	for _, status := range []houseapi.Status{houseapi.StatusOnModeration, houseapi.StatusApproved} {
+		f, err = moder.UpdateFlat(bg, houseapi.UpdateFlatRequest{ID: f.ID, HouseID: h.ID, Status: status})
+		if err != nil {
+			t.Fatalf("update flat to %s: %v", status, err)
+		}
+		if f.Status != status {
+			t.Errorf("update flat status = %s, want %s", f.Status, status)
+		}
+	}
+
+	flats, err := client.HouseFlats(bg, h.ID)
+	if err != nil {
+		t.Fatalf("house flats: %v", err)
+	}
+	wantFlat.Status = houseapi.StatusApproved
+	if !reflect.DeepEqual(flats, []houseapi.Flat{wantFlat}) {
+		t.Errorf("house flats = %+v, want %+v", flats, []houseapi.Flat{wantFlat})
+	}
+}
+
+func TestHouseAPIClient_Auth(t *testing.T) {
+	ctx := &testContext{
+		Server:         newTestServer(t),
+		ModeratorToken: middleware.Token(""),
+		ClientToken:    middleware.Token(""),
+		Houses:         map[int]house.House{},
+	}
+	t.Cleanup(func() {
+		ctx.cleanup()
+	})
+	c := newTestClient(t, ctx.Server.Router)
+	bg := context.Background()
+
+	uid, err := c.Register(bg, houseapi.RegisterRequest{Email: "sdk@haha.foo", Password: "secret", UserType: houseapi.UserTypeModerator})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	token, err := c.Login(bg, uid, "secret")
+	if err != nil || token == "" {
+		t.Fatalf("login: token %q, err %v", token, err)
+	}
+	if _, err = c.WithToken(token).CreateHouse(bg, houseapi.CreateHouseRequest{Address: "Mira 3", Year: 1990}); err != nil {
+		t.Errorf("create house with login token: %v", err)
+	}
+	if _, err = c.Login(bg, uid, "wrong"); err == nil {
+		t.Errorf("login with wrong password: want error")
+	}
+	token, err = c.DummyLogin(bg, houseapi.UserTypeClient)
+	if err != nil || token == "" {
+		t.Fatalf("dummy login: token %q, err %v", token, err)
+	}
+	if _, err = c.DummyLogin(bg, houseapi.UserType("admin")); !houseapi.IsNotFound(err) {
+		t.Errorf("dummy login unknown type: err = %v, want 404", err)
+	}
+}
+
+func TestHouseAPIClient_RetriesServerErrors(t *testing.T) {
+	ctx := &testContext{
+		Server:         newTestServer(t),
+		ModeratorToken: middleware.Token(""),
+		ClientToken:    middleware.Token(""),
+		Houses:         map[int]house.House{},
+	}
+	t.Cleanup(func() {
+		ctx.cleanup()
+	})
+	var calls, failures atomic.Int32
+	failures.Store(2)
+	flaky := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failures.Add(-1) >= 0 {
+			w.Header().Set("Retry-After", "1")
+			apierror.Write(w, errors.New("temporarily unavailable"), "flaky", http.StatusServiceUnavailable)
+			return
+		}
+		ctx.Server.Router.ServeHTTP(w, r)
+	})
+	c := newTestClient(t, flaky)
+	token, err := c.DummyLogin(context.Background(), houseapi.UserTypeModerator)
+	if err != nil || token == "" {
+		t.Fatalf("dummy login: token %q, err %v", token, err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("calls = %d, want 3", got)
+	}
+
+	failures.Store(5)
+	calls.Store(0)
+	_, err = c.DummyLogin(context.Background(), houseapi.UserTypeModerator)
+	var apiErr *houseapi.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.RetryAfter != time.Second || apiErr.RequestID != "flaky" {
+		t.Errorf("err = %#v, want decoded 503", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("calls = %d, want 3", got)
+	}
+}
@@ -3,6 +3,7 @@ package tests
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"os"
 	"testing"
@@ -17,6 +18,7 @@ import (
 	"github.com/Polyrom/houses_api/pkg/client/postgres"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -69,6 +71,10 @@ func (ctx *testContext) cleanup() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	err = deleteDevelopers(ctx.Server.DB)
+	if err != nil {
+		log.Fatal(err)
+	}
 	err = deleteTokens(ctx.Server.DB)
 	if err != nil {
 		log.Fatal(err)
@@ -107,6 +113,15 @@ func deleteHouses(db *pgxpool.Pool) error {
 	return nil
 }
 
+func deleteDevelopers(db *pgxpool.Pool) error {
+	dq := `DELETE FROM developers;`
+	_, err := db.Exec(context.Background(), dq)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func deleteUsers(db *pgxpool.Pool) error {
 	dq := `DELETE FROM users;`
 	_, err := db.Exec(context.Background(), dq)
@@ -133,14 +148,14 @@ func (ml *MockLogger) Info(args ...interface{})                    {}
 func (ml *MockLogger) Warn(args ...interface{})                    {}
 func (ml *MockLogger) Warning(args ...interface{})                 {}
 func (ml *MockLogger) Error(args ...interface{})                   {}
-func (ml *MockLogger) Fatal(args ...interface{})                   {}
+func (ml *MockLogger) Fatal(args ...interface{})                   { panic(fmt.Sprint(args...)) }
 func (ml *MockLogger) Tracef(format string, args ...interface{})   {}
 func (ml *MockLogger) Debugf(format string, args ...interface{})   {}
 func (ml *MockLogger) Infof(format string, args ...interface{})    {}
 func (ml *MockLogger) Warnf(format string, args ...interface{})    {}
 func (ml *MockLogger) Warningf(format string, args ...interface{}) {}
 func (ml *MockLogger) Errorf(format string, args ...interface{})   {}
-func (ml *MockLogger) Fatalf(format string, args ...interface{})   {}
+func (ml *MockLogger) Fatalf(format string, args ...interface{})   { panic(fmt.Sprintf(format, args...)) }
 func (ml *MockLogger) Panicf(format string, args ...interface{})   {}
 
 var testStorageCfg = config.StorageConfig{
@@ -151,25 +166,35 @@ var testStorageCfg = config.StorageConfig{
 	Database:    "testdb",
 	MaxAttempts: 5,
 }
-var testCfg = config.Config{
-	Debug: new(bool),
-	Listen: struct {
-		Host string `yaml:"host"`
-		Port string `yaml:"port"`
-	}{
-		Host: "localhost",
-		Port: "8080",
-	},
-	Storage: testStorageCfg,
+
+// newTestConfig returns the default config pointed at the test database,
+// with photos kept in a temporary directory.
+func newTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg := &config.Config{}
+	if err := cleanenv.ReadEnv(cfg); err != nil {
+		t.Fatalf("read default config: %v", err)
+	}
+	cfg.Listen.Host = "localhost"
+	cfg.Storage = testStorageCfg
+	cfg.Photos.Dir = t.TempDir()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("invalid test config: %v", err)
+	}
+	return cfg
 }
 
-func newTestServer() *server.Server {
-	pg, err := postgres.NewClient(context.Background(), testStorageCfg)
+// newTestServer connects to the test database and builds the router.
+// Startup failures panic through the logger and fail the test.
+func newTestServer(t *testing.T) *server.Server {
+	t.Helper()
+	cfg := newTestConfig(t)
+	pg, err := postgres.NewClient(context.Background(), cfg.Storage)
 	if err != nil {
-		os.Exit(1)
+		t.Fatalf("connect to test database: %v", err)
 	}
 	router := mux.NewRouter()
-	server := server.New(&testCfg, &MockLogger{}, router, pg)
+	server := server.New(cfg, &MockLogger{}, router, pg)
 	server.ConfigureRouter()
 	return server
 }